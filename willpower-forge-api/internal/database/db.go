@@ -1,14 +1,21 @@
 package database
 
 import (
+	"fmt"
 	"log"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"willpower-forge-api/internal/markdown"
 	"willpower-forge-api/internal/models"
 )
 
+// markdownEscapedVersion is the SQLite user_version from which stored
+// markdown has been sanitized by escaping raw HTML. Databases below it are
+// sanitized again on start, since the former sanitizer could be bypassed.
+const markdownEscapedVersion = 1
+
 var dbInstance *gorm.DB
 
 // Connect initializes and caches the SQLite connection used across the app.
@@ -32,7 +39,7 @@ func Connect() (*gorm.DB, error) {
 
 // AutoMigrateModels ensures the schema matches the expected models.
 func AutoMigrateModels(db *gorm.DB) {
-	if err := db.AutoMigrate(&models.User{}, &models.Goal{}, &models.CheckIn{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.Tag{}, &models.Goal{}, &models.GoalPause{}, &models.GoalOutcome{}, &models.ImplementationIntention{}, &models.GoalTemplate{}, &models.CheckIn{}, &models.VacationPeriod{}, &models.TemptationEvent{}, &models.Relapse{}, &models.JournalEntry{}, &models.JournalAnswer{}, &models.Routine{}, &models.RoutineStep{}, &models.Milestone{}, &models.ChecklistItem{}, &models.GoalStrength{}, &models.GoalAchievementStats{}, &models.Report{}, &models.Achievement{}, &models.PointsEntry{}, &models.StreakFreeze{}, &models.Reminder{}, &models.ReminderDelivery{}, &models.PushSubscription{}, &models.VapidKey{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.InboundHook{}, &models.InboundHookCall{}, &models.JobRun{}, &models.DigestSubscription{}, &models.DigestDelivery{}, &models.Notification{}); err != nil {
 		log.Fatalf("failed to migrate database: %v", err)
 	}
 
@@ -41,4 +48,60 @@ func AutoMigrateModels(db *gorm.DB) {
 			log.Printf("failed to drop legacy check-in index: %v", err)
 		}
 	}
+
+	if err := resanitizeMarkdown(db); err != nil {
+		log.Fatalf("failed to sanitize stored markdown: %v", err)
+	}
+}
+
+// resanitizeMarkdown runs every stored markdown field through the current
+// sanitizer once, then records markdownEscapedVersion.
+func resanitizeMarkdown(db *gorm.DB) error {
+	var version int
+	if err := db.Raw("PRAGMA user_version").Row().Scan(&version); err != nil {
+		return err
+	}
+	if version >= markdownEscapedVersion {
+		return nil
+	}
+
+	tables := []struct {
+		name   string
+		fields []string
+	}{
+		{"goals", []string{"description", "motivation"}},
+		{"implementation_intentions", []string{"cue", "action"}},
+		{"journal_entries", []string{"body"}},
+		{"journal_answers", []string{"answer"}},
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range tables {
+			var rows []map[string]interface{}
+			if err := tx.Table(table.name).Select(append([]string{"id"}, table.fields...)).Find(&rows).Error; err != nil {
+				return err
+			}
+			for _, row := range rows {
+				updates := make(map[string]interface{})
+				for _, field := range table.fields {
+					var value string
+					switch v := row[field].(type) {
+					case string:
+						value = v
+					case []byte:
+						value = string(v)
+					}
+					if sanitized := markdown.Sanitize(value); sanitized != value {
+						updates[field] = sanitized
+					}
+				}
+				if len(updates) == 0 {
+					continue
+				}
+				if err := tx.Table(table.name).Where("id = ?", row["id"]).Updates(updates).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", markdownEscapedVersion)).Error
+	})
 }
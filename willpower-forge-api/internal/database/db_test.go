@@ -0,0 +1,58 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"willpower-forge-api/internal/models"
+)
+
+func TestResanitizeMarkdown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	AutoMigrateModels(db)
+
+	// Rows stored by the former sanitizer, before user_version was set.
+	user := models.User{Username: "alice", PasswordHash: "x"}
+	db.Create(&user)
+	goal := models.Goal{UserID: user.ID, Type: "I_WILL", Title: "Read", Description: "<svg/onload=alert(1)>", Motivation: "fine"}
+	db.Create(&goal)
+	entry := models.JournalEntry{UserID: user.ID, Date: "2026-01-01", Body: "<scr<b>ipt>alert(1)</scr<b>ipt>"}
+	db.Create(&entry)
+	db.Create(&models.JournalAnswer{JournalEntryID: entry.ID, PromptKey: "highlight", Answer: "[x](javascript:alert(1))"})
+	if err := db.Exec("PRAGMA user_version = 0").Error; err != nil {
+		t.Fatalf("reset user_version: %v", err)
+	}
+
+	if err := resanitizeMarkdown(db); err != nil {
+		t.Fatalf("resanitize: %v", err)
+	}
+
+	db.First(&goal, goal.ID)
+	db.First(&entry, entry.ID)
+	var answer models.JournalAnswer
+	db.First(&answer, "journal_entry_id = ?", entry.ID)
+	if goal.Description != "&lt;svg/onload=alert(1)&gt;" || goal.Motivation != "fine" {
+		t.Errorf("goal = %q, %q", goal.Description, goal.Motivation)
+	}
+	if entry.Body != "&lt;scr&lt;b&gt;ipt&gt;alert(1)&lt;/scr&lt;b&gt;ipt&gt;" {
+		t.Errorf("journal body = %q", entry.Body)
+	}
+	if answer.Answer != "[x](#)" {
+		t.Errorf("journal answer = %q", answer.Answer)
+	}
+
+	var version int
+	db.Raw("PRAGMA user_version").Row().Scan(&version)
+	if version != markdownEscapedVersion {
+		t.Errorf("user_version = %d, want %d", version, markdownEscapedVersion)
+	}
+}
@@ -0,0 +1,22 @@
+package testdb
+
+import "testing"
+
+func TestOpenEnforcesForeignKeysOnEveryConnection(t *testing.T) {
+	db := Open(t)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("database handle: %v", err)
+	}
+	sqlDB.SetMaxIdleConns(0)
+
+	for i := 0; i < 3; i++ {
+		var enabled int
+		if err := db.Raw("PRAGMA foreign_keys").Scan(&enabled).Error; err != nil {
+			t.Fatalf("read pragma: %v", err)
+		}
+		if enabled != 1 {
+			t.Fatalf("connection %d has foreign_keys = %d, want 1", i, enabled)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+type AchievementHandler struct {
+	achievementService *services.AchievementService
+}
+
+// checkInResponse is a recorded check-in with the achievements it unlocked.
+type checkInResponse struct {
+	models.CheckIn
+	UnlockedAchievements []services.AchievementView `json:"unlocked_achievements,omitempty"`
+}
+
+func NewAchievementHandler(achievementService *services.AchievementService) *AchievementHandler {
+	return &AchievementHandler{achievementService: achievementService}
+}
+
+func (h *AchievementHandler) ListAchievements(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	achievements, err := h.achievementService.List(userID, requestLocale(c))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", achievements)
+}
+
+// unlockAchievements evaluates the user's achievements after a check-in has
+// been written and returns the newly unlocked ones, which responses include
+// as unlocked_achievements for the frontend to celebrate. The check-in is
+// already stored, so a failure here is only logged.
+func unlockAchievements(c *gin.Context, achievementService *services.AchievementService, userID uint) []services.AchievementView {
+	unlocked, err := achievementService.Evaluate(userID, requestLocale(c))
+	if err != nil {
+		log.Printf("Error evaluating achievements for user %d: %v", userID, err)
+		return nil
+	}
+	return unlocked
+}
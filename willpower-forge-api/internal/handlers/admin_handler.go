@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/scheduler"
+	"willpower-forge-api/internal/services"
+)
+
+// AdminHandler serves server administration endpoints. They are open to the
+// users listed in ADMIN_USERNAMES, comma separated; without it nobody is an
+// admin.
+type AdminHandler struct {
+	db                  *gorm.DB
+	scheduler           *scheduler.Scheduler
+	notificationService *services.NotificationService
+}
+
+type AnnouncementRequest struct {
+	Title string `json:"title" binding:"required,max=200"`
+	Body  string `json:"body" binding:"max=5000"`
+	Link  string `json:"link" binding:"max=500"`
+}
+
+func NewAdminHandler(db *gorm.DB, jobs *scheduler.Scheduler, notificationService *services.NotificationService) *AdminHandler {
+	return &AdminHandler{db: db, scheduler: jobs, notificationService: notificationService}
+}
+
+// ListJobs returns the scheduled jobs with their next and latest run.
+func (h *AdminHandler) ListJobs(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	jobs, err := h.scheduler.Jobs()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", jobs)
+}
+
+// TriggerJob starts a job right away and returns its run, which finishes in
+// the background.
+func (h *AdminHandler) TriggerJob(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	run, err := h.scheduler.Trigger(c.Param("name"))
+	if err != nil {
+		switch {
+		case errors.Is(err, scheduler.ErrJobNotFound):
+			respondError(c, http.StatusNotFound, 40401, "Job not found")
+		case errors.Is(err, scheduler.ErrJobRunning):
+			respondError(c, http.StatusConflict, 40902, "Job is already running")
+		case errors.Is(err, scheduler.ErrStopped):
+			respondError(c, http.StatusServiceUnavailable, 50301, "Server is shutting down")
+		default:
+			respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		}
+		return
+	}
+
+	respondSuccess(c, http.StatusAccepted, "Job started", run)
+}
+
+// ListJobRuns returns a job's run history, newest first, paged with limit
+// and offset.
+func (h *AdminHandler) ListJobRuns(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultHistoryLimit)))
+	if err != nil || limit < 1 || limit > maxHistoryLimit {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid limit")
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid offset")
+		return
+	}
+
+	runs, total, err := h.scheduler.Runs(c.Param("name"), limit, offset)
+	if err != nil {
+		if errors.Is(err, scheduler.ErrJobNotFound) {
+			respondError(c, http.StatusNotFound, 40401, "Job not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", gin.H{
+		"runs":  runs,
+		"total": total,
+	})
+}
+
+// Announce adds a system announcement to every user's inbox.
+func (h *AdminHandler) Announce(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	var req AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	recipients, err := h.notificationService.Broadcast(services.NewNotification{
+		Kind:  models.NotificationAnnouncement,
+		Title: req.Title,
+		Body:  req.Body,
+		Link:  req.Link,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidNotification) {
+			respondError(c, http.StatusBadRequest, 40001, "Title is required")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Announcement sent", gin.H{"recipients": recipients})
+}
+
+func (h *AdminHandler) requireAdmin(c *gin.Context) bool {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return false
+	}
+
+	var user models.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+			return false
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return false
+	}
+
+	for _, name := range strings.Split(os.Getenv("ADMIN_USERNAMES"), ",") {
+		if name = strings.TrimSpace(name); name != "" && name == user.Username {
+			return true
+		}
+	}
+
+	respondError(c, http.StatusForbidden, 40301, "Admin access required")
+	return false
+}
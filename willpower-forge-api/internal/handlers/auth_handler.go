@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -22,6 +23,13 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// UpdateProfileRequest changes only the settings it includes.
+type UpdateProfileRequest struct {
+	Timezone            *string `json:"timezone" binding:"omitempty,max=64"`
+	MissedDays          *string `json:"missed_days"`
+	MissedDayGraceHours *int    `json:"missed_day_grace_hours"`
+}
+
 func NewAuthHandler(authService *services.AuthService) *AuthHandler {
 	return &AuthHandler{authService: authService}
 }
@@ -69,3 +77,59 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		"user_id": userID,
 	})
 }
+
+func (h *AuthHandler) GetProfile(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	user, err := h.authService.GetUser(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", user)
+}
+
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	update := services.ProfileUpdate{
+		MissedDays:          req.MissedDays,
+		MissedDayGraceHours: req.MissedDayGraceHours,
+	}
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		update.Timezone = &timezone
+	}
+
+	user, err := h.authService.UpdateProfile(userID, update)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidTimezone:
+			respondError(c, http.StatusBadRequest, 40001, "Invalid timezone")
+		case services.ErrInvalidMissedDays:
+			respondError(c, http.StatusBadRequest, 40001, "Missed days must be record or ignore")
+		case services.ErrInvalidGraceHours:
+			respondError(c, http.StatusBadRequest, 40001, "Grace hours must be between 0 and 72")
+		default:
+			respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		}
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Profile updated", user)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -9,20 +10,30 @@ import (
 	"gorm.io/gorm"
 
 	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
 )
 
 type CheckInHandler struct {
-	db *gorm.DB
+	db                 *gorm.DB
+	checkInService     *services.CheckInService
+	achievementService *services.AchievementService
 }
 
 type CreateCheckInRequest struct {
-	GoalID      uint   `json:"goal_id" binding:"required"`
-	Status      string `json:"status" binding:"required,oneof=completed failed partial"`
-	ReviewNotes string `json:"review_notes"`
+	GoalID       uint     `json:"goal_id" binding:"required"`
+	Status       string   `json:"status" binding:"required,oneof=completed failed partial excused"`
+	ReviewNotes  string   `json:"review_notes"`
+	ExcuseReason string   `json:"excuse_reason" binding:"max=500"`
+	Amount       *float64 `json:"amount"`
+	IntentionID  *uint    `json:"intention_id"`
+	Mood         *int     `json:"mood" binding:"omitempty,min=1,max=5"`
+	Energy       *int     `json:"energy" binding:"omitempty,min=1,max=5"`
+	Stress       *int     `json:"stress" binding:"omitempty,min=1,max=5"`
+	Effort       *int     `json:"effort" binding:"omitempty,min=1,max=5"`
 }
 
-func NewCheckInHandler(db *gorm.DB) *CheckInHandler {
-	return &CheckInHandler{db: db}
+func NewCheckInHandler(db *gorm.DB, checkInService *services.CheckInService, achievementService *services.AchievementService) *CheckInHandler {
+	return &CheckInHandler{db: db, checkInService: checkInService, achievementService: achievementService}
 }
 
 func (h *CheckInHandler) CreateOrUpdateCheckIn(c *gin.Context) {
@@ -48,20 +59,49 @@ func (h *CheckInHandler) CreateOrUpdateCheckIn(c *gin.Context) {
 		return
 	}
 
-	checkIn := models.CheckIn{
-		GoalID:      req.GoalID,
-		UserID:      userID,
-		Date:        time.Now().Format("2006-01-02"),
-		Status:      req.Status,
-		ReviewNotes: req.ReviewNotes,
+	checkIn, err := h.checkInService.RecordCheckIn(goal, services.CheckInInput{
+		Status:       req.Status,
+		ReviewNotes:  req.ReviewNotes,
+		ExcuseReason: req.ExcuseReason,
+		Amount:       req.Amount,
+		IntentionID:  req.IntentionID,
+		Mood:         req.Mood,
+		Energy:       req.Energy,
+		Stress:       req.Stress,
+		Effort:       req.Effort,
+	})
+	if err != nil {
+		respondCheckInError(c, goal, err)
+		return
 	}
 
-	if err := h.db.Create(&checkIn).Error; err != nil {
+	respondSuccess(c, http.StatusCreated, "Check-in recorded", checkInResponse{
+		CheckIn:              checkIn,
+		UnlockedAchievements: unlockAchievements(c, h.achievementService, userID),
+	})
+}
+
+// respondCheckInError maps errors from CheckInService.RecordCheckIn to API
+// responses.
+func respondCheckInError(c *gin.Context, goal models.Goal, err error) {
+	switch {
+	case errors.Is(err, services.ErrGoalNotCheckable):
+		respondError(c, http.StatusConflict, 40902, "Goal is "+goal.Status+" and cannot be checked in")
+	case errors.Is(err, services.ErrExcuseReasonRequired):
+		respondError(c, http.StatusBadRequest, 40001, "An excused check-in needs a reason")
+	case errors.Is(err, services.ErrIntentionMismatch):
+		respondError(c, http.StatusBadRequest, 40001, "Intention does not belong to this goal")
+	case errors.Is(err, services.ErrRatingOutOfRange):
+		respondError(c, http.StatusBadRequest, 40001, "Ratings must be between 1 and 5")
+	case errors.Is(err, services.ErrNegativeAmount):
+		respondError(c, http.StatusBadRequest, 40001, "Amount cannot be negative")
+	case errors.Is(err, services.ErrCheckInDateInFuture):
+		respondError(c, http.StatusBadRequest, 40001, "Invalid date")
+	case errors.Is(err, services.ErrCheckInOutsideGoal):
+		respondError(c, http.StatusBadRequest, 40001, "Date is outside the goal's start and end dates")
+	default:
 		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
-		return
 	}
-
-	respondSuccess(c, http.StatusCreated, "Check-in recorded", checkIn)
 }
 
 func (h *CheckInHandler) ListCheckIns(c *gin.Context) {
@@ -105,18 +145,33 @@ func (h *CheckInHandler) ListCheckIns(c *gin.Context) {
 	respondSuccess(c, http.StatusOK, "Success", checkIns)
 }
 
-type goalSummaryRow struct {
-	GoalID uint
-	Status string
-	Count  int64
+type GoalSummary struct {
+	GoalID         uint    `json:"goal_id"`
+	Title          string  `json:"title"`
+	Status         string  `json:"status"`
+	Completed      int64   `json:"completed"`
+	Partial        int64   `json:"partial"`
+	Failed         int64   `json:"failed"`
+	Missed         int64   `json:"missed"`
+	Excused        int64   `json:"excused"`
+	CompletionRate float64 `json:"completion_rate"`
+	CurrentStreak  int     `json:"current_streak"`
+	LongestStreak  int     `json:"longest_streak"`
+
+	Abstinence *services.AbstinenceStats `json:"abstinence,omitempty"`
 }
 
-type GoalSummary struct {
-	GoalID    uint   `json:"goal_id"`
-	Title     string `json:"title"`
+// TagSummary aggregates the goal summaries of every goal carrying a tag.
+type TagSummary struct {
+	TagID     uint   `json:"tag_id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	GoalCount int    `json:"goal_count"`
 	Completed int64  `json:"completed"`
 	Partial   int64  `json:"partial"`
 	Failed    int64  `json:"failed"`
+	Missed    int64  `json:"missed"`
+	Excused   int64  `json:"excused"`
 }
 
 func (h *CheckInHandler) GoalSummaries(c *gin.Context) {
@@ -126,64 +181,272 @@ func (h *CheckInHandler) GoalSummaries(c *gin.Context) {
 		return
 	}
 
+	dateFilter, tagIDs, ok := parseSummaryFilters(c)
+	if !ok {
+		return
+	}
+
+	summaries, _, err := h.buildGoalSummaries(userID, dateFilter, tagIDs)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", summaries)
+}
+
+func (h *CheckInHandler) TagSummaries(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	dateFilter, tagIDs, ok := parseSummaryFilters(c)
+	if !ok {
+		return
+	}
+
+	goalSummaries, goals, err := h.buildGoalSummaries(userID, dateFilter, tagIDs)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	var tags []models.Tag
+	query := h.db.Where("user_id = ?", userID)
+	if len(tagIDs) > 0 {
+		query = query.Where("id IN ?", tagIDs)
+	}
+	if err := query.Order("name ASC").Find(&tags).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	summaries := make([]TagSummary, 0, len(tags))
+	tagIndex := make(map[uint]int, len(tags))
+	for idx, tag := range tags {
+		summaries = append(summaries, TagSummary{
+			TagID: tag.ID,
+			Name:  tag.Name,
+			Color: tag.Color,
+		})
+		tagIndex[tag.ID] = idx
+	}
+
+	for idx, goal := range goals {
+		for _, tag := range goal.Tags {
+			tagIdx, exists := tagIndex[tag.ID]
+			if !exists {
+				continue
+			}
+			summaries[tagIdx].GoalCount++
+			summaries[tagIdx].Completed += goalSummaries[idx].Completed
+			summaries[tagIdx].Partial += goalSummaries[idx].Partial
+			summaries[tagIdx].Failed += goalSummaries[idx].Failed
+			summaries[tagIdx].Missed += goalSummaries[idx].Missed
+			summaries[tagIdx].Excused += goalSummaries[idx].Excused
+		}
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", summaries)
+}
+
+// RatingAnalytics relates check-in ratings to completion for each of the
+// user's goals, or for the single goal given by goal_id, optionally limited
+// to a from/to date range.
+func (h *CheckInHandler) RatingAnalytics(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	goalQuery := h.db.Where("user_id = ?", userID)
+	if goalIDParam := c.Query("goal_id"); goalIDParam != "" {
+		goalID, err := strconv.ParseUint(goalIDParam, 10, 64)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid goal id")
+			return
+		}
+		goalQuery = goalQuery.Where("id = ?", uint(goalID))
+	}
+
+	var goals []models.Goal
+	if err := goalQuery.Order("created_at ASC").Find(&goals).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+	if len(goals) == 0 && c.Query("goal_id") != "" {
+		respondError(c, http.StatusNotFound, 40401, "Goal not found")
+		return
+	}
+
+	checkInQuery := h.db.Where("user_id = ?", userID)
+	if from != "" {
+		checkInQuery = checkInQuery.Where("date >= ?", from)
+	}
+	if to != "" {
+		checkInQuery = checkInQuery.Where("date <= ?", to)
+	}
+
+	var checkIns []models.CheckIn
+	if err := checkInQuery.Find(&checkIns).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	checkInsByGoal := make(map[uint][]models.CheckIn)
+	for _, checkIn := range checkIns {
+		checkInsByGoal[checkIn.GoalID] = append(checkInsByGoal[checkIn.GoalID], checkIn)
+	}
+
+	results := make([]services.GoalRatingAnalytics, 0, len(goals))
+	for _, goal := range goals {
+		results = append(results, services.GoalRatingAnalytics{
+			GoalID:  goal.ID,
+			Title:   goal.Title,
+			Ratings: services.AnalyzeRatings(checkInsByGoal[goal.ID]),
+		})
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", results)
+}
+
+// parseSummaryFilters reads the date and tag query parameters shared by the
+// summary endpoints, responding with an error and returning false if invalid.
+func parseSummaryFilters(c *gin.Context) (string, []uint, bool) {
 	dateFilter := c.Query("date")
 	if dateFilter != "" {
 		if _, err := time.Parse("2006-01-02", dateFilter); err != nil {
 			respondError(c, http.StatusBadRequest, 40001, "Invalid date format")
-			return
+			return "", nil, false
 		}
 	}
 
+	tagIDs, ok := parseTagFilter(c)
+	if !ok {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid tag filter")
+		return "", nil, false
+	}
+
+	return dateFilter, tagIDs, true
+}
+
+// buildGoalSummaries computes one summary per goal of the user, optionally
+// restricted to check-ins on a single date and to goals carrying any of the
+// given tags. The returned goals are index-aligned with the summaries.
+func (h *CheckInHandler) buildGoalSummaries(userID uint, dateFilter string, tagIDs []uint) ([]GoalSummary, []models.Goal, error) {
+	query := h.db.Preload("Pauses").Preload("StreakFreezes").Preload("Tags").Where("user_id = ?", userID)
+	if len(tagIDs) > 0 {
+		query = query.Where("id IN (SELECT goal_id FROM goal_tags WHERE tag_id IN ?)", tagIDs)
+	}
+
 	var goals []models.Goal
-	if err := h.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&goals).Error; err != nil {
-		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
-		return
+	if err := query.Order("created_at ASC").Find(&goals).Error; err != nil {
+		return nil, nil, err
 	}
 
 	summaries := make([]GoalSummary, 0, len(goals))
-	summaryMap := make(map[uint]int)
-
-	for idx, goal := range goals {
+	for _, goal := range goals {
 		summaries = append(summaries, GoalSummary{
 			GoalID: goal.ID,
 			Title:  goal.Title,
+			Status: goal.Status,
 		})
-		summaryMap[goal.ID] = idx
 	}
 
 	if len(goals) == 0 {
-		respondSuccess(c, http.StatusOK, "Success", summaries)
-		return
+		return summaries, goals, nil
 	}
 
-	var rows []goalSummaryRow
-	query := h.db.Model(&models.CheckIn{}).
-		Select("goal_id, status, COUNT(*) AS count").
-		Where("user_id = ?", userID)
+	var checkIns []models.CheckIn
+	if err := h.db.Where("user_id = ?", userID).Find(&checkIns).Error; err != nil {
+		return nil, nil, err
+	}
 
-	if dateFilter != "" {
-		query = query.Where("date = ?", dateFilter)
+	var vacations []models.VacationPeriod
+	if err := h.db.Where("user_id = ?", userID).Find(&vacations).Error; err != nil {
+		return nil, nil, err
 	}
+	onVacation := services.VacationDates(vacations)
 
-	if err := query.Group("goal_id, status").Find(&rows).Error; err != nil {
-		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
-		return
+	var relapses []models.Relapse
+	if err := h.db.Where("user_id = ?", userID).Find(&relapses).Error; err != nil {
+		return nil, nil, err
+	}
+	relapsesByGoal := make(map[uint][]models.Relapse)
+	for _, relapse := range relapses {
+		relapsesByGoal[relapse.GoalID] = append(relapsesByGoal[relapse.GoalID], relapse)
+	}
+
+	checkInsByGoal := make(map[uint][]models.CheckIn)
+	for _, checkIn := range checkIns {
+		checkInsByGoal[checkIn.GoalID] = append(checkInsByGoal[checkIn.GoalID], checkIn)
+	}
+
+	loc, err := services.UserLocationOf(h.db, userID)
+	if err != nil {
+		return nil, nil, err
 	}
+	now := time.Now().In(loc)
+	today := now.Format("2006-01-02")
+	for idx, goal := range goals {
+		paused := services.PausedDates(goal.Pauses)
+		// Each day counts once, with its latest status, as in goal outcomes.
+		statuses := services.LatestStatusByDate(checkInsByGoal[goal.ID])
+
+		for date, status := range statuses {
+			if dateFilter != "" && date != dateFilter {
+				continue
+			}
+			switch status {
+			case "completed":
+				summaries[idx].Completed++
+			case "partial":
+				summaries[idx].Partial++
+			case "excused":
+				summaries[idx].Excused++
+			case "failed", models.CheckInStatusMissed:
+				// Failures and missed days recorded while a goal was paused
+				// or the user was on vacation are not held against it.
+				if paused(date) {
+					continue
+				}
+				if onVacation(date) {
+					summaries[idx].Excused++
+					continue
+				}
+				if status == models.CheckInStatusMissed {
+					summaries[idx].Missed++
+				} else {
+					summaries[idx].Failed++
+				}
+			}
+		}
 
-	for _, row := range rows {
-		idx, exists := summaryMap[row.GoalID]
-		if !exists {
-			continue
+		if judged := summaries[idx].Completed + summaries[idx].Partial + summaries[idx].Failed + summaries[idx].Missed; judged > 0 {
+			summaries[idx].CompletionRate = float64(summaries[idx].Completed) / float64(judged)
 		}
-		switch row.Status {
-		case "completed":
-			summaries[idx].Completed = row.Count
-		case "partial":
-			summaries[idx].Partial = row.Count
-		case "failed":
-			summaries[idx].Failed = row.Count
+
+		endDate := today
+		if goal.EndDate != "" && goal.EndDate < endDate {
+			endDate = goal.EndDate
+		}
+		streaks := services.ComputeStreaks(statuses, goal.EffectiveStartDate(), endDate, services.NeutralDates(goal, vacations))
+		summaries[idx].CurrentStreak = streaks.CurrentStreak
+		summaries[idx].LongestStreak = streaks.LongestStreak
+
+		if goal.Type == "I_WONT" {
+			abstinence := services.ComputeAbstinence(goal, relapsesByGoal[goal.ID], now)
+			summaries[idx].Abstinence = &abstinence
 		}
 	}
 
-	respondSuccess(c, http.StatusOK, "Success", summaries)
+	return summaries, goals, nil
 }
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+func TestGoalSummariesCountDaysLikeOutcomes(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db)
+	goal := models.Goal{UserID: user.ID, Type: "I_WILL", Title: "Read", Status: models.GoalStatusActive,
+		StartDate: "2026-03-02", EndDate: "2026-03-04"}
+	if err := db.Create(&goal).Error; err != nil {
+		t.Fatalf("create goal: %v", err)
+	}
+
+	// Days checked in more than once count once, with the latest status.
+	created := time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC)
+	for _, checkIn := range []models.CheckIn{
+		{Date: "2026-03-02", Status: "failed"},
+		{Date: "2026-03-02", Status: "completed"},
+		{Date: "2026-03-03", Status: "partial"},
+		{Date: "2026-03-03", Status: "partial"},
+		{Date: "2026-03-04", Status: "completed"},
+	} {
+		checkIn.GoalID, checkIn.UserID, checkIn.CreatedAt = goal.ID, user.ID, created
+		created = created.Add(time.Minute)
+		if err := db.Create(&checkIn).Error; err != nil {
+			t.Fatalf("create check-in: %v", err)
+		}
+	}
+
+	handler := NewCheckInHandler(db, services.NewCheckInService(db, services.NewPointsService(db)), nil)
+	router := newTestRouter(user.ID)
+	router.GET("/checkins/summary", handler.GoalSummaries)
+
+	var summaries []GoalSummary
+	if code := doJSON(t, router, http.MethodGet, "/checkins/summary", nil, &summaries); code != http.StatusOK {
+		t.Fatalf("summary: status %d", code)
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("got %d summaries, want 1", len(summaries))
+	}
+	summary := summaries[0]
+
+	outcome, err := services.BuildGoalOutcome(db, goal, goal.EndDate)
+	if err != nil {
+		t.Fatalf("build outcome: %v", err)
+	}
+	if summary.Completed != 2 || summary.Partial != 1 || summary.Failed != 0 {
+		t.Errorf("summary counts completed=%d partial=%d failed=%d, want 2, 1 and 0",
+			summary.Completed, summary.Partial, summary.Failed)
+	}
+	if summary.Completed != outcome.Completed || summary.Partial != outcome.Partial || summary.Failed != outcome.Failed {
+		t.Errorf("summary %+v disagrees with outcome %+v", summary, outcome)
+	}
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+type DigestHandler struct {
+	digestService *services.DigestService
+}
+
+// UpdateDigestRequest changes only the settings it includes. The locale
+// defaults to the request's language when the subscription is created.
+type UpdateDigestRequest struct {
+	Email    *string `json:"email" binding:"omitempty,max=254"`
+	Locale   *string `json:"locale" binding:"omitempty,oneof=en zh"`
+	Daily    *bool   `json:"daily"`
+	Weekly   *bool   `json:"weekly"`
+	SendTime *string `json:"send_time"`
+}
+
+type TestDigestRequest struct {
+	Kind string `json:"kind" binding:"required,oneof=daily weekly"`
+}
+
+func NewDigestHandler(digestService *services.DigestService) *DigestHandler {
+	return &DigestHandler{digestService: digestService}
+}
+
+// GetSubscription returns the user's digest settings, all off until saved.
+func (h *DigestHandler) GetSubscription(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	subscription, err := h.digestService.Get(userID)
+	if err != nil {
+		if !errors.Is(err, services.ErrDigestNotFound) {
+			respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+			return
+		}
+		subscription = models.DigestSubscription{UserID: userID, Locale: requestLocale(c), SendTime: "07:00"}
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", subscription)
+}
+
+func (h *DigestHandler) UpdateSubscription(c *gin.Context) {
+	var req UpdateDigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	update := services.DigestUpdate{
+		Email:    req.Email,
+		Locale:   req.Locale,
+		Daily:    req.Daily,
+		Weekly:   req.Weekly,
+		SendTime: req.SendTime,
+	}
+	if update.Locale == nil {
+		if _, err := h.digestService.Get(userID); errors.Is(err, services.ErrDigestNotFound) {
+			locale := requestLocale(c)
+			update.Locale = &locale
+		}
+	}
+
+	subscription, err := h.digestService.Save(userID, update)
+	if err != nil {
+		respondDigestError(c, err)
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Digest settings updated", subscription)
+}
+
+// TestDigest sends the daily or weekly digest right away so the user can
+// see what it looks like. Delivery failures are reported in the returned
+// delivery.
+func (h *DigestHandler) TestDigest(c *gin.Context) {
+	var req TestDigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	subscription, err := h.digestService.Get(userID)
+	if err != nil {
+		respondDigestError(c, err)
+		return
+	}
+
+	delivery, err := h.digestService.SendTest(subscription, req.Kind)
+	if err != nil {
+		respondDigestError(c, err)
+		return
+	}
+
+	message := "Test digest sent"
+	if delivery.Status == models.DeliveryStatusFailed {
+		message = "Test digest failed"
+	}
+	respondSuccess(c, http.StatusOK, message, delivery)
+}
+
+// ListDeliveries returns the user's digest deliveries, newest first, paged
+// with limit and offset.
+func (h *DigestHandler) ListDeliveries(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultHistoryLimit)))
+	if err != nil || limit < 1 || limit > maxHistoryLimit {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid limit")
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid offset")
+		return
+	}
+
+	deliveries, total, err := h.digestService.Deliveries(userID, limit, offset)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", gin.H{
+		"deliveries": deliveries,
+		"total":      total,
+	})
+}
+
+// ConfirmUnsubscribe serves the page an email's unsubscribe link opens. It
+// only asks for confirmation, so mail scanners following links cannot
+// unsubscribe anyone.
+func (h *DigestHandler) ConfirmUnsubscribe(c *gin.Context) {
+	page := services.UnsubscribePage{
+		Locale: requestLocale(c),
+		State:  services.UnsubscribeConfirm,
+		Token:  c.Query("token"),
+		Kind:   c.Query("kind"),
+	}
+	if page.Token == "" {
+		page.State = services.UnsubscribeInvalid
+	}
+	renderUnsubscribePage(c, http.StatusOK, page)
+}
+
+// Unsubscribe turns digests off for the token in the query, without logging
+// in. It serves both the confirmation form and one-click unsubscribe from
+// mail clients (RFC 8058). kind=daily or kind=weekly limits it to one
+// digest.
+func (h *DigestHandler) Unsubscribe(c *gin.Context) {
+	page := services.UnsubscribePage{Locale: requestLocale(c)}
+
+	subscription, err := h.digestService.Unsubscribe(c.Query("token"), c.Query("kind"))
+	if err != nil {
+		if errors.Is(err, services.ErrDigestNotFound) {
+			page.State = services.UnsubscribeInvalid
+			renderUnsubscribePage(c, http.StatusNotFound, page)
+			return
+		}
+		page.State = services.UnsubscribeFailed
+		renderUnsubscribePage(c, http.StatusInternalServerError, page)
+		return
+	}
+
+	page.Locale = subscription.Locale
+	page.State = services.UnsubscribeDone
+	renderUnsubscribePage(c, http.StatusOK, page)
+}
+
+func respondDigestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDigestNotFound):
+		respondError(c, http.StatusNotFound, 40401, "Digests are not set up")
+	case errors.Is(err, services.ErrDigestUnavailable):
+		respondError(c, http.StatusBadRequest, 40001, "Email is not configured on this server")
+	case errors.Is(err, services.ErrInvalidDigestEmail):
+		respondError(c, http.StatusBadRequest, 40001, "Invalid email address")
+	case errors.Is(err, services.ErrInvalidDigestTime):
+		respondError(c, http.StatusBadRequest, 40001, "Invalid send time, expected HH:MM")
+	default:
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+	}
+}
+
+func renderUnsubscribePage(c *gin.Context, status int, page services.UnsubscribePage) {
+	html, err := services.RenderUnsubscribePage(page)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", []byte(html))
+}
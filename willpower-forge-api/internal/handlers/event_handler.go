@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"willpower-forge-api/internal/services"
+)
+
+// eventHeartbeatInterval keeps idle streams from being closed by proxies.
+const eventHeartbeatInterval = 25 * time.Second
+
+type EventHandler struct {
+	events *services.EventBroker
+}
+
+func NewEventHandler(events *services.EventBroker) *EventHandler {
+	return &EventHandler{events: events}
+}
+
+// Stream pushes the user's goal, check-in and notification changes as
+// Server-Sent Events until the client disconnects. A client reconnecting
+// with Last-Event-ID (or ?last_event_id=) first gets the events it missed,
+// or just a reset event when they are no longer all buffered.
+func (h *EventHandler) Stream(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		var err error
+		if lastID, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid Last-Event-ID")
+			return
+		}
+	}
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		respondError(c, http.StatusInternalServerError, 50001, "Streaming not supported")
+		return
+	}
+
+	sub, replay, complete := h.events.Subscribe(userID, lastID, lastEventID != "")
+	defer sub.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+	if complete {
+		for _, event := range replay {
+			writeStreamEvent(c, event)
+		}
+	} else {
+		fmt.Fprintf(c.Writer, "event: %s\ndata: {}\n\n", services.EventReset)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			writeStreamEvent(c, event)
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+func writeStreamEvent(c *gin.Context, event services.StreamEvent) {
+	fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"willpower-forge-api/internal/services"
+)
+
+// openStream requests the event stream as a client that has already gone
+// away, so the handler returns once it has written the replay.
+func openStream(t *testing.T, events *services.EventBroker, userID uint, path, lastEventID string) *httptest.ResponseRecorder {
+	t.Helper()
+	router := newTestRouter(userID)
+	router.GET("/events/stream", NewEventHandler(events).Stream)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, path, nil).WithContext(ctx)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// streamedEvents lists the events in a stream body as "id type" pairs.
+func streamedEvents(body string) []string {
+	var events []string
+	for _, block := range strings.Split(body, "\n\n") {
+		var id, kind string
+		for _, line := range strings.Split(block, "\n") {
+			if strings.HasPrefix(line, "id: ") {
+				id = strings.TrimPrefix(line, "id: ")
+			} else if strings.HasPrefix(line, "event: ") {
+				kind = strings.TrimPrefix(line, "event: ")
+			}
+		}
+		if kind != "" {
+			events = append(events, strings.TrimSpace(id+" "+kind))
+		}
+	}
+	return events
+}
+
+func TestStreamReplaysMissedEvents(t *testing.T) {
+	const alice, bob = 1, 2
+	events := services.NewEventBroker()
+	seen, _, _ := events.Subscribe(alice, 0, false)
+	defer seen.Close()
+
+	events.Publish(alice, services.EventNotificationCreated, map[string]int{"id": 1})
+	events.Publish(bob, services.EventNotificationCreated, map[string]int{"id": 2})
+	events.Publish(alice, services.EventNotificationRead, map[string]int{"id": 1})
+	events.PublishAll(services.EventNotificationAnnounced, map[string]string{"title": "Maintenance"})
+	var ids []uint64
+	for len(seen.Events()) > 0 {
+		ids = append(ids, (<-seen.Events()).ID)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("alice received %d events, want 3", len(ids))
+	}
+
+	want := []string{
+		fmt.Sprintf("%d %s", ids[1], services.EventNotificationRead),
+		fmt.Sprintf("%d %s", ids[2], services.EventNotificationAnnounced),
+	}
+	for name, rec := range map[string]*httptest.ResponseRecorder{
+		"header": openStream(t, events, alice, "/events/stream", fmt.Sprint(ids[0])),
+		"query":  openStream(t, events, alice, fmt.Sprintf("/events/stream?last_event_id=%d", ids[0]), ""),
+	} {
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("%s: status %d, content type %q", name, rec.Code, rec.Header().Get("Content-Type"))
+		}
+		if got := streamedEvents(rec.Body.String()); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: replayed %v, want %v", name, got, want)
+		}
+	}
+
+	// A client that is caught up, or opens a fresh stream, gets no replay.
+	for _, lastEventID := range []string{fmt.Sprint(ids[2]), ""} {
+		if got := streamedEvents(openStream(t, events, alice, "/events/stream", lastEventID).Body.String()); len(got) != 0 {
+			t.Errorf("stream from %q replayed %v, want nothing", lastEventID, got)
+		}
+	}
+}
+
+func TestStreamResetsStaleEventIDs(t *testing.T) {
+	events := services.NewEventBroker()
+	events.Publish(1, services.EventNotificationCreated, map[string]int{"id": 1})
+
+	// An ID from before the server started, or one it never issued.
+	for _, lastEventID := range []string{"1", "18446744073709551615"} {
+		got := streamedEvents(openStream(t, events, 1, "/events/stream", lastEventID).Body.String())
+		if len(got) != 1 || got[0] != services.EventReset {
+			t.Errorf("stream from %s = %v, want only a reset event", lastEventID, got)
+		}
+	}
+
+	if rec := openStream(t, events, 1, "/events/stream", "latest"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid Last-Event-ID: status %d, want 400", rec.Code)
+	}
+}
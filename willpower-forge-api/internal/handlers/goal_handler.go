@@ -1,35 +1,79 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"willpower-forge-api/internal/markdown"
 	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
 )
 
 type GoalHandler struct {
-	db *gorm.DB
+	db        *gorm.DB
+	lifecycle *services.GoalLifecycleService
+	relapses  *services.RelapseService
+	webhooks  *services.WebhookService
+	events    *services.EventBroker
 }
 
 type CreateGoalRequest struct {
-	Type  string `json:"type" binding:"required,oneof=I_WILL I_WONT I_WANT"`
-	Title string `json:"title" binding:"required,min=1,max=255"`
+	Type        string `json:"type" binding:"required,oneof=I_WILL I_WONT I_WANT"`
+	Title       string `json:"title" binding:"required,min=1,max=255"`
+	Description string `json:"description" binding:"max=10000"`
+	Motivation  string `json:"motivation" binding:"max=2000"`
+	Schedule    string `json:"schedule"`
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date"`
+	TagIDs      []uint `json:"tag_ids"`
+
+	BaselinePerDay    float64 `json:"baseline_per_day" binding:"min=0"`
+	CostPerOccurrence float64 `json:"cost_per_occurrence" binding:"min=0"`
+	CostUnit          string  `json:"cost_unit" binding:"max=20"`
+	Difficulty        string  `json:"difficulty" binding:"omitempty,oneof=easy medium hard"`
+	MissedDays        string  `json:"missed_days" binding:"omitempty,oneof=record ignore"`
 }
 
 type UpdateGoalStatusRequest struct {
-	Status string `json:"status" binding:"required,oneof=active archived"`
+	Status string `json:"status" binding:"required,oneof=active paused completed archived"`
 }
 
 type UpdateGoalRequest struct {
-	Type  string `json:"type" binding:"omitempty,oneof=I_WILL I_WONT I_WANT"`
-	Title string `json:"title" binding:"omitempty,min=1,max=255"`
+	Type        string  `json:"type" binding:"omitempty,oneof=I_WILL I_WONT I_WANT"`
+	Title       string  `json:"title" binding:"omitempty,min=1,max=255"`
+	Description *string `json:"description" binding:"omitempty,max=10000"`
+	Motivation  *string `json:"motivation" binding:"omitempty,max=2000"`
+	Schedule    *string `json:"schedule"`
+	StartDate   string  `json:"start_date"`
+	// EndDate set to "" removes the goal's end date.
+	EndDate *string `json:"end_date"`
+
+	BaselinePerDay    *float64 `json:"baseline_per_day" binding:"omitempty,min=0"`
+	CostPerOccurrence *float64 `json:"cost_per_occurrence" binding:"omitempty,min=0"`
+	CostUnit          *string  `json:"cost_unit" binding:"omitempty,max=20"`
+	Difficulty        *string  `json:"difficulty" binding:"omitempty,oneof=easy medium hard"`
+	// MissedDays set to "" makes the goal follow the user's missed-day mode
+	// again.
+	MissedDays *string `json:"missed_days"`
 }
 
-func NewGoalHandler(db *gorm.DB) *GoalHandler {
-	return &GoalHandler{db: db}
+// goalDetail is the single-goal response. Goals carry their milestone and
+// checklist progress, and I_WONT goals their abstinence timer, alongside the
+// goal fields.
+type goalDetail struct {
+	models.Goal
+	Progress   services.GoalProgress     `json:"progress"`
+	Abstinence *services.AbstinenceStats `json:"abstinence,omitempty"`
+}
+
+func NewGoalHandler(db *gorm.DB, lifecycle *services.GoalLifecycleService, relapses *services.RelapseService, webhooks *services.WebhookService, events *services.EventBroker) *GoalHandler {
+	return &GoalHandler{db: db, lifecycle: lifecycle, relapses: relapses, webhooks: webhooks, events: events}
 }
 
 func (h *GoalHandler) CreateGoal(c *gin.Context) {
@@ -45,18 +89,74 @@ func (h *GoalHandler) CreateGoal(c *gin.Context) {
 		return
 	}
 
-	goal := models.Goal{
-		UserID: userID,
-		Type:   req.Type,
-		Title:  req.Title,
-		Status: "active",
+	today, err := services.UserToday(h.db, userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+	startDate := req.StartDate
+	if startDate == "" {
+		startDate = today
 	}
 
-	if err := h.db.Create(&goal).Error; err != nil {
+	if !isValidDate(startDate) || (req.EndDate != "" && (!isValidDate(req.EndDate) || req.EndDate < startDate)) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid start or end date")
+		return
+	}
+
+	schedule, ok := models.NormalizeSchedule(req.Schedule)
+	if !ok {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid schedule")
+		return
+	}
+
+	status := models.GoalStatusActive
+	if startDate > today {
+		status = models.GoalStatusScheduled
+	}
+
+	difficulty := req.Difficulty
+	if difficulty == "" {
+		difficulty = models.DifficultyMedium
+	}
+
+	goal := models.Goal{
+		UserID:      userID,
+		Type:        req.Type,
+		Title:       req.Title,
+		Description: markdown.Sanitize(req.Description),
+		Motivation:  markdown.Sanitize(req.Motivation),
+		Status:      status,
+		Schedule:    schedule,
+		StartDate:   startDate,
+		EndDate:     req.EndDate,
+
+		BaselinePerDay:    req.BaselinePerDay,
+		CostPerOccurrence: req.CostPerOccurrence,
+		CostUnit:          strings.TrimSpace(req.CostUnit),
+		Difficulty:        difficulty,
+		MissedDays:        req.MissedDays,
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		tags, err := findUserTags(tx, userID, req.TagIDs)
+		if err != nil {
+			return err
+		}
+		goal.Tags = tags
+		return tx.Create(&goal).Error
+	})
+	if err != nil {
+		if errors.Is(err, errTagInvalid) {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid tag ids")
+			return
+		}
 		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
 		return
 	}
 
+	h.webhooks.GoalEvent(models.EventGoalCreated, goal)
+	h.events.GoalEvent(models.EventGoalCreated, goal)
 	respondSuccess(c, http.StatusCreated, "Goal created", goal)
 }
 
@@ -67,8 +167,19 @@ func (h *GoalHandler) GetGoals(c *gin.Context) {
 		return
 	}
 
+	tagIDs, ok := parseTagFilter(c)
+	if !ok {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid tag filter")
+		return
+	}
+
+	query := h.db.Preload("Tags").Where("user_id = ?", userID)
+	if len(tagIDs) > 0 {
+		query = query.Where("id IN (SELECT goal_id FROM goal_tags WHERE tag_id IN ?)", tagIDs)
+	}
+
 	var goals []models.Goal
-	if err := h.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&goals).Error; err != nil {
+	if err := query.Order("created_at DESC").Find(&goals).Error; err != nil {
 		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
 		return
 	}
@@ -93,7 +204,9 @@ func (h *GoalHandler) GetGoalByID(c *gin.Context) {
 	goalIDUint := uint(goalID)
 
 	var goal models.Goal
-	if err := h.db.Where("id = ? AND user_id = ?", goalIDUint, userID).First(&goal).Error; err != nil {
+	if err := h.db.Preload("Pauses").Preload("StreakFreezes").Preload("Outcome").Preload("Tags").
+		Preload("Intentions", orderByPosition).Preload("Milestones", orderByPosition).Preload("Checklist", orderByPosition).
+		Where("id = ? AND user_id = ?", goalIDUint, userID).First(&goal).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			respondError(c, http.StatusNotFound, 40401, "Goal not found")
 			return
@@ -102,7 +215,17 @@ func (h *GoalHandler) GetGoalByID(c *gin.Context) {
 		return
 	}
 
-	respondSuccess(c, http.StatusOK, "Success", goal)
+	detail := goalDetail{Goal: goal, Progress: services.ComputeProgress(goal.Milestones, goal.Checklist)}
+	if goal.Type == "I_WONT" {
+		abstinence, err := h.relapses.Abstinence(goal)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+			return
+		}
+		detail.Abstinence = &abstinence
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", detail)
 }
 
 func (h *GoalHandler) UpdateGoalStatus(c *gin.Context) {
@@ -140,12 +263,17 @@ func (h *GoalHandler) UpdateGoalStatus(c *gin.Context) {
 		return
 	}
 
-	if err := h.db.Model(&goal).Update("status", req.Status).Error; err != nil {
+	if err := h.lifecycle.ChangeStatus(&goal, req.Status); err != nil {
+		if errors.Is(err, services.ErrInvalidStatusTransition) {
+			respondError(c, http.StatusConflict, 40902, "Goal cannot move from "+goal.Status+" to "+req.Status)
+			return
+		}
 		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
 		return
 	}
 
-	goal.Status = req.Status
+	h.webhooks.GoalEvent(models.EventGoalStatusChanged, goal)
+	h.events.GoalEvent(models.EventGoalStatusChanged, goal)
 	respondSuccess(c, http.StatusOK, "Goal status updated", goal)
 }
 
@@ -187,6 +315,74 @@ func (h *GoalHandler) UpdateGoal(c *gin.Context) {
 	if req.Title != "" {
 		updates["title"] = req.Title
 	}
+	if req.Description != nil {
+		updates["description"] = markdown.Sanitize(*req.Description)
+	}
+	if req.Motivation != nil {
+		updates["motivation"] = markdown.Sanitize(*req.Motivation)
+	}
+	if req.Schedule != nil {
+		schedule, ok := models.NormalizeSchedule(*req.Schedule)
+		if !ok {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid schedule")
+			return
+		}
+		updates["schedule"] = schedule
+	}
+	if req.BaselinePerDay != nil {
+		updates["baseline_per_day"] = *req.BaselinePerDay
+	}
+	if req.CostPerOccurrence != nil {
+		updates["cost_per_occurrence"] = *req.CostPerOccurrence
+	}
+	if req.CostUnit != nil {
+		updates["cost_unit"] = strings.TrimSpace(*req.CostUnit)
+	}
+	if req.Difficulty != nil {
+		updates["difficulty"] = *req.Difficulty
+	}
+	if req.MissedDays != nil {
+		switch *req.MissedDays {
+		case "", models.MissedDaysRecord, models.MissedDaysIgnore:
+			updates["missed_days"] = *req.MissedDays
+		default:
+			respondError(c, http.StatusBadRequest, 40001, "Invalid missed days mode")
+			return
+		}
+	}
+
+	startDate := goal.EffectiveStartDate()
+	endDate := goal.EndDate
+	if req.StartDate != "" {
+		if goal.Status != models.GoalStatusScheduled {
+			respondError(c, http.StatusConflict, 40902, "Start date can only be changed before the goal starts")
+			return
+		}
+		startDate = req.StartDate
+		updates["start_date"] = req.StartDate
+	}
+	if req.EndDate != nil {
+		if goal.Status == models.GoalStatusCompleted {
+			respondError(c, http.StatusConflict, 40902, "Goal is already completed")
+			return
+		}
+		endDate = *req.EndDate
+		updates["end_date"] = *req.EndDate
+	}
+	if !isValidDate(startDate) || (endDate != "" && (!isValidDate(endDate) || endDate < startDate)) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid start or end date")
+		return
+	}
+	if _, ok := updates["start_date"]; ok {
+		today, err := services.UserToday(h.db, userID)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+			return
+		}
+		if startDate <= today {
+			updates["status"] = models.GoalStatusActive
+		}
+	}
 
 	if len(updates) == 0 {
 		respondSuccess(c, http.StatusOK, "No updates provided", goal)
@@ -204,6 +400,8 @@ func (h *GoalHandler) UpdateGoal(c *gin.Context) {
 		return
 	}
 
+	h.webhooks.GoalEvent(models.EventGoalUpdated, goal)
+	h.events.GoalEvent(models.EventGoalUpdated, goal)
 	respondSuccess(c, http.StatusOK, "Goal updated", goal)
 }
 
@@ -237,6 +435,8 @@ func (h *GoalHandler) DeleteGoal(c *gin.Context) {
 		return
 	}
 
+	h.webhooks.GoalEvent(models.EventGoalDeleted, goal)
+	h.events.GoalEvent(models.EventGoalDeleted, goal)
 	respondSuccess(c, http.StatusOK, "Goal deleted", nil)
 }
 
@@ -286,6 +486,9 @@ func (h *GoalHandler) RestoreGoal(c *gin.Context) {
 		return
 	}
 
+	goal.DeletedAt = gorm.DeletedAt{}
+	h.webhooks.GoalEvent(models.EventGoalRestored, goal)
+	h.events.GoalEvent(models.EventGoalRestored, goal)
 	respondSuccess(c, http.StatusOK, "Goal restored", goal)
 }
 
@@ -319,9 +522,49 @@ func (h *GoalHandler) PermanentDeleteGoal(c *gin.Context) {
 		return
 	}
 
+	h.events.Publish(userID, services.EventGoalPurged, gin.H{"id": goal.ID})
 	respondSuccess(c, http.StatusOK, "Goal permanently deleted", nil)
 }
 
+// loadUserGoal loads the goal named by the :id path parameter for the current
+// user, responding with an error and returning false if it cannot.
+func loadUserGoal(c *gin.Context, db *gorm.DB) (models.Goal, bool) {
+	var goal models.Goal
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return goal, false
+	}
+
+	goalID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid goal id")
+		return goal, false
+	}
+
+	if err := db.Where("id = ? AND user_id = ?", uint(goalID), userID).First(&goal).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, 40401, "Goal not found")
+			return goal, false
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return goal, false
+	}
+
+	return goal, true
+}
+
+// orderByPosition sorts user-ordered rows such as intentions and milestones.
+func orderByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
+
+func isValidDate(value string) bool {
+	_, err := time.Parse("2006-01-02", value)
+	return err == nil
+}
+
 func getUserID(c *gin.Context) (uint, bool) {
 	val, exists := c.Get("user_id")
 	if !exists {
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+func TestUpdateGoalEndDate(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db)
+	goal := models.Goal{UserID: user.ID, Type: "I_WILL", Title: "Read", Status: models.GoalStatusActive,
+		StartDate: "2026-03-01", EndDate: "2026-03-31"}
+	if err := db.Create(&goal).Error; err != nil {
+		t.Fatalf("create goal: %v", err)
+	}
+	handler := NewGoalHandler(db, services.NewGoalLifecycleService(db), services.NewRelapseService(db),
+		services.NewWebhookService(db), services.NewEventBroker())
+	router := newTestRouter(user.ID)
+	router.PUT("/goals/:id", handler.UpdateGoal)
+	path := "/goals/" + strconv.FormatUint(uint64(goal.ID), 10)
+
+	tests := []struct {
+		name string
+		body map[string]interface{}
+		code int
+		want string
+	}{
+		{"omitted keeps it", map[string]interface{}{"title": "Read more"}, http.StatusOK, "2026-03-31"},
+		{"new date", map[string]interface{}{"end_date": "2026-04-30"}, http.StatusOK, "2026-04-30"},
+		{"before the start", map[string]interface{}{"end_date": "2026-02-01"}, http.StatusBadRequest, "2026-04-30"},
+		{"empty clears it", map[string]interface{}{"end_date": ""}, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		if code := doJSON(t, router, http.MethodPut, path, tt.body, nil); code != tt.code {
+			t.Errorf("%s: status %d, want %d", tt.name, code, tt.code)
+		}
+		var stored models.Goal
+		if err := db.First(&stored, goal.ID).Error; err != nil {
+			t.Fatalf("load goal: %v", err)
+		}
+		if stored.EndDate != tt.want {
+			t.Errorf("%s: end date %q, want %q", tt.name, stored.EndDate, tt.want)
+		}
+	}
+}
+
+func TestCreateGoalStartsOnUserToday(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db)
+	zone := zoneOffServerDate(t)
+	db.Model(&user).Update("timezone", zone)
+	handler := NewGoalHandler(db, services.NewGoalLifecycleService(db), services.NewRelapseService(db),
+		services.NewWebhookService(db), services.NewEventBroker())
+	router := newTestRouter(user.ID)
+	router.POST("/goals", handler.CreateGoal)
+
+	today := services.TodayIn(zone)
+	for _, body := range []map[string]interface{}{
+		{"type": "I_WILL", "title": "Read"},
+		{"type": "I_WILL", "title": "Walk", "start_date": today},
+	} {
+		var goal models.Goal
+		if code := doJSON(t, router, http.MethodPost, "/goals", body, &goal); code != http.StatusCreated {
+			t.Fatalf("create %v: status %d", body["title"], code)
+		}
+		if goal.StartDate != today || goal.Status != models.GoalStatusActive {
+			t.Errorf("%s: start %s, status %s, want an active goal starting on the user's today %s",
+				goal.Title, goal.StartDate, goal.Status, today)
+		}
+	}
+}
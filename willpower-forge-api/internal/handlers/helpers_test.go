@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func createTestUser(t *testing.T, db *gorm.DB) models.User {
+	t.Helper()
+	user := models.User{Username: "alice", PasswordHash: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+// newTestRouter returns a router whose requests are authenticated as userID.
+func newTestRouter(userID uint) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	return router
+}
+
+// doJSON sends body as JSON and decodes the response's data into out.
+func doJSON(t *testing.T, router *gin.Engine, method, path string, body interface{}, out interface{}) int {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatalf("encode request: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if out != nil {
+		envelope := struct {
+			Data json.RawMessage `json:"data"`
+		}{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+			t.Fatalf("decode response %s: %v", rec.Body.String(), err)
+		}
+		if err := json.Unmarshal(envelope.Data, out); err != nil {
+			t.Fatalf("decode data %s: %v", envelope.Data, err)
+		}
+	}
+	return rec.Code
+}
+
+// zoneOffServerDate returns a timezone whose date currently differs from the
+// server's, or skips the test if there is none.
+func zoneOffServerDate(t *testing.T) string {
+	t.Helper()
+	serverToday := time.Now().Format("2006-01-02")
+	for _, zone := range []string{"Pacific/Kiritimati", "Pacific/Pago_Pago", "Pacific/Auckland", "America/Los_Angeles"} {
+		if services.TodayIn(zone) != serverToday {
+			return zone
+		}
+	}
+	t.Skip("no timezone is on a different date than the server right now")
+	return ""
+}
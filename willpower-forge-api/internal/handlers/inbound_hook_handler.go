@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+type InboundHookHandler struct {
+	db                 *gorm.DB
+	inboundHookService *services.InboundHookService
+	achievementService *services.AchievementService
+}
+
+type CreateInboundHookRequest struct {
+	Name string `json:"name" binding:"max=100"`
+}
+
+// InboundCheckInRequest is the payload accepted by inbound hook URLs. An
+// empty body records a completed check-in; for excused check-ins the note is
+// the excuse.
+type InboundCheckInRequest struct {
+	Status string   `json:"status" binding:"omitempty,oneof=completed failed partial excused"`
+	Amount *float64 `json:"amount"`
+	Note   string   `json:"note" binding:"max=2000"`
+}
+
+// inboundHookWithURL is returned when a hook is created or its token
+// rotated, the only times its URL is shown.
+type inboundHookWithURL struct {
+	models.InboundHook
+	Token string `json:"token"`
+	Path  string `json:"path"`
+}
+
+func NewInboundHookHandler(db *gorm.DB, inboundHookService *services.InboundHookService, achievementService *services.AchievementService) *InboundHookHandler {
+	return &InboundHookHandler{db: db, inboundHookService: inboundHookService, achievementService: achievementService}
+}
+
+func (h *InboundHookHandler) ListHooks(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	var hooks []models.InboundHook
+	if err := h.db.Where("goal_id = ?", goal.ID).Order("id ASC").Find(&hooks).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", hooks)
+}
+
+func (h *InboundHookHandler) CreateHook(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	var req CreateInboundHookRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = goal.Title
+	}
+
+	hook, token, err := h.inboundHookService.Create(goal, name)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Inbound hook created", withInboundHookURL(hook, token))
+}
+
+// RotateToken gives the hook a new URL and returns it. The previous URL
+// stops working right away.
+func (h *InboundHookHandler) RotateToken(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	hook, ok := h.findHook(c, goal.ID)
+	if !ok {
+		return
+	}
+
+	token, err := h.inboundHookService.Rotate(&hook)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Inbound hook token rotated", withInboundHookURL(hook, token))
+}
+
+// DeleteHook revokes the hook's URL and drops its call log.
+func (h *InboundHookHandler) DeleteHook(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	hook, ok := h.findHook(c, goal.ID)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("hook_id = ?", hook.ID).Delete(&models.InboundHookCall{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&hook).Error
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Inbound hook revoked", nil)
+}
+
+// ListCalls returns the hook's call log, newest first, paged with limit and
+// offset.
+func (h *InboundHookHandler) ListCalls(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	hook, ok := h.findHook(c, goal.ID)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultHistoryLimit)))
+	if err != nil || limit < 1 || limit > maxHistoryLimit {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid limit")
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid offset")
+		return
+	}
+
+	calls, total, err := h.inboundHookService.Calls(hook.ID, limit, offset)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", gin.H{
+		"calls": calls,
+		"total": total,
+	})
+}
+
+// Receive records a check-in sent to an inbound hook URL. It needs no login:
+// the token in the path identifies the hook and its goal.
+func (h *InboundHookHandler) Receive(c *gin.Context) {
+	hook, err := h.inboundHookService.Authorize(c.Param("token"), c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRateLimited):
+			respondError(c, http.StatusTooManyRequests, 42901, "Too many requests, try again later")
+		case errors.Is(err, services.ErrInboundHookNotFound):
+			respondError(c, http.StatusNotFound, 40401, "Inbound hook not found")
+		default:
+			respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		}
+		return
+	}
+
+	var req InboundCheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.inboundHookService.Reject(hook, c.ClientIP(), "invalid payload")
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	input := services.CheckInInput{
+		Status:      req.Status,
+		ReviewNotes: strings.TrimSpace(req.Note),
+		Amount:      req.Amount,
+	}
+	if input.Status == "" {
+		input.Status = "completed"
+	}
+	if input.Status == "excused" {
+		input.ExcuseReason = input.ReviewNotes
+	}
+
+	goal, checkIn, err := h.inboundHookService.Record(hook, c.ClientIP(), input)
+	if err != nil {
+		if errors.Is(err, services.ErrInboundHookNotFound) {
+			respondError(c, http.StatusNotFound, 40401, "Inbound hook not found")
+			return
+		}
+		respondCheckInError(c, goal, err)
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Check-in recorded", checkInResponse{
+		CheckIn:              checkIn,
+		UnlockedAchievements: unlockAchievements(c, h.achievementService, goal.UserID),
+	})
+}
+
+func (h *InboundHookHandler) findHook(c *gin.Context, goalID uint) (models.InboundHook, bool) {
+	var hook models.InboundHook
+
+	hookID, err := strconv.ParseUint(c.Param("hookId"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid inbound hook id")
+		return hook, false
+	}
+
+	if err := h.db.Where("id = ? AND goal_id = ?", uint(hookID), goalID).First(&hook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, 40401, "Inbound hook not found")
+			return hook, false
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return hook, false
+	}
+
+	return hook, true
+}
+
+func withInboundHookURL(hook models.InboundHook, token string) inboundHookWithURL {
+	return inboundHookWithURL{
+		InboundHook: hook,
+		Token:       token,
+		Path:        services.InboundHookPathPrefix + token,
+	}
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/markdown"
+	"willpower-forge-api/internal/models"
+)
+
+type IntentionHandler struct {
+	db *gorm.DB
+}
+
+type CreateIntentionRequest struct {
+	Cue    string `json:"cue" binding:"required,min=1,max=500"`
+	Action string `json:"action" binding:"required,min=1,max=500"`
+}
+
+type UpdateIntentionRequest struct {
+	Cue      string `json:"cue" binding:"omitempty,min=1,max=500"`
+	Action   string `json:"action" binding:"omitempty,min=1,max=500"`
+	Position *int   `json:"position" binding:"omitempty,min=0"`
+}
+
+func NewIntentionHandler(db *gorm.DB) *IntentionHandler {
+	return &IntentionHandler{db: db}
+}
+
+func (h *IntentionHandler) ListIntentions(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	var intentions []models.ImplementationIntention
+	if err := h.db.Where("goal_id = ?", goal.ID).Order("position ASC, id ASC").Find(&intentions).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", intentions)
+}
+
+func (h *IntentionHandler) CreateIntention(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	var req CreateIntentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	cue := sanitizeIntentionText(req.Cue)
+	action := sanitizeIntentionText(req.Action)
+	if cue == "" || action == "" {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	var count int64
+	if err := h.db.Model(&models.ImplementationIntention{}).Where("goal_id = ?", goal.ID).Count(&count).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	intention := models.ImplementationIntention{
+		GoalID:   goal.ID,
+		UserID:   goal.UserID,
+		Cue:      cue,
+		Action:   action,
+		Position: int(count),
+	}
+
+	if err := h.db.Create(&intention).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Intention created", intention)
+}
+
+func (h *IntentionHandler) UpdateIntention(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	intention, ok := h.findIntention(c, goal.ID)
+	if !ok {
+		return
+	}
+
+	var req UpdateIntentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.Cue != "" {
+		updates["cue"] = sanitizeIntentionText(req.Cue)
+	}
+	if req.Action != "" {
+		updates["action"] = sanitizeIntentionText(req.Action)
+	}
+	if req.Position != nil {
+		updates["position"] = *req.Position
+	}
+	for _, value := range updates {
+		if value == "" {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+			return
+		}
+	}
+
+	if len(updates) == 0 {
+		respondSuccess(c, http.StatusOK, "No updates provided", intention)
+		return
+	}
+
+	if err := h.db.Model(&intention).Updates(updates).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	if err := h.db.First(&intention, intention.ID).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Intention updated", intention)
+}
+
+func (h *IntentionHandler) DeleteIntention(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	intention, ok := h.findIntention(c, goal.ID)
+	if !ok {
+		return
+	}
+
+	// Check-ins keep their history but no longer point at the removed plan.
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.CheckIn{}).Where("intention_id = ?", intention.ID).
+			Update("intention_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&intention).Error
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Intention deleted", nil)
+}
+
+func (h *IntentionHandler) findIntention(c *gin.Context, goalID uint) (models.ImplementationIntention, bool) {
+	var intention models.ImplementationIntention
+
+	intentionID, err := strconv.ParseUint(c.Param("intentionId"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid intention id")
+		return intention, false
+	}
+
+	if err := h.db.Where("id = ? AND goal_id = ?", uint(intentionID), goalID).First(&intention).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, 40401, "Intention not found")
+			return intention, false
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return intention, false
+	}
+
+	return intention, true
+}
+
+func sanitizeIntentionText(value string) string {
+	return strings.Join(strings.Fields(markdown.Sanitize(value)), " ")
+}
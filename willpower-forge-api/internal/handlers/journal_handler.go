@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/markdown"
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+type JournalHandler struct {
+	db             *gorm.DB
+	journalService *services.JournalService
+}
+
+type JournalAnswerRequest struct {
+	PromptKey string `json:"prompt_key" binding:"required"`
+	Answer    string `json:"answer" binding:"max=5000"`
+}
+
+type CreateJournalEntryRequest struct {
+	Date    string                 `json:"date"`
+	Body    string                 `json:"body" binding:"max=20000"`
+	Answers []JournalAnswerRequest `json:"answers" binding:"dive"`
+}
+
+type UpdateJournalEntryRequest struct {
+	Body    *string                `json:"body" binding:"omitempty,max=20000"`
+	Answers []JournalAnswerRequest `json:"answers" binding:"omitempty,dive"`
+}
+
+func NewJournalHandler(db *gorm.DB, journalService *services.JournalService) *JournalHandler {
+	return &JournalHandler{db: db, journalService: journalService}
+}
+
+func (h *JournalHandler) ListEntries(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.journalService.ListEntries(userID, from, to, strings.TrimSpace(c.Query("q")))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", entries)
+}
+
+// ExportEntries downloads the journal, optionally limited to a date range,
+// as Markdown or, with format=json, as JSON.
+func (h *JournalHandler) ExportEntries(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "markdown")
+	if format != "markdown" && format != "json" {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid export format")
+		return
+	}
+
+	entries, err := h.journalService.ListEntries(userID, from, to, "")
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	if format == "json" {
+		c.Header("Content-Disposition", `attachment; filename="journal.json"`)
+		c.JSON(http.StatusOK, entries)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="journal.md"`)
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(services.ExportMarkdown(entries)))
+}
+
+func (h *JournalHandler) ListPrompts(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	date := c.Query("date")
+	if date == "" {
+		date = services.TodayIn(user.Timezone)
+	}
+	if !isValidDate(date) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid date")
+		return
+	}
+
+	prompts, err := h.journalService.Prompts(user.ID, date, requestLocale(c))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", prompts)
+}
+
+func (h *JournalHandler) GetEntry(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	date := c.Param("date")
+	if !isValidDate(date) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid date")
+		return
+	}
+
+	entry, err := h.journalService.GetEntry(userID, date)
+	if err != nil {
+		respondJournalError(c, err)
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", entry)
+}
+
+func (h *JournalHandler) CreateEntry(c *gin.Context) {
+	var req CreateJournalEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	today := services.TodayIn(user.Timezone)
+	date := req.Date
+	if date == "" {
+		date = today
+	}
+	if !isValidDate(date) || date > today {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid date")
+		return
+	}
+
+	entry, err := h.journalService.CreateEntry(user.ID, date, markdown.Sanitize(req.Body),
+		journalAnswerInputs(req.Answers), requestLocale(c))
+	if err != nil {
+		respondJournalError(c, err)
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Journal entry created", entry)
+}
+
+func (h *JournalHandler) UpdateEntry(c *gin.Context) {
+	var req UpdateJournalEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	date := c.Param("date")
+	if !isValidDate(date) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid date")
+		return
+	}
+
+	var body *string
+	if req.Body != nil {
+		sanitized := markdown.Sanitize(*req.Body)
+		body = &sanitized
+	}
+
+	entry, err := h.journalService.UpdateEntry(userID, date, body, journalAnswerInputs(req.Answers), requestLocale(c))
+	if err != nil {
+		respondJournalError(c, err)
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Journal entry updated", entry)
+}
+
+func (h *JournalHandler) DeleteEntry(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	date := c.Param("date")
+	if !isValidDate(date) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid date")
+		return
+	}
+
+	if err := h.journalService.DeleteEntry(userID, date); err != nil {
+		respondJournalError(c, err)
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Journal entry deleted", nil)
+}
+
+// loadUser loads the authenticated user, whose timezone decides which day
+// "today" is.
+func (h *JournalHandler) loadUser(c *gin.Context) (models.User, bool) {
+	var user models.User
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return user, false
+	}
+
+	if err := h.db.First(&user, userID).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return user, false
+	}
+
+	return user, true
+}
+
+// journalAnswerInputs converts request answers, keeping nil as nil so an
+// update without answers leaves them untouched.
+func journalAnswerInputs(answers []JournalAnswerRequest) []services.JournalAnswerInput {
+	if answers == nil {
+		return nil
+	}
+
+	inputs := make([]services.JournalAnswerInput, 0, len(answers))
+	for _, answer := range answers {
+		inputs = append(inputs, services.JournalAnswerInput{
+			PromptKey: answer.PromptKey,
+			Answer:    markdown.Sanitize(strings.TrimSpace(answer.Answer)),
+		})
+	}
+	return inputs
+}
+
+func respondJournalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrJournalEntryNotFound):
+		respondError(c, http.StatusNotFound, 40401, "Journal entry not found")
+	case errors.Is(err, services.ErrJournalEntryExists):
+		respondError(c, http.StatusConflict, 40901, "A journal entry already exists for this date")
+	case errors.Is(err, services.ErrUnknownPrompt):
+		respondError(c, http.StatusBadRequest, 40001, "Answers must refer to known prompts, once each")
+	default:
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+	}
+}
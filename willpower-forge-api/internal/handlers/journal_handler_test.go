@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+func TestJournalEntriesAreSanitized(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db)
+	handler := NewJournalHandler(db, services.NewJournalService(db))
+	router := newTestRouter(user.ID)
+	router.POST("/journal", handler.CreateEntry)
+	router.PUT("/journal/:date", handler.UpdateEntry)
+
+	payloads := []string{
+		"<scr<b>ipt>alert(1)</scr<b>ipt>",
+		"<img/src=x onerror=alert(1)>",
+		"<svg/onload=alert(1)>",
+		"[x](javascript:alert(1))",
+	}
+	body := strings.Join(payloads, "\n\n")
+
+	var entry models.JournalEntry
+	code := doJSON(t, router, http.MethodPost, "/journal", map[string]interface{}{
+		"body":    body,
+		"answers": []map[string]string{{"prompt_key": "highlight", "answer": body}},
+	}, &entry)
+	if code != http.StatusCreated {
+		t.Fatalf("create entry: status %d", code)
+	}
+
+	var stored models.JournalEntry
+	if err := db.Preload("Answers").First(&stored, entry.ID).Error; err != nil {
+		t.Fatalf("load entry: %v", err)
+	}
+	texts := []string{stored.Body}
+	for _, answer := range stored.Answers {
+		texts = append(texts, answer.Answer)
+	}
+
+	code = doJSON(t, router, http.MethodPut, "/journal/"+stored.Date, map[string]interface{}{"body": body}, &entry)
+	if code != http.StatusOK {
+		t.Fatalf("update entry: status %d", code)
+	}
+	texts = append(texts, entry.Body)
+
+	if len(texts) != 3 {
+		t.Fatalf("got %d texts, want body, answer and updated body", len(texts))
+	}
+	for _, text := range texts {
+		if strings.ContainsAny(text, "<>") || strings.Contains(text, "javascript:") {
+			t.Errorf("stored text is not sanitized: %q", text)
+		}
+	}
+}
@@ -0,0 +1,547 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+var errOrderMismatch = errors.New("order does not list every entry exactly once")
+
+type MilestoneHandler struct {
+	db *gorm.DB
+}
+
+type CreateMilestoneRequest struct {
+	Title      string `json:"title" binding:"required,min=1,max=255"`
+	TargetDate string `json:"target_date"`
+}
+
+type UpdateMilestoneRequest struct {
+	Title      string  `json:"title" binding:"omitempty,min=1,max=255"`
+	TargetDate *string `json:"target_date"`
+	Completed  *bool   `json:"completed"`
+}
+
+type CreateChecklistItemRequest struct {
+	Title       string `json:"title" binding:"required,min=1,max=255"`
+	MilestoneID *uint  `json:"milestone_id"`
+}
+
+type UpdateChecklistItemRequest struct {
+	Title       string `json:"title" binding:"omitempty,min=1,max=255"`
+	MilestoneID *uint  `json:"milestone_id"`
+	Done        *bool  `json:"done"`
+}
+
+// ReorderRequest lists every milestone or checklist item of a goal by id in
+// the new order.
+type ReorderRequest struct {
+	IDs []uint `json:"ids" binding:"required"`
+}
+
+func NewMilestoneHandler(db *gorm.DB) *MilestoneHandler {
+	return &MilestoneHandler{db: db}
+}
+
+func (h *MilestoneHandler) ListMilestones(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	milestones, err := loadMilestones(h.db, goal.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", milestones)
+}
+
+func (h *MilestoneHandler) CreateMilestone(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	var req CreateMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	if req.TargetDate != "" && !isValidDate(req.TargetDate) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid target date")
+		return
+	}
+
+	var count int64
+	if err := h.db.Model(&models.Milestone{}).Where("goal_id = ?", goal.ID).Count(&count).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	milestone := models.Milestone{
+		GoalID:     goal.ID,
+		UserID:     goal.UserID,
+		Title:      sanitizeIntentionText(req.Title),
+		TargetDate: req.TargetDate,
+		Position:   int(count),
+	}
+	if milestone.Title == "" {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	if err := h.db.Create(&milestone).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Milestone created", milestone)
+}
+
+func (h *MilestoneHandler) UpdateMilestone(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	milestone, ok := h.findMilestone(c, goal.ID, c.Param("milestoneId"))
+	if !ok {
+		return
+	}
+
+	var req UpdateMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.Title != "" {
+		title := sanitizeIntentionText(req.Title)
+		if title == "" {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+			return
+		}
+		updates["title"] = title
+	}
+	if req.TargetDate != nil {
+		if *req.TargetDate != "" && !isValidDate(*req.TargetDate) {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid target date")
+			return
+		}
+		updates["target_date"] = *req.TargetDate
+	}
+	if req.Completed != nil && *req.Completed != (milestone.CompletedAt != nil) {
+		if *req.Completed {
+			updates["completed_at"] = time.Now()
+		} else {
+			updates["completed_at"] = nil
+		}
+	}
+
+	if len(updates) == 0 {
+		respondSuccess(c, http.StatusOK, "No updates provided", milestone)
+		return
+	}
+
+	if err := h.db.Model(&milestone).Updates(updates).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	if err := h.db.Preload("Items", orderByPosition).First(&milestone, milestone.ID).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Milestone updated", milestone)
+}
+
+// DeleteMilestone removes a milestone together with the checklist items
+// grouped under it.
+func (h *MilestoneHandler) DeleteMilestone(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	milestone, ok := h.findMilestone(c, goal.ID, c.Param("milestoneId"))
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("milestone_id = ?", milestone.ID).Delete(&models.ChecklistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&milestone).Error
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Milestone deleted", nil)
+}
+
+func (h *MilestoneHandler) ReorderMilestones(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	var req ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	if err := applyOrder(h.db, &models.Milestone{}, goal.ID, req.IDs); err != nil {
+		respondOrderError(c, err)
+		return
+	}
+
+	milestones, err := loadMilestones(h.db, goal.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Milestones reordered", milestones)
+}
+
+func (h *MilestoneHandler) ListChecklist(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	var items []models.ChecklistItem
+	if err := h.db.Where("goal_id = ?", goal.ID).Order("position ASC, id ASC").Find(&items).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", items)
+}
+
+func (h *MilestoneHandler) CreateChecklistItem(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	var req CreateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	if req.MilestoneID != nil {
+		if _, ok := h.findMilestone(c, goal.ID, strconv.FormatUint(uint64(*req.MilestoneID), 10)); !ok {
+			return
+		}
+	}
+
+	var count int64
+	if err := h.db.Model(&models.ChecklistItem{}).Where("goal_id = ?", goal.ID).Count(&count).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	item := models.ChecklistItem{
+		GoalID:      goal.ID,
+		UserID:      goal.UserID,
+		MilestoneID: req.MilestoneID,
+		Title:       sanitizeIntentionText(req.Title),
+		Position:    int(count),
+	}
+	if item.Title == "" {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	if err := h.db.Create(&item).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Checklist item created", item)
+}
+
+func (h *MilestoneHandler) UpdateChecklistItem(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	item, ok := h.findChecklistItem(c, goal.ID)
+	if !ok {
+		return
+	}
+
+	var req UpdateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.Title != "" {
+		title := sanitizeIntentionText(req.Title)
+		if title == "" {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+			return
+		}
+		updates["title"] = title
+	}
+	if req.MilestoneID != nil {
+		// Zero moves the item out of its milestone.
+		if *req.MilestoneID == 0 {
+			updates["milestone_id"] = nil
+		} else {
+			if _, ok := h.findMilestone(c, goal.ID, strconv.FormatUint(uint64(*req.MilestoneID), 10)); !ok {
+				return
+			}
+			updates["milestone_id"] = *req.MilestoneID
+		}
+	}
+	if req.Done != nil && *req.Done != (item.DoneAt != nil) {
+		if *req.Done {
+			updates["done_at"] = time.Now()
+		} else {
+			updates["done_at"] = nil
+		}
+	}
+
+	if len(updates) == 0 {
+		respondSuccess(c, http.StatusOK, "No updates provided", item)
+		return
+	}
+
+	if err := h.db.Model(&item).Updates(updates).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	if err := h.db.First(&item, item.ID).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Checklist item updated", item)
+}
+
+func (h *MilestoneHandler) DeleteChecklistItem(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	item, ok := h.findChecklistItem(c, goal.ID)
+	if !ok {
+		return
+	}
+
+	if err := h.db.Delete(&item).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Checklist item deleted", nil)
+}
+
+func (h *MilestoneHandler) ReorderChecklist(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	var req ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	if err := applyOrder(h.db, &models.ChecklistItem{}, goal.ID, req.IDs); err != nil {
+		respondOrderError(c, err)
+		return
+	}
+
+	var items []models.ChecklistItem
+	if err := h.db.Where("goal_id = ?", goal.ID).Order("position ASC, id ASC").Find(&items).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Checklist reordered", items)
+}
+
+func (h *MilestoneHandler) GoalProgress(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	progress, err := loadGoalProgress(h.db, goal.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", progress)
+}
+
+// GoalTimeline returns the goal's history: check-ins, pauses, completed
+// milestones and checklist items, newest first.
+func (h *MilestoneHandler) GoalTimeline(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	if err := h.db.Preload("Pauses").Preload("Outcome").First(&goal, goal.ID).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	var checkIns []models.CheckIn
+	if err := h.db.Where("goal_id = ?", goal.ID).Find(&checkIns).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+	var milestones []models.Milestone
+	if err := h.db.Where("goal_id = ?", goal.ID).Find(&milestones).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+	var items []models.ChecklistItem
+	if err := h.db.Where("goal_id = ?", goal.ID).Find(&items).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	loc, err := services.UserLocationOf(h.db, goal.UserID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", services.BuildTimeline(goal, checkIns, milestones, items, loc))
+}
+
+func (h *MilestoneHandler) findMilestone(c *gin.Context, goalID uint, idParam string) (models.Milestone, bool) {
+	var milestone models.Milestone
+
+	milestoneID, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid milestone id")
+		return milestone, false
+	}
+
+	if err := h.db.Where("id = ? AND goal_id = ?", uint(milestoneID), goalID).First(&milestone).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, 40401, "Milestone not found")
+			return milestone, false
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return milestone, false
+	}
+
+	return milestone, true
+}
+
+func (h *MilestoneHandler) findChecklistItem(c *gin.Context, goalID uint) (models.ChecklistItem, bool) {
+	var item models.ChecklistItem
+
+	itemID, err := strconv.ParseUint(c.Param("itemId"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid checklist item id")
+		return item, false
+	}
+
+	if err := h.db.Where("id = ? AND goal_id = ?", uint(itemID), goalID).First(&item).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, 40401, "Checklist item not found")
+			return item, false
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return item, false
+	}
+
+	return item, true
+}
+
+func loadMilestones(db *gorm.DB, goalID uint) ([]models.Milestone, error) {
+	var milestones []models.Milestone
+	if err := db.Preload("Items", orderByPosition).Where("goal_id = ?", goalID).
+		Order("position ASC, id ASC").Find(&milestones).Error; err != nil {
+		return nil, err
+	}
+	return milestones, nil
+}
+
+// loadGoalProgress computes the goal's progress from its milestones and
+// checklist.
+func loadGoalProgress(db *gorm.DB, goalID uint) (services.GoalProgress, error) {
+	var milestones []models.Milestone
+	if err := db.Where("goal_id = ?", goalID).Order("position ASC, id ASC").Find(&milestones).Error; err != nil {
+		return services.GoalProgress{}, err
+	}
+	var items []models.ChecklistItem
+	if err := db.Where("goal_id = ?", goalID).Find(&items).Error; err != nil {
+		return services.GoalProgress{}, err
+	}
+	return services.ComputeProgress(milestones, items), nil
+}
+
+// applyOrder sets the position of each of the goal's rows of model to its
+// index in ids, which must list every row exactly once.
+func applyOrder(db *gorm.DB, model interface{}, goalID uint, ids []uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var existing []uint
+		if err := tx.Model(model).Where("goal_id = ?", goalID).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		if len(existing) != len(ids) {
+			return errOrderMismatch
+		}
+
+		remaining := make(map[uint]bool, len(existing))
+		for _, id := range existing {
+			remaining[id] = true
+		}
+		for _, id := range ids {
+			if !remaining[id] {
+				return errOrderMismatch
+			}
+			delete(remaining, id)
+		}
+
+		for position, id := range ids {
+			if err := tx.Model(model).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func respondOrderError(c *gin.Context, err error) {
+	if errors.Is(err, errOrderMismatch) {
+		respondError(c, http.StatusBadRequest, 40001, "ids must list every entry exactly once")
+		return
+	}
+	respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+}
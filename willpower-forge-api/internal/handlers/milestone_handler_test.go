@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+func TestMilestonesAndChecklist(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db)
+	goal := models.Goal{UserID: user.ID, Type: "I_WILL", Title: "Write a book", Status: models.GoalStatusActive, StartDate: "2026-03-01"}
+	if err := db.Create(&goal).Error; err != nil {
+		t.Fatalf("create goal: %v", err)
+	}
+	handler := NewMilestoneHandler(db)
+	router := newTestRouter(user.ID)
+	router.GET("/goals/:id/milestones", handler.ListMilestones)
+	router.POST("/goals/:id/milestones", handler.CreateMilestone)
+	router.PUT("/goals/:id/milestones/order", handler.ReorderMilestones)
+	router.PUT("/goals/:id/milestones/:milestoneId", handler.UpdateMilestone)
+	router.DELETE("/goals/:id/milestones/:milestoneId", handler.DeleteMilestone)
+	router.GET("/goals/:id/checklist", handler.ListChecklist)
+	router.POST("/goals/:id/checklist", handler.CreateChecklistItem)
+	router.GET("/goals/:id/progress", handler.GoalProgress)
+	base := fmt.Sprintf("/goals/%d", goal.ID)
+
+	var outline, draft models.Milestone
+	for _, m := range []struct {
+		title string
+		out   *models.Milestone
+	}{{"Outline", &outline}, {"Draft", &draft}} {
+		if code := doJSON(t, router, http.MethodPost, base+"/milestones", map[string]string{"title": m.title}, m.out); code != http.StatusCreated {
+			t.Fatalf("create milestone %s: status %d", m.title, code)
+		}
+	}
+	var item models.ChecklistItem
+	for _, body := range []map[string]interface{}{
+		{"title": "Pick a title", "milestone_id": outline.ID},
+		{"title": "Find an editor"},
+	} {
+		if code := doJSON(t, router, http.MethodPost, base+"/checklist", body, &item); code != http.StatusCreated {
+			t.Fatalf("create checklist item %v: status %d", body["title"], code)
+		}
+	}
+
+	var updated models.Milestone
+	if code := doJSON(t, router, http.MethodPut, fmt.Sprintf("%s/milestones/%d", base, outline.ID), map[string]bool{"completed": true}, &updated); code != http.StatusOK {
+		t.Fatalf("complete milestone: status %d", code)
+	}
+	if updated.CompletedAt == nil {
+		t.Fatal("completed milestone has no completed_at")
+	}
+
+	var progress services.GoalProgress
+	if code := doJSON(t, router, http.MethodGet, base+"/progress", nil, &progress); code != http.StatusOK {
+		t.Fatalf("progress: status %d", code)
+	}
+	if progress.MilestonesCompleted != 1 || progress.MilestonesTotal != 2 || progress.ItemsTotal != 2 || progress.Percent != 25 {
+		t.Errorf("progress = %+v, want 1 of 2 milestones, 0 of 2 items, 25%%", progress)
+	}
+	if progress.NextMilestone == nil || progress.NextMilestone.ID != draft.ID {
+		t.Errorf("next milestone = %+v, want Draft", progress.NextMilestone)
+	}
+
+	if code := doJSON(t, router, http.MethodPut, base+"/milestones/order", map[string][]uint{"ids": {draft.ID}}, nil); code != http.StatusBadRequest {
+		t.Errorf("reorder listing one milestone: status %d, want 400", code)
+	}
+	var milestones []models.Milestone
+	if code := doJSON(t, router, http.MethodPut, base+"/milestones/order", map[string][]uint{"ids": {draft.ID, outline.ID}}, &milestones); code != http.StatusOK {
+		t.Fatalf("reorder: status %d", code)
+	}
+	if len(milestones) != 2 || milestones[0].ID != draft.ID || milestones[1].ID != outline.ID {
+		t.Errorf("milestones after reordering = %+v, want Draft then Outline", milestones)
+	}
+
+	if code := doJSON(t, router, http.MethodDelete, fmt.Sprintf("%s/milestones/%d", base, outline.ID), nil, nil); code != http.StatusOK {
+		t.Fatalf("delete milestone: status %d", code)
+	}
+	var items []models.ChecklistItem
+	doJSON(t, router, http.MethodGet, base+"/checklist", nil, &items)
+	if len(items) != 1 || items[0].Title != "Find an editor" {
+		t.Errorf("checklist after deleting the milestone = %+v, want only the ungrouped item", items)
+	}
+}
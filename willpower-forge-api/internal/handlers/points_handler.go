@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
+)
+
+type PointsHandler struct {
+	db            *gorm.DB
+	pointsService *services.PointsService
+}
+
+type SpendFreezeRequest struct {
+	GoalID uint   `json:"goal_id" binding:"required"`
+	Date   string `json:"date" binding:"required"`
+}
+
+func NewPointsHandler(db *gorm.DB, pointsService *services.PointsService) *PointsHandler {
+	return &PointsHandler{db: db, pointsService: pointsService}
+}
+
+func (h *PointsHandler) GetBalance(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	balance, err := h.pointsService.Balance(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", balance)
+}
+
+// ListHistory returns the points ledger, newest first, paged with limit and
+// offset.
+func (h *PointsHandler) ListHistory(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultHistoryLimit)))
+	if err != nil || limit < 1 || limit > maxHistoryLimit {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid limit")
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid offset")
+		return
+	}
+
+	entries, total, err := h.pointsService.History(userID, limit, offset)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", gin.H{
+		"entries": entries,
+		"total":   total,
+	})
+}
+
+// SpendFreeze protects a goal's streak on a missed day with one of the
+// user's streak freezes.
+func (h *PointsHandler) SpendFreeze(c *gin.Context) {
+	var req SpendFreezeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+	if !isValidDate(req.Date) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	var goal models.Goal
+	if err := h.db.Where("id = ? AND user_id = ?", req.GoalID, userID).First(&goal).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, 40401, "Goal not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	freeze, err := h.pointsService.SpendFreeze(goal, req.Date)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNoFreezes):
+			respondError(c, http.StatusConflict, 40902, "No streak freezes left")
+		case errors.Is(err, services.ErrAlreadyFrozen):
+			respondError(c, http.StatusConflict, 40901, "This day is already frozen")
+		case errors.Is(err, services.ErrFreezeNotAllowed):
+			respondError(c, http.StatusBadRequest, 40001, "Only a past, missed day the goal was due can be frozen")
+		default:
+			respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		}
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Streak freeze used", freeze)
+}
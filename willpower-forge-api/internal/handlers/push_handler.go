@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/notify"
+	"willpower-forge-api/internal/services"
+)
+
+type PushHandler struct {
+	pushService *services.PushService
+}
+
+// SubscribePushRequest is the browser's PushSubscription.toJSON() plus an
+// optional device name shown in the list of subscribed devices.
+type SubscribePushRequest struct {
+	Endpoint string `json:"endpoint" binding:"required,max=2048"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys" binding:"required"`
+	Device string `json:"device" binding:"max=255"`
+}
+
+func NewPushHandler(pushService *services.PushService) *PushHandler {
+	return &PushHandler{pushService: pushService}
+}
+
+// GetVAPIDPublicKey returns the applicationServerKey for
+// pushManager.subscribe.
+func (h *PushHandler) GetVAPIDPublicKey(c *gin.Context) {
+	respondSuccess(c, http.StatusOK, "Success", gin.H{"public_key": h.pushService.PublicKey()})
+}
+
+func (h *PushHandler) ListSubscriptions(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	subscriptions, err := h.pushService.List(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", subscriptions)
+}
+
+func (h *PushHandler) Subscribe(c *gin.Context) {
+	var req SubscribePushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	device := req.Device
+	if device == "" {
+		device = c.Request.UserAgent()
+		if len(device) > 255 {
+			device = device[:255]
+		}
+	}
+
+	subscription, err := h.pushService.Subscribe(userID, req.Endpoint, req.Keys.P256dh, req.Keys.Auth, device)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSubscription) {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid push subscription")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Push subscription saved", subscription)
+}
+
+func (h *PushHandler) Unsubscribe(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid subscription id")
+		return
+	}
+
+	if err := h.pushService.Unsubscribe(userID, uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, 40401, "Push subscription not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Push subscription removed", nil)
+}
+
+// SendTest pushes a test notification to all of the user's devices.
+func (h *PushHandler) SendTest(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	sent, err := h.pushService.SendToUser(ctx, userID, notify.Message{
+		Title: "Willpower Forge",
+		Body:  "Push notifications are working.",
+	}, "test")
+	if err != nil {
+		if errors.Is(err, services.ErrNoPushSubscriptions) {
+			respondError(c, http.StatusNotFound, 40401, "No push subscriptions")
+			return
+		}
+		respondError(c, http.StatusBadGateway, 50001, "Push delivery failed: "+err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Test notification sent", gin.H{"sent": sent})
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+type RelapseHandler struct {
+	db             *gorm.DB
+	relapseService *services.RelapseService
+}
+
+type CreateRelapseRequest struct {
+	OccurredAt *time.Time `json:"occurred_at"`
+	Quantity   int        `json:"quantity" binding:"omitempty,min=1,max=1000"`
+	Note       string     `json:"note" binding:"max=1000"`
+}
+
+func NewRelapseHandler(db *gorm.DB, relapseService *services.RelapseService) *RelapseHandler {
+	return &RelapseHandler{db: db, relapseService: relapseService}
+}
+
+func (h *RelapseHandler) CreateRelapse(c *gin.Context) {
+	var req CreateRelapseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	goal, ok := h.loadIWontGoal(c)
+	if !ok {
+		return
+	}
+
+	var occurredAt time.Time
+	if req.OccurredAt != nil {
+		if req.OccurredAt.After(time.Now()) {
+			respondError(c, http.StatusBadRequest, 40001, "occurred_at cannot be in the future")
+			return
+		}
+		occurredAt = *req.OccurredAt
+	}
+
+	relapse, err := h.relapseService.LogRelapse(goal, occurredAt, req.Quantity, strings.TrimSpace(req.Note))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Relapse logged", relapse)
+}
+
+func (h *RelapseHandler) ListRelapses(c *gin.Context) {
+	goal, ok := h.loadIWontGoal(c)
+	if !ok {
+		return
+	}
+
+	relapses, err := h.relapseService.ListRelapses(goal.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", relapses)
+}
+
+func (h *RelapseHandler) DeleteRelapse(c *gin.Context) {
+	relapseID, err := strconv.ParseUint(c.Param("relapseId"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid relapse id")
+		return
+	}
+
+	goal, ok := h.loadIWontGoal(c)
+	if !ok {
+		return
+	}
+
+	if err := h.relapseService.DeleteRelapse(goal.ID, uint(relapseID)); err != nil {
+		if errors.Is(err, services.ErrRelapseNotFound) {
+			respondError(c, http.StatusNotFound, 40401, "Relapse not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Relapse deleted", nil)
+}
+
+// loadIWontGoal loads the goal from the path and ensures it is an I_WONT
+// goal, the only type relapses apply to.
+func (h *RelapseHandler) loadIWontGoal(c *gin.Context) (models.Goal, bool) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return goal, false
+	}
+
+	if goal.Type != "I_WONT" {
+		respondError(c, http.StatusBadRequest, 40001, "Relapses can only be logged for I_WONT goals")
+		return goal, false
+	}
+
+	return goal, true
+}
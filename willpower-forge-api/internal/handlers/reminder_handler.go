@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/notify"
+	"willpower-forge-api/internal/services"
+)
+
+type ReminderHandler struct {
+	db              *gorm.DB
+	reminderService *services.ReminderService
+}
+
+type CreateReminderRequest struct {
+	Times              string `json:"times" binding:"required"`
+	Days               string `json:"days"`
+	OnlyIfNotCheckedIn bool   `json:"only_if_not_checked_in"`
+	Channel            string `json:"channel" binding:"required,oneof=email webhook ntfy gotify webpush"`
+	Target             string `json:"target" binding:"max=2048"`
+	Enabled            *bool  `json:"enabled"`
+}
+
+type UpdateReminderRequest struct {
+	Times              *string `json:"times"`
+	Days               *string `json:"days"`
+	OnlyIfNotCheckedIn *bool   `json:"only_if_not_checked_in"`
+	Channel            *string `json:"channel" binding:"omitempty,oneof=email webhook ntfy gotify webpush"`
+	Target             *string `json:"target" binding:"omitempty,max=2048"`
+	Enabled            *bool   `json:"enabled"`
+}
+
+func NewReminderHandler(db *gorm.DB, reminderService *services.ReminderService) *ReminderHandler {
+	return &ReminderHandler{db: db, reminderService: reminderService}
+}
+
+func (h *ReminderHandler) ListReminders(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	var reminders []models.Reminder
+	if err := h.db.Where("goal_id = ?", goal.ID).Order("id ASC").Find(&reminders).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", reminders)
+}
+
+func (h *ReminderHandler) CreateReminder(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	var req CreateReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	reminder := models.Reminder{
+		GoalID:             goal.ID,
+		UserID:             goal.UserID,
+		OnlyIfNotCheckedIn: req.OnlyIfNotCheckedIn,
+		Channel:            req.Channel,
+		Target:             strings.TrimSpace(req.Target),
+		Enabled:            req.Enabled == nil || *req.Enabled,
+	}
+	if !h.applySchedule(c, &reminder, req.Times, req.Days) || !h.validateChannel(c, reminder) {
+		return
+	}
+
+	if err := h.db.Create(&reminder).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Reminder created", reminder)
+}
+
+func (h *ReminderHandler) UpdateReminder(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	reminder, ok := h.findReminder(c, goal.ID)
+	if !ok {
+		return
+	}
+
+	var req UpdateReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	times, days := reminder.Times, reminder.Days
+	if req.Times != nil {
+		times = *req.Times
+	}
+	if req.Days != nil {
+		days = *req.Days
+	}
+	if !h.applySchedule(c, &reminder, times, days) {
+		return
+	}
+	if req.Channel != nil {
+		reminder.Channel = *req.Channel
+	}
+	if req.Target != nil {
+		reminder.Target = strings.TrimSpace(*req.Target)
+	} else if reminder.Channel == notify.ChannelWebPush {
+		// Web Push has no target; drop the previous channel's address.
+		reminder.Target = ""
+	}
+	if (req.Channel != nil || req.Target != nil) && !h.validateChannel(c, reminder) {
+		return
+	}
+	if req.OnlyIfNotCheckedIn != nil {
+		reminder.OnlyIfNotCheckedIn = *req.OnlyIfNotCheckedIn
+	}
+	if req.Enabled != nil {
+		reminder.Enabled = *req.Enabled
+	}
+
+	if err := h.db.Model(&reminder).Updates(map[string]interface{}{
+		"times":                  reminder.Times,
+		"days":                   reminder.Days,
+		"only_if_not_checked_in": reminder.OnlyIfNotCheckedIn,
+		"channel":                reminder.Channel,
+		"target":                 reminder.Target,
+		"enabled":                reminder.Enabled,
+	}).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Reminder updated", reminder)
+}
+
+func (h *ReminderHandler) DeleteReminder(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	reminder, ok := h.findReminder(c, goal.ID)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("reminder_id = ?", reminder.ID).Delete(&models.ReminderDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&reminder).Error
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Reminder deleted", nil)
+}
+
+// TestReminder sends the reminder right away so the user can check the
+// channel works. Delivery failures are reported in the returned delivery.
+func (h *ReminderHandler) TestReminder(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	reminder, ok := h.findReminder(c, goal.ID)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.reminderService.SendTest(reminder)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	message := "Test reminder sent"
+	if delivery.Status == models.DeliveryStatusFailed {
+		message = "Test reminder failed"
+	}
+	respondSuccess(c, http.StatusOK, message, delivery)
+}
+
+// ListDeliveries returns the user's reminder delivery attempts, newest first,
+// paged with limit and offset and optionally filtered by goal_id.
+func (h *ReminderHandler) ListDeliveries(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultHistoryLimit)))
+	if err != nil || limit < 1 || limit > maxHistoryLimit {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid limit")
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid offset")
+		return
+	}
+	var goalID uint64
+	if raw := c.Query("goal_id"); raw != "" {
+		if goalID, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid goal id")
+			return
+		}
+	}
+
+	deliveries, total, err := h.reminderService.Deliveries(userID, uint(goalID), limit, offset)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", gin.H{
+		"deliveries": deliveries,
+		"total":      total,
+	})
+}
+
+func (h *ReminderHandler) findReminder(c *gin.Context, goalID uint) (models.Reminder, bool) {
+	var reminder models.Reminder
+
+	reminderID, err := strconv.ParseUint(c.Param("reminderId"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid reminder id")
+		return reminder, false
+	}
+
+	if err := h.db.Where("id = ? AND goal_id = ?", uint(reminderID), goalID).First(&reminder).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, 40401, "Reminder not found")
+			return reminder, false
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return reminder, false
+	}
+
+	return reminder, true
+}
+
+// applySchedule validates and normalizes the reminder's times and days.
+func (h *ReminderHandler) applySchedule(c *gin.Context, reminder *models.Reminder, times, days string) bool {
+	normalizedTimes, err := services.NormalizeReminderTimes(times)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid times, expected HH:MM separated by commas")
+		return false
+	}
+	normalizedDays, ok := models.NormalizeSchedule(days)
+	if !ok {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid days")
+		return false
+	}
+	reminder.Times = normalizedTimes
+	reminder.Days = normalizedDays
+	return true
+}
+
+func (h *ReminderHandler) validateChannel(c *gin.Context, reminder models.Reminder) bool {
+	switch err := h.reminderService.ValidateChannel(reminder.Channel, reminder.Target); {
+	case err == nil:
+		return true
+	case errors.Is(err, services.ErrChannelUnavailable):
+		respondError(c, http.StatusBadRequest, 40001, "Channel is not configured on this server")
+	case errors.Is(err, services.ErrInvalidTarget):
+		respondError(c, http.StatusBadRequest, 40001, "Invalid target for this channel")
+	default:
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+type ReportHandler struct {
+	db            *gorm.DB
+	reportService *services.ReportService
+}
+
+type SaveReportRequest struct {
+	Date string `json:"date"`
+}
+
+func NewReportHandler(db *gorm.DB, reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{db: db, reportService: reportService}
+}
+
+// GetReport generates the review of the week or month containing date,
+// which defaults to today in the user's timezone. format selects json,
+// markdown or html.
+func (h *ReportHandler) GetReport(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	format, ok := parseReportFormat(c)
+	if !ok {
+		return
+	}
+
+	date := c.Query("date")
+	if date == "" {
+		date = services.TodayIn(user.Timezone)
+	} else if !isValidDate(date) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+
+	report, err := h.reportService.Generate(user.ID, c.Param("period"), date)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	respondReport(c, format, report)
+}
+
+// SaveReport generates and stores the review of the period containing the
+// given date, replacing an earlier version of it.
+func (h *ReportHandler) SaveReport(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	var req SaveReportRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid input: "+err.Error())
+			return
+		}
+	}
+
+	if req.Date == "" {
+		req.Date = services.TodayIn(user.Timezone)
+	} else if !isValidDate(req.Date) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+
+	saved, report, err := h.reportService.Save(user.ID, c.Param("period"), req.Date)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Report saved", gin.H{
+		"id":     saved.ID,
+		"report": report,
+	})
+}
+
+func (h *ReportHandler) ListSavedReports(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	reports, err := h.reportService.ListSaved(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", reports)
+}
+
+func (h *ReportHandler) GetSavedReport(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	reportID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid report id")
+		return
+	}
+
+	format, ok := parseReportFormat(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.GetSaved(userID, uint(reportID))
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	respondReport(c, format, report)
+}
+
+// GetYearReview renders the user's year in review as a printable HTML page,
+// or as JSON with format=json.
+func (h *ReportHandler) GetYearReview(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid year")
+		return
+	}
+
+	format := c.DefaultQuery("format", "html")
+	if format != "html" && format != "json" {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid report format")
+		return
+	}
+
+	review, err := h.reportService.YearReview(userID, year)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	if format == "json" {
+		respondSuccess(c, http.StatusOK, "Success", review)
+		return
+	}
+
+	page, err := services.RenderYearReviewHTML(review)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="year-in-review-%d.html"`, year))
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+}
+
+// loadUser loads the authenticated user, whose timezone decides which period
+// is the current one.
+func (h *ReportHandler) loadUser(c *gin.Context) (models.User, bool) {
+	var user models.User
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return user, false
+	}
+
+	if err := h.db.First(&user, userID).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return user, false
+	}
+
+	return user, true
+}
+
+func parseReportFormat(c *gin.Context) (string, bool) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "markdown" && format != "html" {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid report format")
+		return "", false
+	}
+	return format, true
+}
+
+func respondReport(c *gin.Context, format string, report services.ReportData) {
+	filename := fmt.Sprintf("%s-review-%s", report.Period, report.StartDate)
+
+	switch format {
+	case "markdown":
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.md"`, filename))
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(services.RenderReportMarkdown(report)))
+	case "html":
+		page, err := services.RenderReportHTML(report)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+	default:
+		respondSuccess(c, http.StatusOK, "Success", report)
+	}
+}
+
+func respondReportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidReportPeriod):
+		respondError(c, http.StatusBadRequest, 40001, "Invalid report period, expected week or month")
+	case errors.Is(err, services.ErrInvalidYear):
+		respondError(c, http.StatusBadRequest, 40001, "Invalid year")
+	case errors.Is(err, services.ErrReportNotFound):
+		respondError(c, http.StatusNotFound, 40401, "Report not found")
+	default:
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+	}
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+type RoutineHandler struct {
+	routineService     *services.RoutineService
+	achievementService *services.AchievementService
+}
+
+type CreateRoutineRequest struct {
+	Name      string `json:"name" binding:"required,min=1,max=100"`
+	TimeOfDay string `json:"time_of_day"`
+	GoalIDs   []uint `json:"goal_ids"`
+}
+
+type UpdateRoutineRequest struct {
+	Name      *string `json:"name" binding:"omitempty,min=1,max=100"`
+	TimeOfDay *string `json:"time_of_day"`
+	GoalIDs   []uint  `json:"goal_ids"`
+}
+
+type RoutineCheckInRequest struct {
+	Date        string          `json:"date"`
+	Status      string          `json:"status" binding:"required,oneof=completed failed partial"`
+	Statuses    map[uint]string `json:"statuses"`
+	ReviewNotes string          `json:"review_notes"`
+}
+
+// routineCheckInResponse is a routine check-in with the achievements it
+// unlocked.
+type routineCheckInResponse struct {
+	services.RoutineCheckInResult
+	UnlockedAchievements []services.AchievementView `json:"unlocked_achievements,omitempty"`
+}
+
+func NewRoutineHandler(routineService *services.RoutineService, achievementService *services.AchievementService) *RoutineHandler {
+	return &RoutineHandler{routineService: routineService, achievementService: achievementService}
+}
+
+func (h *RoutineHandler) ListRoutines(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	routines, err := h.routineService.ListRoutines(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", routines)
+}
+
+func (h *RoutineHandler) GetRoutine(c *gin.Context) {
+	routine, ok := h.loadRoutine(c)
+	if !ok {
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", routine)
+}
+
+func (h *RoutineHandler) CreateRoutine(c *gin.Context) {
+	var req CreateRoutineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	if !isValidTimeOfDay(req.TimeOfDay) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid time of day")
+		return
+	}
+
+	routine, err := h.routineService.CreateRoutine(userID, strings.TrimSpace(req.Name), req.TimeOfDay, req.GoalIDs)
+	if err != nil {
+		respondRoutineError(c, err)
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Routine created", routine)
+}
+
+func (h *RoutineHandler) UpdateRoutine(c *gin.Context) {
+	var req UpdateRoutineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	routineID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid routine id")
+		return
+	}
+
+	if req.TimeOfDay != nil && !isValidTimeOfDay(*req.TimeOfDay) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid time of day")
+		return
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		req.Name = &name
+	}
+
+	routine, err := h.routineService.UpdateRoutine(userID, uint(routineID), req.Name, req.TimeOfDay, req.GoalIDs)
+	if err != nil {
+		respondRoutineError(c, err)
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Routine updated", routine)
+}
+
+func (h *RoutineHandler) DeleteRoutine(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	routineID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid routine id")
+		return
+	}
+
+	if err := h.routineService.DeleteRoutine(userID, uint(routineID)); err != nil {
+		respondRoutineError(c, err)
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Routine deleted", nil)
+}
+
+func (h *RoutineHandler) RemoveStep(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	routineID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid routine id")
+		return
+	}
+	goalID, err := strconv.ParseUint(c.Param("goalId"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid goal id")
+		return
+	}
+
+	routine, err := h.routineService.RemoveStep(userID, uint(routineID), uint(goalID))
+	if err != nil {
+		if errors.Is(err, services.ErrRoutineGoalInvalid) {
+			respondError(c, http.StatusNotFound, 40401, "Goal is not part of this routine")
+			return
+		}
+		respondRoutineError(c, err)
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Goal removed from routine", routine)
+}
+
+func (h *RoutineHandler) CheckInRoutine(c *gin.Context) {
+	var req RoutineCheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	for _, status := range req.Statuses {
+		if status != "completed" && status != "failed" && status != "partial" {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid status")
+			return
+		}
+	}
+	if req.Date != "" && !isValidDate(req.Date) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid date")
+		return
+	}
+
+	routine, ok := h.loadRoutine(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.routineService.CheckInRoutine(routine, services.RoutineCheckInInput{
+		Date:        req.Date,
+		Status:      req.Status,
+		Statuses:    req.Statuses,
+		ReviewNotes: req.ReviewNotes,
+	})
+	if err != nil {
+		respondRoutineError(c, err)
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Routine checked in", routineCheckInResponse{
+		RoutineCheckInResult: result,
+		UnlockedAchievements: unlockAchievements(c, h.achievementService, routine.UserID),
+	})
+}
+
+func (h *RoutineHandler) RoutineStats(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	routine, ok := h.loadRoutine(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.routineService.Stats(routine, from, to)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", stats)
+}
+
+// loadRoutine loads the routine named by the id path parameter, responding
+// with an error and returning false if it does not belong to the user.
+func (h *RoutineHandler) loadRoutine(c *gin.Context) (models.Routine, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return models.Routine{}, false
+	}
+
+	routineID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid routine id")
+		return models.Routine{}, false
+	}
+
+	routine, err := h.routineService.GetRoutine(userID, uint(routineID))
+	if err != nil {
+		respondRoutineError(c, err)
+		return models.Routine{}, false
+	}
+
+	return routine, true
+}
+
+// isValidTimeOfDay accepts an empty value or a 24-hour "HH:MM" time.
+func isValidTimeOfDay(value string) bool {
+	if value == "" {
+		return true
+	}
+	_, err := time.Parse("15:04", value)
+	return err == nil
+}
+
+func respondRoutineError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRoutineNotFound):
+		respondError(c, http.StatusNotFound, 40401, "Routine not found")
+	case errors.Is(err, services.ErrRoutineGoalInvalid):
+		respondError(c, http.StatusBadRequest, 40001, "Invalid goal ids")
+	case errors.Is(err, services.ErrRoutineEmpty):
+		respondError(c, http.StatusConflict, 40902, "No goal in this routine can be checked in on this date")
+	case errors.Is(err, services.ErrCheckInDateInFuture):
+		respondError(c, http.StatusBadRequest, 40001, "Invalid date")
+	default:
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/services"
+)
+
+type StrengthHandler struct {
+	db              *gorm.DB
+	strengthService *services.HabitStrengthService
+}
+
+func NewStrengthHandler(db *gorm.DB, strengthService *services.HabitStrengthService) *StrengthHandler {
+	return &StrengthHandler{db: db, strengthService: strengthService}
+}
+
+func (h *StrengthHandler) GoalStrength(c *gin.Context) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	strength, err := h.strengthService.GoalStrength(goal)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", strength)
+}
+
+func (h *StrengthHandler) Dashboard(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	dashboard, err := h.strengthService.Dashboard(userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", dashboard)
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+var (
+	errTagExists  = errors.New("tag already exists")
+	errTagInvalid = errors.New("tag does not belong to user")
+
+	tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+type TagHandler struct {
+	db *gorm.DB
+}
+
+type CreateTagRequest struct {
+	Name  string `json:"name" binding:"required,min=1,max=50"`
+	Color string `json:"color"`
+}
+
+type UpdateTagRequest struct {
+	Name  string  `json:"name" binding:"omitempty,min=1,max=50"`
+	Color *string `json:"color"`
+}
+
+type SetGoalTagsRequest struct {
+	TagIDs []uint `json:"tag_ids" binding:"required"`
+}
+
+func NewTagHandler(db *gorm.DB) *TagHandler {
+	return &TagHandler{db: db}
+}
+
+func (h *TagHandler) ListTags(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	var tags []models.Tag
+	if err := h.db.Where("user_id = ?", userID).Order("name ASC").Find(&tags).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", tags)
+}
+
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	var req CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || (req.Color != "" && !tagColorPattern.MatchString(req.Color)) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	tag := models.Tag{
+		UserID: userID,
+		Name:   name,
+		Color:  req.Color,
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureTagNameFree(tx, userID, name, 0); err != nil {
+			return err
+		}
+		return tx.Create(&tag).Error
+	})
+	if err != nil {
+		if errors.Is(err, errTagExists) {
+			respondError(c, http.StatusConflict, 40901, "Tag already exists")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Tag created", tag)
+}
+
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	var req UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	tag, ok := h.findTag(c)
+	if !ok {
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != "" {
+		name := strings.TrimSpace(req.Name)
+		if name == "" {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+			return
+		}
+		updates["name"] = name
+	}
+	if req.Color != nil {
+		if *req.Color != "" && !tagColorPattern.MatchString(*req.Color) {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+			return
+		}
+		updates["color"] = *req.Color
+	}
+
+	if len(updates) == 0 {
+		respondSuccess(c, http.StatusOK, "No updates provided", tag)
+		return
+	}
+
+	// The rename and the touch of every tagged goal happen together so
+	// clients syncing on goal updated_at never see a half-applied rename.
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if name, ok := updates["name"].(string); ok {
+			if err := ensureTagNameFree(tx, tag.UserID, name, tag.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&tag).Updates(updates).Error; err != nil {
+			return err
+		}
+		return touchTaggedGoals(tx, tag.ID)
+	})
+	if err != nil {
+		if errors.Is(err, errTagExists) {
+			respondError(c, http.StatusConflict, 40901, "Tag already exists")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	if err := h.db.First(&tag, tag.ID).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Tag updated", tag)
+}
+
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	tag, ok := h.findTag(c)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := touchTaggedGoals(tx, tag.ID); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM goal_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&tag).Error
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Tag deleted", nil)
+}
+
+func (h *TagHandler) SetGoalTags(c *gin.Context) {
+	var req SetGoalTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		tags, err := findUserTags(tx, goal.UserID, req.TagIDs)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&goal).Association("Tags").Replace(tags)
+	})
+	if err != nil {
+		if errors.Is(err, errTagInvalid) {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid tag ids")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Goal tags updated", goal)
+}
+
+func (h *TagHandler) findTag(c *gin.Context) (models.Tag, bool) {
+	var tag models.Tag
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return tag, false
+	}
+
+	tagID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid tag id")
+		return tag, false
+	}
+
+	if err := h.db.Where("id = ? AND user_id = ?", uint(tagID), userID).First(&tag).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, 40401, "Tag not found")
+			return tag, false
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return tag, false
+	}
+
+	return tag, true
+}
+
+// findUserTags loads the given tags, failing with errTagInvalid if any of
+// them does not exist or belongs to another user.
+func findUserTags(db *gorm.DB, userID uint, tagIDs []uint) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	if len(tagIDs) == 0 {
+		return tags, nil
+	}
+
+	unique := make(map[uint]bool, len(tagIDs))
+	for _, id := range tagIDs {
+		unique[id] = true
+	}
+
+	if err := db.Where("user_id = ? AND id IN ?", userID, tagIDs).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	if len(tags) != len(unique) {
+		return nil, errTagInvalid
+	}
+	return tags, nil
+}
+
+func ensureTagNameFree(tx *gorm.DB, userID uint, name string, exceptID uint) error {
+	var count int64
+	if err := tx.Model(&models.Tag{}).
+		Where("user_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", userID, name, exceptID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errTagExists
+	}
+	return nil
+}
+
+func touchTaggedGoals(tx *gorm.DB, tagID uint) error {
+	return tx.Model(&models.Goal{}).
+		Where("id IN (SELECT goal_id FROM goal_tags WHERE tag_id = ?)", tagID).
+		Update("updated_at", time.Now()).Error
+}
+
+// parseTagFilter reads the optional comma-separated tag query parameter.
+func parseTagFilter(c *gin.Context) ([]uint, bool) {
+	raw := c.Query("tag")
+	if raw == "" {
+		return nil, true
+	}
+
+	var tagIDs []uint
+	for _, part := range strings.Split(raw, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		tagIDs = append(tagIDs, uint(id))
+	}
+	return tagIDs, true
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+// tagFixture is a goal tagged "Health" and "Work" behind a router serving
+// the tag endpoints.
+type tagFixture struct {
+	db     *gorm.DB
+	router *gin.Engine
+	goal   models.Goal
+	health models.Tag
+}
+
+// taggedGoalUpdatedAt is the updated_at the fixture's goal starts with.
+var taggedGoalUpdatedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newTagFixture(t *testing.T) tagFixture {
+	t.Helper()
+	db := testdb.Open(t)
+	user := createTestUser(t, db)
+	health := models.Tag{UserID: user.ID, Name: "Health"}
+	work := models.Tag{UserID: user.ID, Name: "Work"}
+	goal := models.Goal{UserID: user.ID, Type: "I_WILL", Title: "Run", Status: models.GoalStatusActive,
+		StartDate: "2026-03-01", Tags: []models.Tag{health, work}}
+	if err := db.Create(&goal).Error; err != nil {
+		t.Fatalf("create goal: %v", err)
+	}
+	db.Model(&goal).UpdateColumn("updated_at", taggedGoalUpdatedAt)
+
+	handler := NewTagHandler(db)
+	router := newTestRouter(user.ID)
+	router.PUT("/tags/:id", handler.UpdateTag)
+	router.DELETE("/tags/:id", handler.DeleteTag)
+	return tagFixture{db: db, router: router, goal: goal, health: goal.Tags[0]}
+}
+
+func (f tagFixture) goalTags(t *testing.T) []string {
+	t.Helper()
+	var goal models.Goal
+	if err := f.db.Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("name ASC") }).First(&goal, f.goal.ID).Error; err != nil {
+		t.Fatalf("load goal: %v", err)
+	}
+	names := make([]string, 0, len(goal.Tags))
+	for _, tag := range goal.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func (f tagFixture) goalTouched(t *testing.T) bool {
+	t.Helper()
+	var goal models.Goal
+	if err := f.db.First(&goal, f.goal.ID).Error; err != nil {
+		t.Fatalf("load goal: %v", err)
+	}
+	return goal.UpdatedAt.After(taggedGoalUpdatedAt)
+}
+
+// failOn makes every statement of kind on table abort, standing in for a
+// failure halfway through a transaction.
+func (f tagFixture) failOn(t *testing.T, kind, table string) {
+	t.Helper()
+	if err := f.db.Exec(fmt.Sprintf("CREATE TRIGGER fail_%s BEFORE %s ON %s BEGIN SELECT RAISE(ABORT, 'injected'); END",
+		table, kind, table)).Error; err != nil {
+		t.Fatalf("create trigger: %v", err)
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	f := newTagFixture(t)
+	path := fmt.Sprintf("/tags/%d", f.health.ID)
+
+	if code := doJSON(t, f.router, http.MethodPut, path, map[string]string{"name": "work"}, nil); code != http.StatusConflict {
+		t.Errorf("rename to a taken name: status %d, want 409", code)
+	}
+	if f.goalTouched(t) {
+		t.Error("a rejected rename touched the tagged goal")
+	}
+
+	if code := doJSON(t, f.router, http.MethodPut, path, map[string]string{"name": "Fitness"}, nil); code != http.StatusOK {
+		t.Fatalf("rename: status %d", code)
+	}
+	if got := f.goalTags(t); len(got) != 2 || got[0] != "Fitness" || got[1] != "Work" {
+		t.Errorf("goal tags = %v, want [Fitness Work]", got)
+	}
+	if !f.goalTouched(t) {
+		t.Error("rename did not touch the tagged goal")
+	}
+}
+
+func TestRenameTagRollsBack(t *testing.T) {
+	f := newTagFixture(t)
+	f.failOn(t, "UPDATE", "goals")
+
+	if code := doJSON(t, f.router, http.MethodPut, fmt.Sprintf("/tags/%d", f.health.ID), map[string]string{"name": "Fitness"}, nil); code != http.StatusInternalServerError {
+		t.Fatalf("rename: status %d, want 500", code)
+	}
+	if got := f.goalTags(t); len(got) != 2 || got[0] != "Health" {
+		t.Errorf("goal tags = %v, want the rename rolled back", got)
+	}
+}
+
+func TestDeleteTag(t *testing.T) {
+	f := newTagFixture(t)
+
+	if code := doJSON(t, f.router, http.MethodDelete, fmt.Sprintf("/tags/%d", f.health.ID), nil, nil); code != http.StatusOK {
+		t.Fatalf("delete: status %d", code)
+	}
+	if got := f.goalTags(t); len(got) != 1 || got[0] != "Work" {
+		t.Errorf("goal tags = %v, want [Work]", got)
+	}
+	if !f.goalTouched(t) {
+		t.Error("delete did not touch the tagged goal")
+	}
+	var links int64
+	f.db.Table("goal_tags").Where("tag_id = ?", f.health.ID).Count(&links)
+	if links != 0 {
+		t.Errorf("%d goal links of the deleted tag are left", links)
+	}
+}
+
+func TestDeleteTagRollsBack(t *testing.T) {
+	f := newTagFixture(t)
+	f.failOn(t, "DELETE", "tags")
+
+	if code := doJSON(t, f.router, http.MethodDelete, fmt.Sprintf("/tags/%d", f.health.ID), nil, nil); code != http.StatusInternalServerError {
+		t.Fatalf("delete: status %d, want 500", code)
+	}
+	if got := f.goalTags(t); len(got) != 2 {
+		t.Errorf("goal tags = %v, want both kept", got)
+	}
+	if f.goalTouched(t) {
+		t.Error("a failed delete touched the tagged goal")
+	}
+}
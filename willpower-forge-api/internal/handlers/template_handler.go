@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/services"
+)
+
+type TemplateHandler struct {
+	db              *gorm.DB
+	templateService *services.TemplateService
+}
+
+type SaveTemplateRequest struct {
+	Name string `json:"name" binding:"max=255"`
+}
+
+type CreateGoalFromTemplateRequest struct {
+	Title     string `json:"title" binding:"omitempty,min=1,max=255"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+func NewTemplateHandler(db *gorm.DB, templateService *services.TemplateService) *TemplateHandler {
+	return &TemplateHandler{db: db, templateService: templateService}
+}
+
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	templates, err := h.templateService.ListTemplates(userID, requestLocale(c))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", templates)
+}
+
+func (h *TemplateHandler) SaveGoalAsTemplate(c *gin.Context) {
+	var req SaveTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return
+	}
+
+	template, err := h.templateService.SaveGoalAsTemplate(goal, req.Name)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Template saved", template)
+}
+
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Built-in templates cannot be deleted")
+		return
+	}
+
+	if err := h.templateService.DeleteTemplate(userID, uint(templateID)); err != nil {
+		if errors.Is(err, services.ErrTemplateNotFound) {
+			respondError(c, http.StatusNotFound, 40401, "Template not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Template deleted", nil)
+}
+
+func (h *TemplateHandler) CreateGoalFromTemplate(c *gin.Context) {
+	var req CreateGoalFromTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	template, err := h.templateService.FindTemplate(userID, c.Param("id"), requestLocale(c))
+	if err != nil {
+		if errors.Is(err, services.ErrTemplateNotFound) {
+			respondError(c, http.StatusNotFound, 40401, "Template not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	startDate := req.StartDate
+	if startDate == "" {
+		if startDate, err = services.UserToday(h.db, userID); err != nil {
+			respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+			return
+		}
+	}
+	if !isValidDate(startDate) || (req.EndDate != "" && (!isValidDate(req.EndDate) || req.EndDate < startDate)) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid start or end date")
+		return
+	}
+
+	goal, err := h.templateService.InstantiateTemplate(userID, template, req.Title, startDate, req.EndDate)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Goal created", goal)
+}
+
+// requestLocale picks the response language from the lang query parameter,
+// falling back to the Accept-Language header.
+func requestLocale(c *gin.Context) string {
+	if lang := c.Query("lang"); lang != "" {
+		return services.NormalizeLocale(lang)
+	}
+	return services.NormalizeLocale(c.GetHeader("Accept-Language"))
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+func TestCreateGoalFromTemplateStartsOnUserToday(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db)
+	zone := zoneOffServerDate(t)
+	db.Model(&user).Update("timezone", zone)
+	handler := NewTemplateHandler(db, services.NewTemplateService(db))
+	router := newTestRouter(user.ID)
+	router.POST("/goals/from-template/:id", handler.CreateGoalFromTemplate)
+
+	today := services.TodayIn(zone)
+	tomorrow := time.Now().In(services.UserLocation(zone)).AddDate(0, 0, 1).Format("2006-01-02")
+	tests := []struct {
+		name   string
+		body   map[string]interface{}
+		start  string
+		status string
+	}{
+		{"default start", nil, today, models.GoalStatusActive},
+		{"user's today", map[string]interface{}{"start_date": today}, today, models.GoalStatusActive},
+		{"user's tomorrow", map[string]interface{}{"start_date": tomorrow}, tomorrow, models.GoalStatusScheduled},
+	}
+	for _, tt := range tests {
+		var goal models.Goal
+		if code := doJSON(t, router, http.MethodPost, "/goals/from-template/daily-meditation", tt.body, &goal); code != http.StatusCreated {
+			t.Fatalf("%s: status %d", tt.name, code)
+		}
+		if goal.StartDate != tt.start || goal.Status != tt.status {
+			t.Errorf("%s: start %s, status %s, want %s, %s", tt.name, goal.StartDate, goal.Status, tt.start, tt.status)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/services"
+)
+
+type TemptationHandler struct {
+	db                 *gorm.DB
+	temptationService  *services.TemptationService
+	achievementService *services.AchievementService
+}
+
+type CreateTemptationRequest struct {
+	OccurredAt *time.Time `json:"occurred_at"`
+	Intensity  int        `json:"intensity" binding:"required,min=1,max=10"`
+	Trigger    string     `json:"trigger" binding:"required"`
+	Resisted   *bool      `json:"resisted" binding:"required"`
+	Note       string     `json:"note" binding:"max=1000"`
+}
+
+type DeriveCheckInRequest struct {
+	Date        string `json:"date"`
+	ReviewNotes string `json:"review_notes"`
+}
+
+func NewTemptationHandler(db *gorm.DB, temptationService *services.TemptationService, achievementService *services.AchievementService) *TemptationHandler {
+	return &TemptationHandler{db: db, temptationService: temptationService, achievementService: achievementService}
+}
+
+func (h *TemptationHandler) CreateTemptation(c *gin.Context) {
+	var req CreateTemptationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	if !isTemptationTrigger(req.Trigger) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid trigger")
+		return
+	}
+
+	goal, ok := h.loadIWontGoal(c)
+	if !ok {
+		return
+	}
+
+	input := services.TemptationInput{
+		Intensity: req.Intensity,
+		Trigger:   req.Trigger,
+		Resisted:  *req.Resisted,
+		Note:      strings.TrimSpace(req.Note),
+	}
+	if req.OccurredAt != nil {
+		if req.OccurredAt.After(time.Now()) {
+			respondError(c, http.StatusBadRequest, 40001, "occurred_at cannot be in the future")
+			return
+		}
+		input.OccurredAt = *req.OccurredAt
+	}
+
+	event, err := h.temptationService.LogTemptation(goal, input)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Temptation logged", event)
+}
+
+func (h *TemptationHandler) ListTemptations(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	goal, ok := h.loadIWontGoal(c)
+	if !ok {
+		return
+	}
+
+	events, err := h.temptationService.ListTemptations(goal.ID, from, to)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", events)
+}
+
+func (h *TemptationHandler) TemptationAnalytics(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	goal, ok := h.loadIWontGoal(c)
+	if !ok {
+		return
+	}
+
+	analytics, err := h.temptationService.Analyze(goal, from, to)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", analytics)
+}
+
+func (h *TemptationHandler) DeriveCheckIn(c *gin.Context) {
+	var req DeriveCheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	if req.Date != "" && !isValidDate(req.Date) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid date")
+		return
+	}
+
+	goal, ok := h.loadIWontGoal(c)
+	if !ok {
+		return
+	}
+
+	checkIn, err := h.temptationService.DeriveCheckIn(goal, req.Date, req.ReviewNotes)
+	if errors.Is(err, services.ErrNoTemptationEvents) {
+		respondError(c, http.StatusBadRequest, 40001, "No temptation events logged for this day")
+		return
+	}
+	if err != nil {
+		respondCheckInError(c, goal, err)
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Check-in recorded", checkInResponse{
+		CheckIn:              checkIn,
+		UnlockedAchievements: unlockAchievements(c, h.achievementService, goal.UserID),
+	})
+}
+
+// loadIWontGoal loads the goal from the path and ensures it is an I_WONT
+// goal, the only type temptation events apply to.
+func (h *TemptationHandler) loadIWontGoal(c *gin.Context) (models.Goal, bool) {
+	goal, ok := loadUserGoal(c, h.db)
+	if !ok {
+		return goal, false
+	}
+
+	if goal.Type != "I_WONT" {
+		respondError(c, http.StatusBadRequest, 40001, "Temptations can only be logged for I_WONT goals")
+		return goal, false
+	}
+
+	return goal, true
+}
+
+func isTemptationTrigger(trigger string) bool {
+	for _, candidate := range models.TemptationTriggers {
+		if candidate == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDateRange reads the optional from and to query parameters,
+// responding with an error and returning false if either is invalid.
+func parseDateRange(c *gin.Context) (string, string, bool) {
+	from := c.Query("from")
+	to := c.Query("to")
+
+	if (from != "" && !isValidDate(from)) || (to != "" && !isValidDate(to)) || (from != "" && to != "" && to < from) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid date range")
+		return "", "", false
+	}
+
+	return from, to, true
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+type VacationHandler struct {
+	db *gorm.DB
+}
+
+type CreateVacationRequest struct {
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+	Reason    string `json:"reason" binding:"max=500"`
+}
+
+func NewVacationHandler(db *gorm.DB) *VacationHandler {
+	return &VacationHandler{db: db}
+}
+
+func (h *VacationHandler) ListVacations(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	var vacations []models.VacationPeriod
+	if err := h.db.Where("user_id = ?", userID).Order("start_date DESC").Find(&vacations).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", vacations)
+}
+
+func (h *VacationHandler) CreateVacation(c *gin.Context) {
+	var req CreateVacationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	if !isValidDate(req.StartDate) || !isValidDate(req.EndDate) || req.EndDate < req.StartDate {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid start or end date")
+		return
+	}
+
+	var overlapping int64
+	if err := h.db.Model(&models.VacationPeriod{}).
+		Where("user_id = ? AND start_date <= ? AND end_date >= ?", userID, req.EndDate, req.StartDate).
+		Count(&overlapping).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+	if overlapping > 0 {
+		respondError(c, http.StatusConflict, 40901, "Vacation overlaps an existing one")
+		return
+	}
+
+	vacation := models.VacationPeriod{
+		UserID:    userID,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Reason:    strings.TrimSpace(req.Reason),
+	}
+
+	if err := h.db.Create(&vacation).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Vacation created", vacation)
+}
+
+func (h *VacationHandler) DeleteVacation(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	vacationID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid vacation id")
+		return
+	}
+
+	result := h.db.Where("id = ? AND user_id = ?", uint(vacationID), userID).Delete(&models.VacationPeriod{})
+	if result.Error != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, 40401, "Vacation not found")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Vacation deleted", nil)
+}
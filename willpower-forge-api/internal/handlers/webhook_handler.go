@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/notify"
+	"willpower-forge-api/internal/services"
+)
+
+type WebhookHandler struct {
+	db             *gorm.DB
+	webhookService *services.WebhookService
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,max=2048"`
+	Events []string `json:"events" binding:"required,min=1"`
+}
+
+type UpdateWebhookRequest struct {
+	URL     *string  `json:"url" binding:"omitempty,max=2048"`
+	Events  []string `json:"events"`
+	Enabled *bool    `json:"enabled"`
+}
+
+// webhookWithSecret is returned when a secret is created or rotated, the
+// only times it is shown.
+type webhookWithSecret struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+func NewWebhookHandler(db *gorm.DB, webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{db: db, webhookService: webhookService}
+}
+
+// ListEventTypes returns the event types webhooks can subscribe to.
+func (h *WebhookHandler) ListEventTypes(c *gin.Context) {
+	respondSuccess(c, http.StatusOK, "Success", models.WebhookEvents)
+}
+
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	var webhooks []models.Webhook
+	if err := h.db.Where("user_id = ?", userID).Order("id ASC").Find(&webhooks).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", webhooks)
+}
+
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return
+	}
+
+	url := strings.TrimSpace(req.URL)
+	if !notify.ValidURL(url) {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid URL, expected http or https")
+		return
+	}
+	events, ok := normalizeWebhookEvents(req.Events)
+	if !ok {
+		respondError(c, http.StatusBadRequest, 40001, "Unknown event type")
+		return
+	}
+
+	secret, err := services.NewWebhookSecret()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	webhook := models.Webhook{
+		UserID:  userID,
+		URL:     url,
+		Events:  events,
+		Secret:  secret,
+		Enabled: true,
+	}
+	if err := h.db.Create(&webhook).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, "Webhook created", webhookWithSecret{Webhook: webhook, Secret: secret})
+}
+
+// UpdateWebhook changes a webhook's URL, events or enabled state.
+// Re-enabling a webhook clears its failure count.
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid input")
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.URL != nil {
+		url := strings.TrimSpace(*req.URL)
+		if !notify.ValidURL(url) {
+			respondError(c, http.StatusBadRequest, 40001, "Invalid URL, expected http or https")
+			return
+		}
+		updates["url"] = url
+	}
+	if req.Events != nil {
+		events, ok := normalizeWebhookEvents(req.Events)
+		if !ok {
+			respondError(c, http.StatusBadRequest, 40001, "Unknown event type")
+			return
+		}
+		updates["events"] = events
+	}
+	if req.Enabled != nil && *req.Enabled != webhook.Enabled {
+		updates["enabled"] = *req.Enabled
+		if *req.Enabled {
+			updates["failures"] = 0
+			updates["disabled_reason"] = ""
+		}
+	}
+
+	if len(updates) == 0 {
+		respondSuccess(c, http.StatusOK, "No updates provided", webhook)
+		return
+	}
+
+	if err := h.db.Model(&webhook).Updates(updates).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+	if err := h.db.First(&webhook, webhook.ID).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Webhook updated", webhook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&webhook).Error
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Webhook deleted", nil)
+}
+
+// RotateSecret replaces the webhook's signing secret and returns the new
+// one. Requests are signed with the new secret from then on.
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	secret, err := services.NewWebhookSecret()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+	if err := h.db.Model(&webhook).Update("secret", secret).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Webhook secret rotated", webhookWithSecret{Webhook: webhook, Secret: secret})
+}
+
+// SendTestEvent sends a ping event right away and returns its delivery.
+func (h *WebhookHandler) SendTestEvent(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.SendTest(webhook)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	message := "Test event delivered"
+	if delivery.Status != models.WebhookDeliverySucceeded {
+		message = "Test event failed"
+	}
+	respondSuccess(c, http.StatusOK, message, delivery)
+}
+
+// ListDeliveries returns the webhook's delivery log, newest first, paged
+// with limit and offset.
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultHistoryLimit)))
+	if err != nil || limit < 1 || limit > maxHistoryLimit {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid limit")
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid offset")
+		return
+	}
+
+	deliveries, total, err := h.webhookService.Deliveries(webhook.ID, limit, offset)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, "Success", gin.H{
+		"deliveries": deliveries,
+		"total":      total,
+	})
+}
+
+func (h *WebhookHandler) loadWebhook(c *gin.Context) (models.Webhook, bool) {
+	var webhook models.Webhook
+
+	userID, ok := getUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, 40102, "Unauthorized")
+		return webhook, false
+	}
+
+	webhookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, 40001, "Invalid webhook id")
+		return webhook, false
+	}
+
+	if err := h.db.Where("id = ? AND user_id = ?", uint(webhookID), userID).First(&webhook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, 40401, "Webhook not found")
+			return webhook, false
+		}
+		respondError(c, http.StatusInternalServerError, 50001, "Internal server error")
+		return webhook, false
+	}
+
+	return webhook, true
+}
+
+// normalizeWebhookEvents validates event types and returns them comma
+// separated in the order of models.WebhookEvents.
+func normalizeWebhookEvents(events []string) (string, bool) {
+	requested := make(map[string]bool, len(events))
+	for _, event := range events {
+		requested[strings.TrimSpace(event)] = true
+	}
+
+	var known []string
+	for _, event := range models.WebhookEvents {
+		if requested[event] {
+			known = append(known, event)
+			delete(requested, event)
+		}
+	}
+	if len(requested) > 0 || len(known) == 0 {
+		return "", false
+	}
+	return strings.Join(known, ","), true
+}
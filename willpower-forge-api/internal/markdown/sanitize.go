@@ -0,0 +1,155 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MaxLength caps the number of characters kept from user-supplied markdown.
+// It counts the input, so escaped HTML may make the stored text longer.
+const MaxLength = 10000
+
+var (
+	htmlCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// Reference definitions: [label]: url
+	referencePattern = regexp.MustCompile(`(?m)^(\s{0,3}\[[^\]]+\]:\s*)(\S+)`)
+	autolinkPattern  = regexp.MustCompile(`<([a-zA-Z][a-zA-Z0-9+.-]*:[^<>\s]*)>`)
+	// Blockquote markers at the start of a line, which must stay unescaped.
+	blockquotePattern = regexp.MustCompile(`^(?: {0,3}> ?)+`)
+)
+
+// Sanitize prepares user-supplied markdown for storage. Control characters
+// are dropped and the text is cut to MaxLength characters, then raw HTML is
+// escaped so it renders as text and links are restricted to safe schemes.
+// Cutting first never splits an escape such as "&lt;".
+func Sanitize(input string) string {
+	text := strings.ReplaceAll(input, "\r\n", "\n")
+	text = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			return r
+		}
+		return -1
+	}, text)
+	text = strings.TrimSpace(text)
+	if runes := []rune(text); len(runes) > MaxLength {
+		text = string(runes[:MaxLength])
+	}
+
+	text = htmlCommentPattern.ReplaceAllString(text, "")
+	text = sanitizeInlineLinks(text)
+	text = referencePattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := referencePattern.FindStringSubmatch(match)
+		if IsSafeURL(parts[2]) {
+			return match
+		}
+		return parts[1] + "#"
+	})
+	text = escapeHTML(text)
+	return strings.TrimSpace(text)
+}
+
+// sanitizeInlineLinks replaces unsafe destinations of inline links and
+// images, [text](url "title") or ![alt](url), with "#". Destinations may
+// contain balanced parentheses, as in Markdown itself.
+func sanitizeInlineLinks(text string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(text, "](")
+		if start < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		b.WriteString(text[:start+2])
+		text = text[start+2:]
+
+		end := closingParen(text)
+		if end < 0 {
+			end = len(text)
+		}
+		inner := text[:end]
+		leading := len(inner) - len(strings.TrimLeft(inner, " \t\n"))
+		destination := inner[leading:]
+		if space := strings.IndexAny(destination, " \t\n"); space >= 0 {
+			destination = destination[:space]
+		}
+		if IsSafeURL(destination) {
+			b.WriteString(inner)
+		} else {
+			b.WriteString("#" + inner[leading+len(destination):])
+		}
+		text = text[end:]
+	}
+}
+
+// closingParen returns the index of the ")" closing a link destination that
+// starts at text[0], or -1 if there is none.
+func closingParen(text string) int {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// escapeHTML escapes every "<" and ">" so no HTML survives, except
+// blockquote markers and autolinks to safe URLs. Unsafe autolinks are
+// dropped.
+func escapeHTML(text string) string {
+	escape := strings.NewReplacer("<", "&lt;", ">", "&gt;").Replace
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		prefix := blockquotePattern.FindString(line)
+		line = line[len(prefix):]
+
+		var b strings.Builder
+		b.WriteString(prefix)
+		last := 0
+		for _, match := range autolinkPattern.FindAllStringSubmatchIndex(line, -1) {
+			b.WriteString(escape(line[last:match[0]]))
+			if IsSafeURL(line[match[2]:match[3]]) {
+				b.WriteString(line[match[0]:match[1]])
+			}
+			last = match[1]
+		}
+		b.WriteString(escape(line[last:]))
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// IsSafeURL reports whether a link target uses a scheme that is safe to
+// render: http, https, mailto, or a relative or fragment reference. Entities
+// and whitespace are resolved first since browsers ignore them in schemes.
+func IsSafeURL(url string) bool {
+	trimmed := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, html.UnescapeString(strings.Trim(url, "<>")))
+	colon := strings.IndexByte(trimmed, ':')
+	if colon < 0 {
+		return true
+	}
+	if slash := strings.IndexAny(trimmed, "/?#"); slash >= 0 && slash < colon {
+		return true
+	}
+
+	switch strings.ToLower(trimmed[:colon]) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
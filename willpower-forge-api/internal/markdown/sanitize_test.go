@@ -0,0 +1,79 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain markdown", "**Bold** and _italic_\n\n- item", "**Bold** and _italic_\n\n- item"},
+		{"script", "<script>alert(1)</script>", "&lt;script&gt;alert(1)&lt;/script&gt;"},
+		{"nested tag splits script", "<scr<b>ipt>alert(1)</scr<b>ipt>", "&lt;scr&lt;b&gt;ipt&gt;alert(1)&lt;/scr&lt;b&gt;ipt&gt;"},
+		{"slash after img", "<img/src=x onerror=alert(1)>", "&lt;img/src=x onerror=alert(1)&gt;"},
+		{"slash after svg", "<svg/onload=alert(1)>", "&lt;svg/onload=alert(1)&gt;"},
+		{"unclosed tag", "<img src=x onerror=alert(1)//", "&lt;img src=x onerror=alert(1)//"},
+		{"comment", "a<!-- hidden -->b", "ab"},
+		{"javascript link with parens", "[x](javascript:alert(1))", "[x](#)"},
+		{"javascript link keeps title", `[x](javascript:alert(1) "t") after`, `[x](# "t") after`},
+		{"entity encoded scheme", "[x](javascript&#58;alert(1))", "[x](#)"},
+		{"angle bracket destination", "[x](<javascript:alert(1)>)", "[x](#)"},
+		{"javascript image", "![a](JaVaScRiPt:alert(1))", "![a](#)"},
+		{"safe link with parens", "[wiki](https://en.wikipedia.org/wiki/Go_(game))", "[wiki](https://en.wikipedia.org/wiki/Go_(game))"},
+		{"relative link", "[goal](/goals/3)", "[goal](/goals/3)"},
+		{"unsafe reference", "[x]: javascript:alert(1)", "[x]: #"},
+		{"safe autolink", "see <https://example.com>", "see <https://example.com>"},
+		{"unsafe autolink", "see <javascript:alert(1)>", "see"},
+		{"blockquote", "> quoted\n> > nested <b>", "> quoted\n> > nested &lt;b&gt;"},
+		{"control characters", "a\x00b\r\nc", "ab\nc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sanitize(tt.input)
+			if got != tt.want {
+				t.Errorf("Sanitize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if again := Sanitize(got); again != got {
+				t.Errorf("Sanitize is not idempotent: %q became %q", got, again)
+			}
+		})
+	}
+}
+
+func TestSanitizeTruncates(t *testing.T) {
+	got := Sanitize(strings.Repeat("é", MaxLength+10))
+	if n := len([]rune(got)); n != MaxLength {
+		t.Errorf("got %d characters, want %d", n, MaxLength)
+	}
+
+	// The cut comes before escaping, so it never splits an entity.
+	input := strings.Repeat("a", MaxLength-1) + "<script>"
+	if got, want := Sanitize(input), strings.Repeat("a", MaxLength-1)+"&lt;"; got != want {
+		t.Errorf("got ...%q, want ...%q", got[len(got)-8:], want[len(want)-8:])
+	}
+}
+
+func TestIsSafeURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://example.com":      true,
+		"mailto:me@example.com":    true,
+		"/goals/1":                 true,
+		"#top":                     true,
+		"javascript:alert(1)":      false,
+		" java\tscript:alert(1)":   false,
+		"data:text/html,<b>x</b>":  false,
+		"vbscript:msgbox":          false,
+		"./a:b":                    true,
+		"java&#x73;cript:alert(1)": false,
+	}
+	for url, want := range tests {
+		if got := IsSafeURL(url); got != want {
+			t.Errorf("IsSafeURL(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
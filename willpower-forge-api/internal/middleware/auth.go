@@ -9,7 +9,18 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// AuthMiddleware requires a bearer token in the Authorization header.
 func AuthMiddleware() gin.HandlerFunc {
+	return authMiddleware(false)
+}
+
+// EventStreamAuthMiddleware also accepts the token as the access_token
+// query parameter, since browsers cannot set headers on an EventSource.
+func EventStreamAuthMiddleware() gin.HandlerFunc {
+	return authMiddleware(true)
+}
+
+func authMiddleware(allowQueryToken bool) gin.HandlerFunc {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		secret = "dev-secret-key"
@@ -17,6 +28,9 @@ func AuthMiddleware() gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 		header := c.GetHeader("Authorization")
+		if header == "" && allowQueryToken && c.Query("access_token") != "" {
+			header = "Bearer " + c.Query("access_token")
+		}
 		if header == "" {
 			respondUnauthorized(c, "Missing Authorization header")
 			return
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inboundHookPrefix starts the paths of inbound hooks, whose last segment is
+// the hook's secret token.
+const inboundHookPrefix = "/api/v1/hooks/"
+
+// digestUnsubscribePath takes a digest subscription's unsubscribe token in
+// its query.
+const digestUnsubscribePath = "/api/v1/digest/unsubscribe"
+
+// Logger logs requests like gin's default logger, except that event
+// streams are not logged, as they are long-lived and may carry the access
+// token in the query, and inbound hook and unsubscribe tokens are redacted.
+func Logger() gin.HandlerFunc {
+	return gin.LoggerWithConfig(gin.LoggerConfig{
+		Formatter: logFormatter,
+		SkipPaths: []string{"/api/v1/events/stream"},
+	})
+}
+
+// logFormatter is gin's default log format with inbound hook and
+// unsubscribe tokens removed from the path.
+func logFormatter(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		redactPath(param.Path),
+		param.ErrorMessage,
+	)
+}
+
+// redactPath replaces the token of inbound hook paths and the query of
+// unsubscribe links.
+func redactPath(path string) string {
+	if strings.HasPrefix(path, inboundHookPrefix) {
+		return inboundHookPrefix + "[redacted]"
+	}
+	if strings.HasPrefix(path, digestUnsubscribePath+"?") {
+		return digestUnsubscribePath + "?[redacted]"
+	}
+	return path
+}
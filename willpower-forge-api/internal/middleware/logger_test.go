@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoggerRedactsInboundHookTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var out bytes.Buffer
+	router := gin.New()
+	router.Use(gin.LoggerWithConfig(gin.LoggerConfig{Formatter: logFormatter, Output: &out}))
+	router.POST("/api/v1/hooks/:token", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	router.GET("/api/v1/goals", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/api/v1/hooks/wfh_secret123", "/api/v1/hooks/wfh_secret123?x=1"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/goals?limit=5", nil))
+
+	logged := out.String()
+	if strings.Contains(logged, "wfh_secret123") {
+		t.Errorf("log contains the hook token:\n%s", logged)
+	}
+	if strings.Count(logged, "/api/v1/hooks/[redacted]") != 2 {
+		t.Errorf("log is missing the redacted hook paths:\n%s", logged)
+	}
+	if !strings.Contains(logged, "/api/v1/goals?limit=5") {
+		t.Errorf("log is missing other paths:\n%s", logged)
+	}
+}
+
+func TestLoggerRedactsUnsubscribeTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var out bytes.Buffer
+	router := gin.New()
+	router.Use(gin.LoggerWithConfig(gin.LoggerConfig{Formatter: logFormatter, Output: &out}))
+	router.GET("/api/v1/digest/unsubscribe", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/api/v1/digest/unsubscribe", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/digest/unsubscribe?token=abc123secret", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/digest/unsubscribe?List-Unsubscribe=One-Click&token=abc123secret", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/digest/unsubscribe", nil))
+
+	logged := out.String()
+	if strings.Contains(logged, "abc123secret") {
+		t.Errorf("log contains the unsubscribe token:\n%s", logged)
+	}
+	if strings.Count(logged, "/api/v1/digest/unsubscribe?[redacted]") != 2 {
+		t.Errorf("log is missing the redacted unsubscribe links:\n%s", logged)
+	}
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Achievement records a badge a user has unlocked. Key names a rule in the
+// achievement catalogue; each badge is unlocked at most once per user.
+type Achievement struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_achievement_user_key" json:"user_id"`
+	Key        string    `gorm:"not null;uniqueIndex:idx_achievement_user_key" json:"key"`
+	UnlockedAt time.Time `gorm:"not null" json:"unlocked_at"`
+}
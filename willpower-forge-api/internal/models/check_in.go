@@ -2,13 +2,53 @@ package models
 
 import "time"
 
+// Check-in ratings are optional and all use the same scale, from RatingMin
+// (very low) to RatingMax (very high).
+const (
+	RatingMin = 1
+	RatingMax = 5
+)
+
+// CheckInStatusMissed marks a due day that ended without a check-in. Missed
+// check-ins are only recorded by the missed-day job, never by users, and are
+// replaced by any check-in made for the day later on.
+const CheckInStatusMissed = "missed"
+
+// RatingDimensions lists the ratings a check-in can carry.
+var RatingDimensions = []string{"mood", "energy", "stress", "effort"}
+
+// CheckIn records how a goal went on one day. Amount is an optional
+// quantity for the day, such as minutes run or pages read.
 type CheckIn struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	GoalID      uint      `gorm:"not null;index" json:"goal_id"`
-	UserID      uint      `gorm:"not null" json:"user_id"`
-	Date        string    `gorm:"not null" json:"date"`
-	Status      string    `gorm:"not null" json:"status"`
-	ReviewNotes string    `json:"review_notes"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	GoalID       uint      `gorm:"not null;index" json:"goal_id"`
+	UserID       uint      `gorm:"not null" json:"user_id"`
+	Date         string    `gorm:"not null" json:"date"`
+	Status       string    `gorm:"not null" json:"status"`
+	ReviewNotes  string    `json:"review_notes"`
+	ExcuseReason string    `json:"excuse_reason,omitempty"`
+	Amount       *float64  `json:"amount,omitempty"`
+	IntentionID  *uint     `gorm:"index" json:"intention_id,omitempty"`
+	Mood         *int      `json:"mood,omitempty"`
+	Energy       *int      `json:"energy,omitempty"`
+	Stress       *int      `json:"stress,omitempty"`
+	Effort       *int      `json:"effort,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Rating returns the check-in's rating for one of RatingDimensions, or nil
+// if it was not given.
+func (c CheckIn) Rating(dimension string) *int {
+	switch dimension {
+	case "mood":
+		return c.Mood
+	case "energy":
+		return c.Energy
+	case "stress":
+		return c.Stress
+	case "effort":
+		return c.Effort
+	}
+	return nil
 }
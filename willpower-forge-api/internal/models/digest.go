@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Digest kinds.
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DigestSubscription is a user's opt-in to digest emails, sent to Email in
+// Locale. The daily digest lists the goals due that day and goes out at
+// SendTime, "HH:MM" in the user's timezone; the weekly summary of the past
+// week follows on Mondays at the same time. LastDailyDate and LastWeekStart
+// record the last digest of each kind so none is sent twice.
+// UnsubscribeToken goes into every email and turns digests off without
+// logging in, so it is stored as is.
+type DigestSubscription struct {
+	ID               uint             `gorm:"primaryKey" json:"id"`
+	UserID           uint             `gorm:"not null;uniqueIndex" json:"user_id"`
+	Email            string           `gorm:"not null" json:"email"`
+	Locale           string           `gorm:"not null;default:'en'" json:"locale"`
+	Daily            bool             `gorm:"not null" json:"daily"`
+	Weekly           bool             `gorm:"not null" json:"weekly"`
+	SendTime         string           `gorm:"not null;default:'07:00'" json:"send_time"`
+	UnsubscribeToken string           `gorm:"not null;uniqueIndex" json:"-"`
+	LastDailyDate    string           `gorm:"not null;default:''" json:"last_daily_date,omitempty"`
+	LastWeekStart    string           `gorm:"not null;default:''" json:"last_week_start,omitempty"`
+	Deliveries       []DigestDelivery `gorm:"foreignKey:SubscriptionID;constraint:OnDelete:CASCADE" json:"-"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+}
+
+// DigestDelivery records one attempt to send a digest. Date is the day of a
+// daily digest or the first day of a weekly one's week. Error holds the
+// failure reason when Status is "failed".
+type DigestDelivery struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint      `gorm:"not null;index" json:"subscription_id"`
+	UserID         uint      `gorm:"not null;index" json:"user_id"`
+	Kind           string    `gorm:"not null" json:"kind"`
+	Date           string    `gorm:"not null" json:"date"`
+	Email          string    `gorm:"not null" json:"email"`
+	Status         string    `gorm:"not null" json:"status"`
+	Error          string    `gorm:"type:text" json:"error,omitempty"`
+	Test           bool      `gorm:"not null" json:"test"`
+	CreatedAt      time.Time `json:"created_at"`
+}
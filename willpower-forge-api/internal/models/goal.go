@@ -1,17 +1,185 @@
 package models
 
 import (
+	"strings"
 	"time"
+
 	"gorm.io/gorm"
 )
 
+// Goal lifecycle statuses. A goal with a future start date waits in
+// "scheduled" until the lifecycle job activates it, and is moved to
+// "completed" once its end date has passed.
+const (
+	GoalStatusScheduled = "scheduled"
+	GoalStatusActive    = "active"
+	GoalStatusPaused    = "paused"
+	GoalStatusCompleted = "completed"
+	GoalStatusArchived  = "archived"
+)
+
+// Goal difficulties. Harder goals earn more XP per check-in.
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+// Goal is a single I_WILL, I_WONT or I_WANT commitment. For I_WONT goals,
+// BaselinePerDay and CostPerOccurrence optionally describe how often the
+// habit used to happen and what each occurrence costs in CostUnit, so that
+// money or time saved can be reported. Difficulty weights the XP a check-in
+// earns. MissedDays overrides the user's missed-day mode; empty follows it.
 type Goal struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	UserID    uint           `gorm:"not null" json:"user_id"`
-	Type      string         `gorm:"not null" json:"type"`
-	Title     string         `gorm:"not null" json:"title"`
-	Status    string         `gorm:"not null;default:'active'" json:"status"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	ID                uint                      `gorm:"primaryKey" json:"id"`
+	UserID            uint                      `gorm:"not null" json:"user_id"`
+	Type              string                    `gorm:"not null" json:"type"`
+	Title             string                    `gorm:"not null" json:"title"`
+	Description       string                    `gorm:"type:text" json:"description"`
+	Motivation        string                    `gorm:"type:text" json:"motivation"`
+	Status            string                    `gorm:"not null;default:'active'" json:"status"`
+	Schedule          string                    `gorm:"not null;default:''" json:"schedule"`
+	StartDate         string                    `gorm:"not null;default:''" json:"start_date"`
+	EndDate           string                    `gorm:"not null;default:''" json:"end_date,omitempty"`
+	BaselinePerDay    float64                   `gorm:"not null;default:0" json:"baseline_per_day"`
+	CostPerOccurrence float64                   `gorm:"not null;default:0" json:"cost_per_occurrence"`
+	CostUnit          string                    `json:"cost_unit"`
+	Difficulty        string                    `gorm:"not null;default:'medium'" json:"difficulty"`
+	MissedDays        string                    `gorm:"not null;default:''" json:"missed_days"`
+	Pauses            []GoalPause               `gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE" json:"pauses,omitempty"`
+	StreakFreezes     []StreakFreeze            `gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE" json:"streak_freezes,omitempty"`
+	Outcome           *GoalOutcome              `gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE" json:"outcome,omitempty"`
+	Intentions        []ImplementationIntention `gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE" json:"intentions,omitempty"`
+	Tags              []Tag                     `gorm:"many2many:goal_tags;constraint:OnDelete:CASCADE" json:"tags,omitempty"`
+	Temptations       []TemptationEvent         `gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE" json:"-"`
+	Relapses          []Relapse                 `gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE" json:"-"`
+	Milestones        []Milestone               `gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE" json:"milestones,omitempty"`
+	Checklist         []ChecklistItem           `gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE" json:"checklist,omitempty"`
+	Strength          *GoalStrength             `gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE" json:"-"`
+	RoutineSteps      []RoutineStep             `gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE" json:"-"`
+	Reminders         []Reminder                `gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE" json:"-"`
+	InboundHooks      []InboundHook             `gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE" json:"-"`
+	DeletedAt         gorm.DeletedAt            `gorm:"index" json:"deleted_at,omitempty"`
+	CreatedAt         time.Time                 `json:"created_at"`
+	UpdatedAt         time.Time                 `json:"updated_at"`
+}
+
+// EffectiveStartDate returns the first day the goal is tracked. Goals created
+// before start dates existed fall back to their creation day.
+func (g Goal) EffectiveStartDate() string {
+	if g.StartDate != "" {
+		return g.StartDate
+	}
+	return g.CreatedAt.Format("2006-01-02")
+}
+
+// RecordsMissedDays reports whether the goal's missed days are recorded,
+// given its user's missed-day mode.
+func (g Goal) RecordsMissedDays(userMode string) bool {
+	if g.MissedDays != "" {
+		return g.MissedDays == MissedDaysRecord
+	}
+	return userMode != MissedDaysIgnore
+}
+
+// weekdayKeys maps the schedule day names to time.Weekday values.
+var weekdayKeys = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// NormalizeSchedule validates a schedule such as "mon,wed,fri" and returns it
+// in canonical form. An empty schedule or "daily" means every day and is
+// normalized to the empty string.
+func NormalizeSchedule(schedule string) (string, bool) {
+	schedule = strings.ToLower(strings.TrimSpace(schedule))
+	if schedule == "" || schedule == "daily" {
+		return "", true
+	}
+
+	seen := make(map[time.Weekday]bool)
+	for _, part := range strings.Split(schedule, ",") {
+		day, ok := weekdayKeys[strings.TrimSpace(part)]
+		if !ok {
+			return "", false
+		}
+		seen[day] = true
+	}
+
+	if len(seen) == len(weekdayKeys) {
+		return "", true
+	}
+
+	days := make([]string, 0, len(seen))
+	for _, key := range []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"} {
+		if seen[weekdayKeys[key]] {
+			days = append(days, key)
+		}
+	}
+	return strings.Join(days, ","), true
+}
+
+// IsDueOn reports whether the goal's schedule includes the given YYYY-MM-DD
+// date. Goals without a schedule are due every day.
+func (g Goal) IsDueOn(date string) bool {
+	if g.Schedule == "" {
+		return true
+	}
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return false
+	}
+
+	for _, part := range strings.Split(g.Schedule, ",") {
+		if weekdayKeys[part] == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// GoalPause records a period during which a goal was paused. EndDate is empty
+// while the pause is still open. Paused days are neither successes nor
+// failures.
+type GoalPause struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	GoalID    uint      `gorm:"not null;index" json:"goal_id"`
+	UserID    uint      `gorm:"not null" json:"user_id"`
+	StartDate string    `gorm:"not null" json:"start_date"`
+	EndDate   string    `gorm:"not null;default:''" json:"end_date,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Covers reports whether the given YYYY-MM-DD date falls inside the pause.
+func (p GoalPause) Covers(date string) bool {
+	if date < p.StartDate {
+		return false
+	}
+	return p.EndDate == "" || date <= p.EndDate
+}
+
+// GoalOutcome is the final summary stored when a goal is completed.
+type GoalOutcome struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	GoalID         uint      `gorm:"not null;uniqueIndex" json:"goal_id"`
+	UserID         uint      `gorm:"not null" json:"user_id"`
+	StartDate      string    `json:"start_date"`
+	EndDate        string    `json:"end_date"`
+	TotalDays      int       `json:"total_days"`
+	PausedDays     int       `json:"paused_days"`
+	Completed      int64     `json:"completed"`
+	Partial        int64     `json:"partial"`
+	Failed         int64     `json:"failed"`
+	Excused        int       `json:"excused"`
+	Missed         int       `json:"missed"`
+	CompletionRate float64   `json:"completion_rate"`
+	LongestStreak  int       `json:"longest_streak"`
+	CreatedAt      time.Time `json:"created_at"`
 }
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// GoalAchievementStats caches a goal's share of the achievement metrics so
+// evaluating badges after a check-in only replays the goal that changed.
+// ComputedOn is the user's date the stats were computed on, since streaks
+// and weeks depend on it. Fingerprint covers the schedule, pauses,
+// vacations, timezone, check-ins and relapses. DueWeeks lists the Mondays of
+// weeks with a due day and MissedWeeks those with a due day not completed,
+// both comma separated.
+type GoalAchievementStats struct {
+	GoalID      uint      `gorm:"primaryKey" json:"goal_id"`
+	ComputedOn  string    `gorm:"not null" json:"computed_on"`
+	Fingerprint string    `gorm:"not null;default:''" json:"-"`
+	CheckInDays int       `gorm:"not null;default:0" json:"check_in_days"`
+	BestStreak  int       `gorm:"not null;default:0" json:"best_streak"`
+	Comeback    bool      `gorm:"not null;default:false" json:"comeback"`
+	DueWeeks    string    `gorm:"type:text;not null;default:''" json:"-"`
+	MissedWeeks string    `gorm:"type:text;not null;default:''" json:"-"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
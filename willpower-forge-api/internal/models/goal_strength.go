@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// GoalStrength caches a goal's habit strength score so it can be advanced
+// day by day instead of replaying the whole history. ComputedThrough is the
+// last date folded into Score. LastCheckInID and Fingerprint detect changes
+// that invalidate the cache: backdated check-ins and edits to the schedule,
+// pauses or vacations.
+type GoalStrength struct {
+	GoalID          uint      `gorm:"primaryKey" json:"goal_id"`
+	ComputedThrough string    `gorm:"not null" json:"computed_through"`
+	Score           float64   `gorm:"not null;default:0" json:"score"`
+	LastCheckInID   uint      `gorm:"not null;default:0" json:"-"`
+	Fingerprint     string    `gorm:"not null;default:''" json:"-"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
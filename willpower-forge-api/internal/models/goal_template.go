@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// TemplateIntention is an if-then plan suggested by a goal template.
+type TemplateIntention struct {
+	Cue    string `json:"cue"`
+	Action string `json:"action"`
+}
+
+// GoalTemplate is a user-defined template saved from one of their goals.
+// Built-in templates are defined in code and never stored.
+type GoalTemplate struct {
+	ID          uint                `gorm:"primaryKey" json:"id"`
+	UserID      uint                `gorm:"not null;index" json:"user_id"`
+	Name        string              `gorm:"not null" json:"name"`
+	Type        string              `gorm:"not null" json:"type"`
+	Title       string              `gorm:"not null" json:"title"`
+	Schedule    string              `gorm:"not null;default:''" json:"schedule"`
+	Description string              `gorm:"type:text" json:"description"`
+	Motivation  string              `gorm:"type:text" json:"motivation"`
+	Intentions  []TemplateIntention `gorm:"type:text;serializer:json" json:"intentions"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
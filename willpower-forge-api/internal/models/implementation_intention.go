@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ImplementationIntention is an if-then plan attached to a goal, e.g. "If I
+// feel like scrolling after 10pm, then I will read".
+type ImplementationIntention struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	GoalID    uint      `gorm:"not null;index" json:"goal_id"`
+	UserID    uint      `gorm:"not null" json:"user_id"`
+	Cue       string    `gorm:"not null" json:"cue"`
+	Action    string    `gorm:"not null" json:"action"`
+	Position  int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Inbound hook call outcomes.
+const (
+	InboundCallAccepted    = "accepted"
+	InboundCallRejected    = "rejected"
+	InboundCallRateLimited = "rate_limited"
+)
+
+// InboundHook lets external tools record check-ins on a goal without
+// logging in, by calling a URL that embeds a secret token. Only the token's
+// SHA-256 hash is stored, so the URL is shown once when the hook is created
+// or its token rotated; TokenHint is the token's last characters to tell
+// hooks apart. Deleting the hook revokes its URL.
+type InboundHook struct {
+	ID         uint              `gorm:"primaryKey" json:"id"`
+	GoalID     uint              `gorm:"not null;index" json:"goal_id"`
+	UserID     uint              `gorm:"not null;index" json:"user_id"`
+	Name       string            `gorm:"not null" json:"name"`
+	TokenHash  string            `gorm:"not null;uniqueIndex" json:"-"`
+	TokenHint  string            `gorm:"not null" json:"token_hint"`
+	LastUsedAt *time.Time        `json:"last_used_at"`
+	Calls      []InboundHookCall `gorm:"foreignKey:HookID;constraint:OnDelete:CASCADE" json:"-"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// InboundHookCall logs one call to an inbound hook URL, with the check-in it
+// created when it was accepted.
+type InboundHookCall struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	HookID     uint      `gorm:"not null;index" json:"hook_id"`
+	UserID     uint      `gorm:"not null" json:"user_id"`
+	Outcome    string    `gorm:"not null" json:"outcome"`
+	Error      string    `json:"error,omitempty"`
+	CheckInID  *uint     `json:"check_in_id,omitempty"`
+	RemoteAddr string    `json:"remote_addr"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
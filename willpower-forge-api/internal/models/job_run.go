@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Job run triggers and statuses. A run is skipped when its job is still
+// running from an earlier start.
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+	JobTriggerStartup  = "startup"
+
+	JobRunRunning   = "running"
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+	JobRunSkipped   = "skipped"
+)
+
+// JobRun records one run of a scheduled job.
+type JobRun struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Job        string     `gorm:"not null;index" json:"job"`
+	Trigger    string     `gorm:"not null" json:"trigger"`
+	Status     string     `gorm:"not null" json:"status"`
+	Error      string     `gorm:"type:text" json:"error,omitempty"`
+	StartedAt  time.Time  `gorm:"not null" json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
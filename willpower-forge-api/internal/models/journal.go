@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// JournalEntry is a user's reflection on a whole day, independent of any
+// single goal. Each user has at most one entry per local date.
+type JournalEntry struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	UserID    uint            `gorm:"not null;uniqueIndex:idx_journal_user_date" json:"user_id"`
+	Date      string          `gorm:"not null;uniqueIndex:idx_journal_user_date" json:"date"`
+	Body      string          `json:"body"`
+	Answers   []JournalAnswer `gorm:"constraint:OnDelete:CASCADE" json:"answers"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// JournalAnswer is the answer to one guided reflection prompt. The prompt
+// text is stored as shown to the user, so later wording changes don't alter
+// old entries.
+type JournalAnswer struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	JournalEntryID uint   `gorm:"not null;index" json:"-"`
+	PromptKey      string `gorm:"not null" json:"prompt_key"`
+	Prompt         string `json:"prompt"`
+	Answer         string `json:"answer"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Milestone is an intermediate step toward a goal, such as "run a half
+// marathon" on the way to a marathon. CompletedAt is nil while it is open.
+type Milestone struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	GoalID      uint            `gorm:"not null;index" json:"goal_id"`
+	UserID      uint            `gorm:"not null" json:"user_id"`
+	Title       string          `gorm:"not null" json:"title"`
+	TargetDate  string          `json:"target_date"`
+	Position    int             `gorm:"not null;default:0" json:"position"`
+	CompletedAt *time.Time      `json:"completed_at"`
+	Items       []ChecklistItem `gorm:"constraint:OnDelete:CASCADE" json:"items,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// ChecklistItem is a single to-do on a goal, optionally grouped under one of
+// its milestones.
+type ChecklistItem struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	GoalID      uint       `gorm:"not null;index" json:"goal_id"`
+	UserID      uint       `gorm:"not null" json:"user_id"`
+	MilestoneID *uint      `gorm:"index" json:"milestone_id"`
+	Title       string     `gorm:"not null" json:"title"`
+	Position    int        `gorm:"not null;default:0" json:"position"`
+	DoneAt      *time.Time `json:"done_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
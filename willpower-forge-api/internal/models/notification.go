@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Notification kinds.
+const (
+	NotificationAchievement  = "achievement"
+	NotificationReminder     = "reminder"
+	NotificationAnnouncement = "announcement"
+)
+
+// Notification is an entry in a user's in-app inbox. Link is an app path to
+// open, such as "/goals/3". ReadAt is nil while the notification is unread.
+type Notification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index:idx_notification_user_read" json:"user_id"`
+	Kind      string     `gorm:"not null" json:"kind"`
+	Title     string     `gorm:"not null" json:"title"`
+	Body      string     `gorm:"type:text" json:"body"`
+	Link      string     `gorm:"not null;default:''" json:"link,omitempty"`
+	ReadAt    *time.Time `gorm:"index:idx_notification_user_read" json:"read_at"`
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+}
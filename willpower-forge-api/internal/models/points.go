@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Points currencies.
+const (
+	CurrencyXP     = "xp"
+	CurrencyFreeze = "freeze"
+)
+
+// Reasons for points ledger entries.
+const (
+	PointsReasonCheckIn      = "check_in"
+	PointsReasonFreezeEarned = "freeze_earned"
+	PointsReasonFreezeSpent  = "freeze_spent"
+)
+
+// PointsEntry is one line of a user's points ledger. The ledger is append
+// only: balances are the sum of Amount per currency, and corrections are
+// recorded as new entries rather than edits. GoalID, CheckInID and Date
+// record what the entry was for and are kept when the goal is deleted.
+type PointsEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Currency  string    `gorm:"not null" json:"currency"`
+	Amount    int       `gorm:"not null" json:"amount"`
+	Reason    string    `gorm:"not null" json:"reason"`
+	GoalID    *uint     `gorm:"index" json:"goal_id,omitempty"`
+	CheckInID *uint     `json:"check_in_id,omitempty"`
+	Date      string    `json:"date,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StreakFreeze protects a goal's streak on a day that was missed. Frozen
+// days neither extend nor break a streak, like excused days.
+type StreakFreeze struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	GoalID    uint      `gorm:"not null;uniqueIndex:idx_freeze_goal_date" json:"goal_id"`
+	UserID    uint      `gorm:"not null" json:"user_id"`
+	Date      string    `gorm:"not null;uniqueIndex:idx_freeze_goal_date" json:"date"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// PushSubscription is one browser or device subscribed to Web Push for a
+// user. Endpoint is the push service URL and P256dh and Auth the client's
+// base64url keys from PushSubscription.toJSON(). Subscriptions the push
+// service reports as gone are deleted.
+type PushSubscription struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Endpoint   string     `gorm:"not null;uniqueIndex" json:"endpoint"`
+	P256dh     string     `gorm:"not null" json:"-"`
+	Auth       string     `gorm:"not null" json:"-"`
+	Device     string     `json:"device"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// VapidKey is the server's Web Push signing key, generated on first start
+// unless VAPID_PRIVATE_KEY is set. Both keys are base64url encoded.
+type VapidKey struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	PublicKey  string    `gorm:"not null" json:"public_key"`
+	PrivateKey string    `gorm:"not null" json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Relapse records a slip on an I_WONT goal. Quantity counts how many
+// occurrences the slip involved, e.g. three cigarettes.
+type Relapse struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	GoalID            uint      `gorm:"not null;index" json:"goal_id"`
+	UserID            uint      `gorm:"not null" json:"user_id"`
+	OccurredAt        time.Time `gorm:"not null;index" json:"occurred_at"`
+	Quantity          int       `gorm:"not null;default:1" json:"quantity"`
+	Note              string    `json:"note"`
+	TemptationEventID *uint     `json:"temptation_event_id,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Reminder delivery statuses.
+const (
+	DeliveryStatusSent   = "sent"
+	DeliveryStatusFailed = "failed"
+)
+
+// Reminder nudges the user to check in on a goal. Times is a comma separated
+// list of "HH:MM" times in the user's timezone and Days a schedule in the
+// same form as Goal.Schedule, empty meaning every day. With
+// OnlyIfNotCheckedIn the reminder is skipped once the day has a check-in.
+// Channel names the notify channel and Target its address: an email
+// address or a push/webhook URL. LastSlot is the "YYYY-MM-DD HH:MM" of the
+// last time the reminder fired, so each slot is sent at most once.
+type Reminder struct {
+	ID                 uint               `gorm:"primaryKey" json:"id"`
+	GoalID             uint               `gorm:"not null;index" json:"goal_id"`
+	UserID             uint               `gorm:"not null;index" json:"user_id"`
+	Times              string             `gorm:"not null" json:"times"`
+	Days               string             `gorm:"not null;default:''" json:"days"`
+	OnlyIfNotCheckedIn bool               `gorm:"not null" json:"only_if_not_checked_in"`
+	Channel            string             `gorm:"not null" json:"channel"`
+	Target             string             `gorm:"not null" json:"target"`
+	Enabled            bool               `gorm:"not null" json:"enabled"`
+	LastSlot           string             `gorm:"not null;default:''" json:"last_slot,omitempty"`
+	Deliveries         []ReminderDelivery `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+// TimeList returns the reminder's times of day.
+func (r Reminder) TimeList() []string {
+	if r.Times == "" {
+		return nil
+	}
+	return strings.Split(r.Times, ",")
+}
+
+// IsScheduledOn reports whether the reminder's days include the given
+// YYYY-MM-DD date.
+func (r Reminder) IsScheduledOn(date string) bool {
+	return Goal{Schedule: r.Days}.IsDueOn(date)
+}
+
+// ReminderDelivery records one attempt to deliver a reminder. Error holds
+// the failure reason when Status is "failed". Test deliveries were
+// triggered by the user rather than the schedule.
+type ReminderDelivery struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ReminderID uint      `gorm:"not null;index" json:"reminder_id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	GoalID     uint      `gorm:"not null" json:"goal_id"`
+	Channel    string    `gorm:"not null" json:"channel"`
+	Target     string    `gorm:"not null" json:"target"`
+	Slot       string    `json:"slot,omitempty"`
+	Status     string    `gorm:"not null" json:"status"`
+	Error      string    `gorm:"type:text" json:"error,omitempty"`
+	Test       bool      `gorm:"not null" json:"test"`
+	CreatedAt  time.Time `json:"created_at"`
+}
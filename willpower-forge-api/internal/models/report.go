@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Report is a stored weekly or monthly review. Data holds the generated
+// report as JSON so it can be viewed later exactly as it was produced.
+type Report struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_report_user_period" json:"user_id"`
+	Period    string    `gorm:"not null;uniqueIndex:idx_report_user_period" json:"period"`
+	StartDate string    `gorm:"not null;uniqueIndex:idx_report_user_period" json:"start_date"`
+	EndDate   string    `gorm:"not null" json:"end_date"`
+	Data      string    `gorm:"type:text" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
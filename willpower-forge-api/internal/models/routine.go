@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Routine groups several goals into an ordered sequence that is usually done
+// in one go, such as a morning routine. TimeOfDay is an optional "HH:MM".
+type Routine struct {
+	ID        uint          `gorm:"primaryKey" json:"id"`
+	UserID    uint          `gorm:"not null;index" json:"user_id"`
+	Name      string        `gorm:"not null" json:"name"`
+	TimeOfDay string        `json:"time_of_day"`
+	Steps     []RoutineStep `gorm:"constraint:OnDelete:CASCADE" json:"steps"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// RoutineStep places a goal at a position within a routine. A goal may be
+// part of several routines but appears at most once in each.
+type RoutineStep struct {
+	ID        uint  `gorm:"primaryKey" json:"-"`
+	RoutineID uint  `gorm:"not null;uniqueIndex:idx_routine_step_goal" json:"-"`
+	GoalID    uint  `gorm:"not null;uniqueIndex:idx_routine_step_goal;index" json:"goal_id"`
+	Position  int   `gorm:"not null" json:"position"`
+	Goal      *Goal `gorm:"constraint:OnDelete:CASCADE" json:"goal,omitempty"`
+}
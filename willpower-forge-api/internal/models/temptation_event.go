@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// TemptationTriggers lists the trigger categories a temptation event can be
+// filed under.
+var TemptationTriggers = []string{
+	"stress",
+	"boredom",
+	"fatigue",
+	"hunger",
+	"social",
+	"emotion",
+	"environment",
+	"habit",
+	"other",
+}
+
+// TemptationEvent records a single urge on an I_WONT goal: when it appeared,
+// how strong it was, what triggered it and whether it was resisted.
+type TemptationEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	GoalID     uint      `gorm:"not null;index" json:"goal_id"`
+	UserID     uint      `gorm:"not null" json:"user_id"`
+	OccurredAt time.Time `gorm:"not null;index" json:"occurred_at"`
+	Date       string    `gorm:"not null;index" json:"date"`
+	Intensity  int       `gorm:"not null" json:"intensity"`
+	Trigger    string    `gorm:"not null" json:"trigger"`
+	Resisted   bool      `gorm:"not null" json:"resisted"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
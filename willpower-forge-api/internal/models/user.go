@@ -2,10 +2,25 @@ package models
 
 import "time"
 
+// Missed-day modes decide whether due days left without a check-in are
+// recorded as missed once they are over.
+const (
+	MissedDaysRecord = "record"
+	MissedDaysIgnore = "ignore"
+)
+
+// User is an account. Timezone is an IANA zone name such as
+// "Asia/Shanghai" that decides where the user's days begin and end; empty
+// means the server's local time. MissedDays is the user's missed-day mode,
+// which goals may override, and MissedDayGraceHours how long after a day
+// ends it is still left open for check-ins before being recorded as missed.
 type User struct {
-	ID           uint      `gorm:"primaryKey" json:"id"`
-	Username     string    `gorm:"unique;not null" json:"username"`
-	PasswordHash string    `gorm:"not null" json:"-"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                  uint      `gorm:"primaryKey" json:"id"`
+	Username            string    `gorm:"unique;not null" json:"username"`
+	PasswordHash        string    `gorm:"not null" json:"-"`
+	Timezone            string    `json:"timezone"`
+	MissedDays          string    `gorm:"not null;default:'record'" json:"missed_days"`
+	MissedDayGraceHours int       `gorm:"not null;default:6" json:"missed_day_grace_hours"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// VacationPeriod is an account-wide date range, e.g. sickness or travel,
+// during which missed or failed days are treated as excused for every goal.
+type VacationPeriod struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	StartDate string    `gorm:"not null" json:"start_date"`
+	EndDate   string    `gorm:"not null" json:"end_date"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Covers reports whether the given YYYY-MM-DD date falls inside the period.
+func (v VacationPeriod) Covers(date string) bool {
+	return date >= v.StartDate && date <= v.EndDate
+}
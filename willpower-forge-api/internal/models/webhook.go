@@ -0,0 +1,88 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Webhook event types.
+const (
+	EventGoalCreated       = "goal.created"
+	EventGoalUpdated       = "goal.updated"
+	EventGoalStatusChanged = "goal.status_changed"
+	EventGoalDeleted       = "goal.deleted"
+	EventGoalRestored      = "goal.restored"
+	EventCheckInRecorded   = "checkin.recorded"
+	EventStreakBroken      = "streak.broken"
+	// EventPing is only sent by the "send test event" endpoint.
+	EventPing = "ping"
+)
+
+// WebhookEvents lists the event types a webhook can subscribe to.
+var WebhookEvents = []string{
+	EventGoalCreated,
+	EventGoalUpdated,
+	EventGoalStatusChanged,
+	EventGoalDeleted,
+	EventGoalRestored,
+	EventCheckInRecorded,
+	EventStreakBroken,
+}
+
+// Webhook delivery statuses. A pending delivery is waiting for its first
+// attempt or a retry.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook posts the user's events to URL. Events is a comma separated list
+// of event types. Every request is signed with Secret. Failures counts
+// attempts that failed in a row; the webhook is disabled once it reaches
+// the limit, with DisabledReason saying why.
+type Webhook struct {
+	ID             uint              `gorm:"primaryKey" json:"id"`
+	UserID         uint              `gorm:"not null;index" json:"user_id"`
+	URL            string            `gorm:"not null" json:"url"`
+	Events         string            `gorm:"not null" json:"events"`
+	Secret         string            `gorm:"not null" json:"-"`
+	Enabled        bool              `gorm:"not null" json:"enabled"`
+	Failures       int               `gorm:"not null;default:0" json:"failures"`
+	DisabledReason string            `json:"disabled_reason,omitempty"`
+	Deliveries     []WebhookDelivery `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook receives the event type.
+func (w Webhook) Subscribes(event string) bool {
+	for _, subscribed := range strings.Split(w.Events, ",") {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for a webhook, with the outcome of its
+// latest attempt. Payload is the exact JSON body sent; EventID is shared by
+// the deliveries of one event to several webhooks and lets receivers drop
+// duplicates. LockedUntil is the lease of the worker attempting it.
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	WebhookID      uint       `gorm:"not null;index" json:"webhook_id"`
+	UserID         uint       `gorm:"not null;index" json:"user_id"`
+	EventID        string     `gorm:"not null" json:"event_id"`
+	Event          string     `gorm:"not null" json:"event"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"not null;index" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	Error          string     `gorm:"type:text" json:"error,omitempty"`
+	NextAttemptAt  *time.Time `gorm:"index" json:"next_attempt_at,omitempty"`
+	LockedUntil    *time.Time `json:"-"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
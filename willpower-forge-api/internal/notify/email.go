@@ -0,0 +1,220 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// Email is a message for one recipient with a plain-text body and an
+// optional HTML alternative. Headers adds extra headers such as
+// List-Unsubscribe.
+type Email struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string
+}
+
+// Mailer sends email.
+type Mailer interface {
+	SendEmail(ctx context.Context, email Email) error
+}
+
+// NewMailerFromEnv returns a FileMailer writing to MAIL_OUTPUT_DIR when it
+// is set, which is meant for development, otherwise an SMTPMailer when
+// SMTP_HOST is set. It returns nil when neither is set.
+func NewMailerFromEnv() Mailer {
+	if dir := os.Getenv("MAIL_OUTPUT_DIR"); dir != "" {
+		return &FileMailer{Dir: dir, From: os.Getenv("SMTP_FROM")}
+	}
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		return newSMTPMailerFromEnv(host)
+	}
+	return nil
+}
+
+func newSMTPMailerFromEnv(host string) *SMTPMailer {
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	return &SMTPMailer{
+		Addr:     host + ":" + port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+}
+
+// SMTPMailer sends email through an SMTP server. The server connection is
+// upgraded with STARTTLS when offered; credentials are only sent when
+// Username is set.
+type SMTPMailer struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) SendEmail(ctx context.Context, email Email) error {
+	sender, to, err := parseAddresses(m.From, email.To)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		host := m.Addr
+		if idx := strings.LastIndex(host, ":"); idx >= 0 {
+			host = host[:idx]
+		}
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	data, err := buildEmail(sender, to, email)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.Addr, auth, sender.Address, []string{to.Address}, data)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FileMailer writes every email to Dir as an .eml file instead of sending
+// it, so emails can be inspected during development.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *FileMailer) SendEmail(ctx context.Context, email Email) error {
+	sender, to, err := parseAddresses(m.From, email.To)
+	if err != nil {
+		return err
+	}
+	data, err := buildEmail(sender, to, email)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), hex.EncodeToString(suffix))
+	return os.WriteFile(filepath.Join(m.Dir, name), data, 0o644)
+}
+
+// EmailNotifier sends notifications as plain-text email through Mailer.
+type EmailNotifier struct {
+	Mailer Mailer
+}
+
+func (n *EmailNotifier) Send(ctx context.Context, msg Message) error {
+	body := msg.Body
+	if msg.Link != "" {
+		body += "\n\n" + msg.Link
+	}
+	return n.Mailer.SendEmail(ctx, Email{To: msg.Target, Subject: msg.Title, Text: body})
+}
+
+func parseAddresses(from, to string) (*mail.Address, *mail.Address, error) {
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return nil, nil, ErrInvalidAddress
+	}
+	if from == "" {
+		from = "willpower-forge@localhost"
+	}
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, nil, ErrInvalidAddress
+	}
+	return sender, recipient, nil
+}
+
+// buildEmail renders a UTF-8 message: plain text on its own, or
+// multipart/alternative when there is an HTML body. Line breaks are
+// stripped from header values so they cannot inject headers.
+func buildEmail(from, to *mail.Address, email Email) ([]byte, error) {
+	clean := strings.NewReplacer("\r", " ", "\n", " ").Replace
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", clean(email.Subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	names := make([]string, 0, len(email.Headers))
+	for name := range email.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\r\n", clean(name), clean(email.Headers[name]))
+	}
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if email.HTML == "" {
+		b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+		b.WriteString(crlf(email.Text))
+		b.WriteString("\r\n")
+		return b.Bytes(), nil
+	}
+
+	boundary := make([]byte, 12)
+	if _, err := rand.Read(boundary); err != nil {
+		return nil, err
+	}
+	delimiter := "alt-" + hex.EncodeToString(boundary)
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", delimiter)
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", email.Text},
+		{"text/html", email.HTML},
+	} {
+		fmt.Fprintf(&b, "--%s\r\n", delimiter)
+		fmt.Fprintf(&b, "Content-Type: %s; charset=utf-8\r\n", part.contentType)
+		b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		writer := quotedprintable.NewWriter(&b)
+		if _, err := writer.Write([]byte(crlf(part.body))); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		b.WriteString("\r\n")
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", delimiter)
+	return b.Bytes(), nil
+}
+
+func crlf(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+}
@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fakeMailer struct {
+	sent []Email
+}
+
+func (m *fakeMailer) SendEmail(ctx context.Context, email Email) error {
+	m.sent = append(m.sent, email)
+	return nil
+}
+
+func TestEmailNotifier(t *testing.T) {
+	mailer := &fakeMailer{}
+	notifier := &EmailNotifier{Mailer: mailer}
+
+	err := notifier.Send(context.Background(), Message{Target: "alice@example.com", Title: "Reminder", Body: "Check in", Link: "https://example.com/goals/1"})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if len(mailer.sent) != 1 {
+		t.Fatalf("sent %d emails, want 1", len(mailer.sent))
+	}
+	want := Email{To: "alice@example.com", Subject: "Reminder", Text: "Check in\n\nhttps://example.com/goals/1"}
+	if got := mailer.sent[0]; got.To != want.To || got.Subject != want.Subject || got.Text != want.Text || got.HTML != "" {
+		t.Errorf("email = %+v, want %+v", got, want)
+	}
+}
+
+func TestBuildEmailStripsHeaderInjection(t *testing.T) {
+	from, to, err := parseAddresses("", "alice@example.com")
+	if err != nil {
+		t.Fatalf("parse addresses: %v", err)
+	}
+	data, err := buildEmail(from, to, Email{
+		Subject: "Hi\r\nBcc: eve@example.com",
+		Text:    "line one\nline two",
+		Headers: map[string]string{"List-Unsubscribe": "<https://example.com/u>\nBcc: eve@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	header, body, _ := strings.Cut(string(data), "\r\n\r\n")
+	for _, line := range strings.Split(header, "\r\n") {
+		if strings.HasPrefix(line, "Bcc:") {
+			t.Errorf("header injected: %q", line)
+		}
+	}
+	if body != "line one\r\nline two\r\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestBuildEmailMultipart(t *testing.T) {
+	from, to, _ := parseAddresses("Forge <forge@example.com>", "alice@example.com")
+	data, err := buildEmail(from, to, Email{Subject: "Digest", Text: "plain", HTML: "<p>html</p>"})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	text := string(data)
+	for _, want := range []string{"multipart/alternative", "Content-Type: text/plain", "Content-Type: text/html", "<p>html</p>"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("email is missing %q", want)
+		}
+	}
+}
+
+func TestParseAddressesRejectsInvalid(t *testing.T) {
+	if _, _, err := parseAddresses("", "not an address"); err != ErrInvalidAddress {
+		t.Errorf("got %v, want ErrInvalidAddress", err)
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer := &FileMailer{Dir: dir}
+
+	if err := mailer.SendEmail(context.Background(), Email{To: "alice@example.com", Subject: "Hello", Text: "Body"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("got files %v (%v), want one .eml", files, err)
+	}
+	data, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(data), "To: <alice@example.com>") {
+		t.Errorf("email = %q", data)
+	}
+}
+
+func TestSMTPMailer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go serveSMTP(listener, received)
+
+	mailer := &SMTPMailer{Addr: listener.Addr().String(), From: "forge@example.com"}
+	if err := mailer.SendEmail(context.Background(), Email{To: "alice@example.com", Subject: "Hello", Text: "Body"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	commands := <-received
+	want := []string{"MAIL FROM:<forge@example.com>", "RCPT TO:<alice@example.com>", "DATA"}
+	joined := strings.Join(commands, "\n")
+	for _, command := range want {
+		if !strings.Contains(joined, command) {
+			t.Errorf("commands %q are missing %q", commands, command)
+		}
+	}
+}
+
+// serveSMTP answers one SMTP session just far enough for net/smtp and
+// reports the commands it received.
+func serveSMTP(listener net.Listener, received chan<- []string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		received <- nil
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	var commands []string
+	text.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			break
+		}
+		commands = append(commands, line)
+		switch verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); verb {
+		case "EHLO", "HELO":
+			text.PrintfLine("250 localhost")
+		case "DATA":
+			text.PrintfLine("354 go ahead")
+			text.ReadDotLines()
+			text.PrintfLine("250 queued")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			received <- commands
+			return
+		default:
+			text.PrintfLine("250 ok")
+		}
+	}
+	received <- commands
+}
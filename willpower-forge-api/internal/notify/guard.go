@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrAddressNotAllowed is returned when a user-supplied URL resolves to an
+// internal address.
+var ErrAddressNotAllowed = errors.New("destination address not allowed")
+
+// blockedNetworks are ranges not covered by the net.IP predicates in
+// blockedIP that must not be reachable from user-supplied URLs either.
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",      // "this" network
+	"100.64.0.0/10",  // carrier-grade NAT
+	"192.0.0.0/24",   // IETF protocol assignments
+	"198.18.0.0/15",  // benchmarking
+	"240.0.0.0/4",    // reserved, including broadcast
+	"64:ff9b::/96",   // NAT64, which can reach IPv4 internals
+	"64:ff9b:1::/48", // local-use NAT64
+	"2001::/32",      // Teredo
+	"2002::/16",      // 6to4
+	"100::/64",       // discard-only
+	"2001:db8::/32",  // documentation
+	"fec0::/10",      // deprecated site-local
+)
+
+// NewHTTPClient returns a client for requests to URLs users supply, such as
+// webhooks and push endpoints. It refuses to connect to loopback, private,
+// link-local and other internal addresses, checked on every connection so
+// DNS answers and redirects cannot get around it, and ignores proxy
+// settings. OUTBOUND_ALLOWED_NETWORKS, a comma separated list of IPs or
+// CIDR ranges, exempts addresses such as a self-hosted ntfy or Gotify
+// server.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return newGuardedClient(timeout, allowedNetworksFromEnv())
+}
+
+func newGuardedClient(timeout time.Duration, allowed []*net.IPNet) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || (blockedIP(ip) && !containsIP(allowed, ip)) {
+				return ErrAddressNotAllowed
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// blockedIP reports whether ip is an internal address.
+func blockedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	return containsIP(blockedNetworks, ip)
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func allowedNetworksFromEnv() []*net.IPNet {
+	var allowed []*net.IPNet
+	for _, entry := range strings.Split(os.Getenv("OUTBOUND_ALLOWED_NETWORKS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		network, err := parseNetwork(entry)
+		if err != nil {
+			log.Printf("Ignoring invalid OUTBOUND_ALLOWED_NETWORKS entry %q", entry)
+			continue
+		}
+		allowed = append(allowed, network)
+	}
+	return allowed
+}
+
+// parseNetwork parses a CIDR range or a single IP.
+func parseNetwork(entry string) (*net.IPNet, error) {
+	if !strings.Contains(entry, "/") {
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, &net.ParseError{Type: "IP address", Text: entry}
+		}
+		bits := 128
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, network, err := net.ParseCIDR(entry)
+	return network, err
+}
+
+func mustParseCIDRs(entries ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		network, err := parseNetwork(entry)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBlockedIP(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1":       true,
+		"10.1.2.3":        true,
+		"172.16.0.1":      true,
+		"192.168.1.20":    true,
+		"169.254.169.254": true,
+		"100.64.0.1":      true,
+		"0.0.0.0":         true,
+		"224.0.0.1":       true,
+		"::1":             true,
+		"fe80::1":         true,
+		"fd00::1":         true,
+		"::ffff:10.0.0.1": true,
+		"64:ff9b::a00:1":  true,
+		"93.184.216.34":   false,
+		"8.8.8.8":         false,
+		"2606:4700::1111": false,
+	}
+	for addr, want := range tests {
+		if got := blockedIP(net.ParseIP(addr)); got != want {
+			t.Errorf("blockedIP(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestGuardedClientRefusesInternalAddresses(t *testing.T) {
+	server, _ := standIn(t, http.StatusOK, "")
+	notifier := &WebhookNotifier{Client: newGuardedClient(time.Second, nil)}
+
+	err := notifier.Send(context.Background(), Message{Target: server.URL})
+	if !errors.Is(err, ErrAddressNotAllowed) {
+		t.Fatalf("Send to %s = %v, want ErrAddressNotAllowed", server.URL, err)
+	}
+}
+
+func TestGuardedClientFollowsAllowlist(t *testing.T) {
+	server, requests := standIn(t, http.StatusOK, "")
+	allowed := mustParseCIDRs("127.0.0.1")
+	notifier := &WebhookNotifier{Client: newGuardedClient(time.Second, allowed)}
+
+	if err := notifier.Send(context.Background(), Message{Target: server.URL}); err != nil {
+		t.Fatalf("send to allowed address: %v", err)
+	}
+	<-requests
+}
+
+func TestParseNetwork(t *testing.T) {
+	for _, entry := range []string{"192.168.1.20", "10.8.0.0/16", "fd00::/8", "::1"} {
+		if _, err := parseNetwork(entry); err != nil {
+			t.Errorf("parseNetwork(%q): %v", entry, err)
+		}
+	}
+	for _, entry := range []string{"", "example.com", "10.0.0.0/40"} {
+		if _, err := parseNetwork(entry); err == nil {
+			t.Errorf("parseNetwork(%q) succeeded, want an error", entry)
+		}
+	}
+}
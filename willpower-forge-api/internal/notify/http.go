@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrInvalidURL = errors.New("invalid notification URL")
+
+// ValidURL reports whether target is an absolute http or https URL.
+func ValidURL(target string) bool {
+	parsed, err := url.Parse(target)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// WebhookNotifier posts the message as JSON to the target URL.
+type WebhookNotifier struct {
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(map[string]string{
+		"title":   msg.Title,
+		"body":    msg.Body,
+		"link":    msg.Link,
+		"sent_at": time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, n.Client, msg.Target, "application/json", payload, nil)
+}
+
+// NtfyNotifier publishes to an ntfy topic URL such as
+// https://ntfy.sh/my-topic. The body is the message; title and link travel
+// in headers.
+type NtfyNotifier struct {
+	Client *http.Client
+}
+
+func (n *NtfyNotifier) Send(ctx context.Context, msg Message) error {
+	headers := map[string]string{"Title": msg.Title}
+	if msg.Link != "" {
+		headers["Click"] = msg.Link
+	}
+	return post(ctx, n.Client, msg.Target, "text/plain; charset=utf-8", []byte(msg.Body), headers)
+}
+
+// GotifyNotifier posts to a Gotify message URL including the application
+// token, such as https://push.example.com/message?token=abc.
+type GotifyNotifier struct {
+	Client *http.Client
+}
+
+func (n *GotifyNotifier) Send(ctx context.Context, msg Message) error {
+	body := msg.Body
+	if msg.Link != "" {
+		body += "\n\n" + msg.Link
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"title":    msg.Title,
+		"message":  body,
+		"priority": 5,
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, n.Client, msg.Target, "application/json", payload, nil)
+}
+
+func post(ctx context.Context, client *http.Client, target, contentType string, body []byte, headers map[string]string) error {
+	if !ValidURL(target) {
+		return ErrInvalidURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "willpower-forge")
+	for name, value := range headers {
+		// Header values must not contain line breaks.
+		req.Header.Set(name, strings.NewReplacer("\r", " ", "\n", " ").Replace(value))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	return checkResponse(resp)
+}
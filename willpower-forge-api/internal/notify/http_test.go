@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recordedRequest is what a stand-in server received.
+type recordedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// standIn starts a server answering status and returns the requests it
+// receives.
+func standIn(t *testing.T, status int, response string) (*httptest.Server, <-chan recordedRequest) {
+	t.Helper()
+	requests := make(chan recordedRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- recordedRequest{header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	server, requests := standIn(t, http.StatusOK, "")
+	notifier := &WebhookNotifier{Client: server.Client()}
+
+	err := notifier.Send(context.Background(), Message{Target: server.URL + "/hook", Title: "Reminder", Body: "Check in", Link: "/goals/1"})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	req := <-requests
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if payload["title"] != "Reminder" || payload["body"] != "Check in" || payload["link"] != "/goals/1" || payload["sent_at"] == "" {
+		t.Errorf("payload = %v", payload)
+	}
+}
+
+func TestNtfyNotifier(t *testing.T) {
+	server, requests := standIn(t, http.StatusOK, "")
+	notifier := &NtfyNotifier{Client: server.Client()}
+
+	err := notifier.Send(context.Background(), Message{Target: server.URL + "/topic", Title: "Line\r\nX-Injected: 1", Body: "Check in", Link: "https://example.com"})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	req := <-requests
+	if string(req.body) != "Check in" {
+		t.Errorf("body = %q", req.body)
+	}
+	if got := req.header.Get("Title"); got != "Line  X-Injected: 1" {
+		t.Errorf("Title = %q", got)
+	}
+	if req.header.Get("X-Injected") != "" {
+		t.Error("title injected a header")
+	}
+	if got := req.header.Get("Click"); got != "https://example.com" {
+		t.Errorf("Click = %q", got)
+	}
+}
+
+func TestGotifyNotifier(t *testing.T) {
+	server, requests := standIn(t, http.StatusOK, "")
+	notifier := &GotifyNotifier{Client: server.Client()}
+
+	err := notifier.Send(context.Background(), Message{Target: server.URL + "/message?token=abc", Title: "Reminder", Body: "Check in", Link: "https://example.com"})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	var payload struct {
+		Title    string `json:"title"`
+		Message  string `json:"message"`
+		Priority int    `json:"priority"`
+	}
+	if err := json.Unmarshal((<-requests).body, &payload); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if payload.Title != "Reminder" || payload.Message != "Check in\n\nhttps://example.com" || payload.Priority != 5 {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestFailedDeliveryHidesResponseBody(t *testing.T) {
+	server, _ := standIn(t, http.StatusForbidden, "secret internal response")
+	notifier := &WebhookNotifier{Client: server.Client()}
+
+	err := notifier.Send(context.Background(), Message{Target: server.URL})
+	if err == nil {
+		t.Fatal("expected an error for status 403")
+	}
+	if strings.Contains(err.Error(), "secret") || !strings.Contains(err.Error(), "403") {
+		t.Errorf("error = %q, want the status without the body", err)
+	}
+}
+
+func TestSendRejectsInvalidURL(t *testing.T) {
+	notifier := &WebhookNotifier{Client: http.DefaultClient}
+	for _, target := range []string{"", "ftp://example.com", "/relative", "http://"} {
+		if err := notifier.Send(context.Background(), Message{Target: target}); err != ErrInvalidURL {
+			t.Errorf("Send(%q) = %v, want ErrInvalidURL", target, err)
+		}
+	}
+}
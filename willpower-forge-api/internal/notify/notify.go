@@ -0,0 +1,101 @@
+// Package notify delivers notifications to users over pluggable channels.
+// Each channel is a Notifier; a Dispatcher routes a message to the notifier
+// registered for the channel the user picked.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Channel names. Web Push is not a Dispatcher channel: it goes to every
+// browser a user subscribed rather than to a target address.
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelNtfy    = "ntfy"
+	ChannelGotify  = "gotify"
+	ChannelWebPush = "webpush"
+)
+
+// requestTimeout bounds every HTTP delivery.
+const requestTimeout = 10 * time.Second
+
+var ErrUnknownChannel = errors.New("notification channel not available")
+
+// Message is a notification for one recipient. Target is channel specific:
+// an email address for email, a URL for the HTTP channels.
+type Message struct {
+	Target string
+	Title  string
+	Body   string
+	Link   string
+}
+
+// Notifier delivers messages over one channel.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Dispatcher routes messages to the notifier of their channel.
+type Dispatcher struct {
+	notifiers map[string]Notifier
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{notifiers: make(map[string]Notifier)}
+}
+
+// NewDispatcherFromEnv registers the HTTP channels, and email when a mailer
+// is configured, see NewMailerFromEnv. SMTP_PORT (default 587),
+// SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM configure the mail server. The
+// HTTP channels cannot reach internal addresses, see NewHTTPClient.
+func NewDispatcherFromEnv() *Dispatcher {
+	client := NewHTTPClient(requestTimeout)
+
+	d := NewDispatcher()
+	d.Register(ChannelWebhook, &WebhookNotifier{Client: client})
+	d.Register(ChannelNtfy, &NtfyNotifier{Client: client})
+	d.Register(ChannelGotify, &GotifyNotifier{Client: client})
+
+	if mailer := NewMailerFromEnv(); mailer != nil {
+		d.Register(ChannelEmail, &EmailNotifier{Mailer: mailer})
+	}
+	return d
+}
+
+// Register adds or replaces the notifier of a channel.
+func (d *Dispatcher) Register(channel string, notifier Notifier) {
+	d.notifiers[channel] = notifier
+}
+
+// Supports reports whether a notifier is registered for the channel.
+func (d *Dispatcher) Supports(channel string) bool {
+	_, ok := d.notifiers[channel]
+	return ok
+}
+
+// Send delivers msg over the channel.
+func (d *Dispatcher) Send(ctx context.Context, channel string, msg Message) error {
+	notifier, ok := d.notifiers[channel]
+	if !ok {
+		return ErrUnknownChannel
+	}
+	return notifier.Send(ctx, msg)
+}
+
+// checkResponse turns a non-2xx response into an error and drains the body
+// so the connection can be reused. The body is not included: errors are
+// shown to the user, who must not be able to read responses through them.
+func checkResponse(resp *http.Response) error {
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// pushRecordSize is the aes128gcm record size. Web Push messages are a
+	// single record, so it also bounds the payload.
+	pushRecordSize = 4096
+	// pushTTL is how long the push service keeps an undelivered message.
+	pushTTL = 24 * time.Hour
+	// vapidTokenLifetime is the lifetime of the VAPID JWT; push services
+	// reject tokens valid for more than 24 hours.
+	vapidTokenLifetime = 12 * time.Hour
+)
+
+// maxPushPayload is the largest plaintext that fits the 4096 bytes push
+// services accept: the 86-byte header, the padding delimiter and the
+// 16-byte authentication tag take the rest.
+const maxPushPayload = pushRecordSize - 86 - 1 - 16
+
+var (
+	// ErrSubscriptionGone means the push service no longer knows the
+	// subscription (404 or 410) and it should be removed.
+	ErrSubscriptionGone = errors.New("push subscription expired")
+	ErrInvalidKey       = errors.New("invalid push key")
+	ErrPayloadTooLarge  = errors.New("push payload too large")
+	ErrInvalidVAPIDKey  = errors.New("invalid VAPID key")
+)
+
+var (
+	base64URL           = base64.RawURLEncoding
+	pushContentEncoding = []byte("Content-Encoding: aes128gcm\x00")
+	pushNonceEncoding   = []byte("Content-Encoding: nonce\x00")
+	pushKeyInfoPrefix   = []byte("WebPush: info\x00")
+)
+
+// PushSubscription is a browser's push subscription: the push service
+// endpoint and the client keys from PushSubscription.toJSON(), both
+// base64url encoded.
+type PushSubscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// WebPushSender sends encrypted Web Push messages (RFC 8291) authenticated
+// with VAPID (RFC 8292). Subject is the contact URI sent to push services,
+// such as "mailto:admin@example.com".
+type WebPushSender struct {
+	Client  *http.Client
+	Key     *ecdsa.PrivateKey
+	Subject string
+}
+
+func NewWebPushSender(key *ecdsa.PrivateKey, subject string) *WebPushSender {
+	return &WebPushSender{Client: NewHTTPClient(requestTimeout), Key: key, Subject: subject}
+}
+
+// GenerateVAPIDKey creates a new P-256 VAPID key pair.
+func GenerateVAPIDKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
+
+// EncodeVAPIDPublicKey returns the uncompressed public key in base64url, the
+// applicationServerKey browsers subscribe with.
+func EncodeVAPIDPublicKey(key *ecdsa.PrivateKey) string {
+	return base64URL.EncodeToString(elliptic.Marshal(elliptic.P256(), key.X, key.Y))
+}
+
+// EncodeVAPIDPrivateKey returns the 32-byte private scalar in base64url.
+func EncodeVAPIDPrivateKey(key *ecdsa.PrivateKey) string {
+	return base64URL.EncodeToString(key.D.FillBytes(make([]byte, 32)))
+}
+
+// DecodeVAPIDPrivateKey parses a private key encoded with
+// EncodeVAPIDPrivateKey.
+func DecodeVAPIDPrivateKey(encoded string) (*ecdsa.PrivateKey, error) {
+	raw, err := base64URL.DecodeString(encoded)
+	if err != nil || len(raw) != 32 {
+		return nil, ErrInvalidVAPIDKey
+	}
+
+	curve := elliptic.P256()
+	d := new(big.Int).SetBytes(raw)
+	if d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, ErrInvalidVAPIDKey
+	}
+	key := &ecdsa.PrivateKey{D: d}
+	key.Curve = curve
+	key.X, key.Y = curve.ScalarBaseMult(raw)
+	return key, nil
+}
+
+// Send encrypts payload for the subscription and posts it to its push
+// service. A 404 or 410 response returns ErrSubscriptionGone.
+func (s *WebPushSender) Send(ctx context.Context, sub PushSubscription, payload []byte) error {
+	if !ValidURL(sub.Endpoint) {
+		return ErrInvalidURL
+	}
+	body, err := encryptPushPayload(sub, payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := s.vapidAuthorization(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(pushTTL.Seconds())))
+	req.Header.Set("Urgency", "normal")
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		resp.Body.Close()
+		return ErrSubscriptionGone
+	}
+	return checkResponse(resp)
+}
+
+// vapidAuthorization builds the "vapid t=..., k=..." header: an ES256 JWT for
+// the endpoint's origin and the public key that signed it.
+func (s *WebPushSender) vapidAuthorization(endpoint string) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", ErrInvalidURL
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": parsed.Scheme + "://" + parsed.Host,
+		"exp": time.Now().Add(vapidTokenLifetime).Unix(),
+		"sub": s.Subject,
+	})
+	signed, err := token.SignedString(s.Key)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, EncodeVAPIDPublicKey(s.Key)), nil
+}
+
+// encryptPushPayload encrypts payload with the aes128gcm content encoding
+// (RFC 8188) using keys derived as in RFC 8291. The result is the header
+// (salt, record size, sender public key) followed by a single record.
+func encryptPushPayload(sub PushSubscription, payload []byte) ([]byte, error) {
+	if len(payload) > maxPushPayload {
+		return nil, ErrPayloadTooLarge
+	}
+
+	asKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encryptPushRecord(sub, payload, asKey, salt)
+}
+
+// encryptPushRecord is encryptPushPayload with the sender key pair and salt
+// given.
+func encryptPushRecord(sub PushSubscription, payload []byte, asKey *ecdsa.PrivateKey, salt []byte) ([]byte, error) {
+	uaPublic, uaX, uaY, authSecret, err := decodeSubscriptionKeys(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	curve := elliptic.P256()
+	asPublic := elliptic.Marshal(curve, asKey.X, asKey.Y)
+	sharedX, _ := curve.ScalarMult(uaX, uaY, asKey.D.FillBytes(make([]byte, 32)))
+	ecdhSecret := sharedX.FillBytes(make([]byte, 32))
+
+	keyInfo := append(append(append([]byte{}, pushKeyInfoPrefix...), uaPublic...), asPublic...)
+	ikm, err := hkdfBytes(ecdhSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdfBytes(ikm, salt, pushContentEncoding, 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdfBytes(ikm, salt, pushNonceEncoding, 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 marks the last (and only) record.
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	var out bytes.Buffer
+	out.Write(salt)
+	binary.Write(&out, binary.BigEndian, uint32(pushRecordSize))
+	out.WriteByte(byte(len(asPublic)))
+	out.Write(asPublic)
+	out.Write(gcm.Seal(nil, nonce, plaintext, nil))
+	return out.Bytes(), nil
+}
+
+// ValidateSubscriptionKeys checks that the subscription's p256dh is a P-256
+// public key and auth a 16-byte secret.
+func ValidateSubscriptionKeys(sub PushSubscription) error {
+	_, _, _, _, err := decodeSubscriptionKeys(sub)
+	return err
+}
+
+func decodeSubscriptionKeys(sub PushSubscription) (public []byte, x, y *big.Int, auth []byte, err error) {
+	public, err = base64URL.DecodeString(trimPadding(sub.P256dh))
+	if err != nil {
+		return nil, nil, nil, nil, ErrInvalidKey
+	}
+	x, y = elliptic.Unmarshal(elliptic.P256(), public)
+	if x == nil {
+		return nil, nil, nil, nil, ErrInvalidKey
+	}
+	auth, err = base64URL.DecodeString(trimPadding(sub.Auth))
+	if err != nil || len(auth) != 16 {
+		return nil, nil, nil, nil, ErrInvalidKey
+	}
+	return public, x, y, auth, nil
+}
+
+func hkdfBytes(secret, salt, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// trimPadding accepts keys sent with base64 padding, which some browsers
+// and libraries include.
+func trimPadding(s string) string {
+	for len(s) > 0 && s[len(s)-1] == '=' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
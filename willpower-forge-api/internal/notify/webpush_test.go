@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// The example of RFC 8291, Appendix A.
+const (
+	rfc8291Plaintext = "V2hlbiBJIGdyb3cgdXAsIEkgd2FudCB0byBiZSBhIHdhdGVybWVsb24"
+	rfc8291ASPrivate = "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"
+	rfc8291ASPublic  = "BP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A8"
+	rfc8291UAPrivate = "q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"
+	rfc8291UAPublic  = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	rfc8291Salt      = "DGv6ra1nlYgDCS1FRnbzlw"
+	rfc8291Auth      = "BTBZMqHH6r4Tts7J_aSIgg"
+	rfc8291Body      = "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+)
+
+func decodeB64(t *testing.T, s string) []byte {
+	t.Helper()
+	raw, err := base64URL.DecodeString(s)
+	if err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+	return raw
+}
+
+func TestEncryptPushRecordRFC8291(t *testing.T) {
+	asKey, err := DecodeVAPIDPrivateKey(rfc8291ASPrivate)
+	if err != nil {
+		t.Fatalf("decode sender key: %v", err)
+	}
+	if got := EncodeVAPIDPublicKey(asKey); got != rfc8291ASPublic {
+		t.Fatalf("sender public key = %s, want %s", got, rfc8291ASPublic)
+	}
+
+	sub := PushSubscription{P256dh: rfc8291UAPublic, Auth: rfc8291Auth}
+	body, err := encryptPushRecord(sub, decodeB64(t, rfc8291Plaintext), asKey, decodeB64(t, rfc8291Salt))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if got := base64URL.EncodeToString(body); got != rfc8291Body {
+		t.Errorf("body = %s\nwant   %s", got, rfc8291Body)
+	}
+}
+
+// decryptPushPayload reverses encryptPushPayload as a user agent would.
+func decryptPushPayload(t *testing.T, uaKey *ecdsa.PrivateKey, authSecret, body []byte) []byte {
+	t.Helper()
+	if len(body) < 21 {
+		t.Fatalf("body of %d bytes is too short", len(body))
+	}
+	salt, recordSize, keyLength := body[:16], binary.BigEndian.Uint32(body[16:20]), int(body[20])
+	if recordSize != pushRecordSize || keyLength != 65 {
+		t.Fatalf("record size %d, key length %d", recordSize, keyLength)
+	}
+	asPublic, record := body[21:21+keyLength], body[21+keyLength:]
+
+	curve := elliptic.P256()
+	asX, asY := elliptic.Unmarshal(curve, asPublic)
+	sharedX, _ := curve.ScalarMult(asX, asY, uaKey.D.FillBytes(make([]byte, 32)))
+	uaPublic := elliptic.Marshal(curve, uaKey.X, uaKey.Y)
+
+	keyInfo := append(append(append([]byte{}, pushKeyInfoPrefix...), uaPublic...), asPublic...)
+	ikm, _ := hkdfBytes(sharedX.FillBytes(make([]byte, 32)), authSecret, keyInfo, 32)
+	cek, _ := hkdfBytes(ikm, salt, pushContentEncoding, 16)
+	nonce, _ := hkdfBytes(ikm, salt, pushNonceEncoding, 12)
+
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plaintext, err := gcm.Open(nil, nonce, record, nil)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if len(plaintext) == 0 || plaintext[len(plaintext)-1] != 0x02 {
+		t.Fatalf("record is not marked as the last one")
+	}
+	return plaintext[:len(plaintext)-1]
+}
+
+func TestEncryptPushPayloadRejectsLargePayloads(t *testing.T) {
+	sub := PushSubscription{P256dh: rfc8291UAPublic, Auth: rfc8291Auth}
+	if _, err := encryptPushPayload(sub, make([]byte, maxPushPayload)); err != nil {
+		t.Errorf("largest payload: %v", err)
+	}
+	if _, err := encryptPushPayload(sub, make([]byte, maxPushPayload+1)); err != ErrPayloadTooLarge {
+		t.Errorf("oversized payload: got %v, want ErrPayloadTooLarge", err)
+	}
+}
+
+func TestWebPushSender(t *testing.T) {
+	uaKey, err := DecodeVAPIDPrivateKey(rfc8291UAPrivate)
+	if err != nil {
+		t.Fatalf("decode user agent key: %v", err)
+	}
+	vapidKey, err := GenerateVAPIDKey()
+	if err != nil {
+		t.Fatalf("generate VAPID key: %v", err)
+	}
+
+	var received *http.Request
+	var body []byte
+	status := http.StatusCreated
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sender := &WebPushSender{Client: server.Client(), Key: vapidKey, Subject: "mailto:admin@example.com"}
+	sub := PushSubscription{Endpoint: server.URL + "/push/abc", P256dh: rfc8291UAPublic, Auth: rfc8291Auth}
+	payload := []byte(`{"title":"Reminder"}`)
+	if err := sender.Send(context.Background(), sub, payload); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if got := received.Header.Get("Content-Encoding"); got != "aes128gcm" {
+		t.Errorf("Content-Encoding = %q", got)
+	}
+	if received.Header.Get("TTL") == "" {
+		t.Error("missing TTL header")
+	}
+	if got := decryptPushPayload(t, uaKey, decodeB64(t, rfc8291Auth), body); !bytes.Equal(got, payload) {
+		t.Errorf("decrypted payload = %q, want %q", got, payload)
+	}
+
+	// Authorization: vapid t=<JWT signed by the VAPID key>, k=<its public key>
+	authorization := received.Header.Get("Authorization")
+	fields := strings.Split(strings.TrimPrefix(authorization, "vapid "), ", ")
+	if !strings.HasPrefix(authorization, "vapid ") || len(fields) != 2 ||
+		!strings.HasPrefix(fields[0], "t=") || fields[1] != "k="+EncodeVAPIDPublicKey(vapidKey) {
+		t.Fatalf("Authorization = %q", authorization)
+	}
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(strings.TrimPrefix(fields[0], "t="), claims, func(*jwt.Token) (interface{}, error) {
+		return &vapidKey.PublicKey, nil
+	}); err != nil {
+		t.Fatalf("verify VAPID token: %v", err)
+	}
+	if claims["aud"] != server.URL || claims["sub"] != "mailto:admin@example.com" {
+		t.Errorf("VAPID claims = %v", claims)
+	}
+
+	for _, status = range []int{http.StatusNotFound, http.StatusGone} {
+		if err := sender.Send(context.Background(), sub, payload); !errors.Is(err, ErrSubscriptionGone) {
+			t.Errorf("status %d: got %v, want ErrSubscriptionGone", status, err)
+		}
+	}
+	status = http.StatusTooManyRequests
+	if err := sender.Send(context.Background(), sub, payload); err == nil || errors.Is(err, ErrSubscriptionGone) {
+		t.Errorf("status 429: got %v, want a plain failure", err)
+	}
+}
@@ -7,15 +7,29 @@ import (
 	"willpower-forge-api/internal/middleware"
 )
 
-func SetupRoutes(router *gin.Engine, authHandler *handlers.AuthHandler, goalHandler *handlers.GoalHandler, checkInHandler *handlers.CheckInHandler) {
+func SetupRoutes(router *gin.Engine, authHandler *handlers.AuthHandler, goalHandler *handlers.GoalHandler, checkInHandler *handlers.CheckInHandler, intentionHandler *handlers.IntentionHandler, templateHandler *handlers.TemplateHandler, tagHandler *handlers.TagHandler, vacationHandler *handlers.VacationHandler, temptationHandler *handlers.TemptationHandler, relapseHandler *handlers.RelapseHandler, journalHandler *handlers.JournalHandler, routineHandler *handlers.RoutineHandler, milestoneHandler *handlers.MilestoneHandler, strengthHandler *handlers.StrengthHandler, reportHandler *handlers.ReportHandler, achievementHandler *handlers.AchievementHandler, pointsHandler *handlers.PointsHandler, reminderHandler *handlers.ReminderHandler, pushHandler *handlers.PushHandler, webhookHandler *handlers.WebhookHandler, inboundHookHandler *handlers.InboundHookHandler, adminHandler *handlers.AdminHandler, digestHandler *handlers.DigestHandler, notificationHandler *handlers.NotificationHandler, eventHandler *handlers.EventHandler) {
 	api := router.Group("/api/v1")
 
 	api.POST("/auth/register", authHandler.Register)
 	api.POST("/auth/login", authHandler.Login)
 
+	// Inbound hooks authenticate with the token in their URL.
+	api.POST("/hooks/:token", inboundHookHandler.Receive)
+
+	// Digest emails carry an unsubscribe token that works without logging in.
+	api.GET("/digest/unsubscribe", digestHandler.ConfirmUnsubscribe)
+	api.POST("/digest/unsubscribe", digestHandler.Unsubscribe)
+
+	// Browsers cannot set headers on an EventSource, so the event stream
+	// also takes the token from the query.
+	api.GET("/events/stream", middleware.EventStreamAuthMiddleware(), eventHandler.Stream)
+
 	authenticated := api.Group("")
 	authenticated.Use(middleware.AuthMiddleware())
 
+	authenticated.GET("/profile", authHandler.GetProfile)
+	authenticated.PUT("/profile", authHandler.UpdateProfile)
+
 	authenticated.POST("/goals", goalHandler.CreateGoal)
 	authenticated.GET("/goals", goalHandler.GetGoals)
 	authenticated.GET("/goals/:id", goalHandler.GetGoalByID)
@@ -26,7 +40,122 @@ func SetupRoutes(router *gin.Engine, authHandler *handlers.AuthHandler, goalHand
 	authenticated.POST("/goals/:id/restore", goalHandler.RestoreGoal)
 	authenticated.DELETE("/goals/:id/permanent", goalHandler.PermanentDeleteGoal)
 
+	authenticated.GET("/goals/:id/intentions", intentionHandler.ListIntentions)
+	authenticated.POST("/goals/:id/intentions", intentionHandler.CreateIntention)
+	authenticated.PUT("/goals/:id/intentions/:intentionId", intentionHandler.UpdateIntention)
+	authenticated.DELETE("/goals/:id/intentions/:intentionId", intentionHandler.DeleteIntention)
+
+	authenticated.GET("/goals/:id/milestones", milestoneHandler.ListMilestones)
+	authenticated.POST("/goals/:id/milestones", milestoneHandler.CreateMilestone)
+	authenticated.PUT("/goals/:id/milestones/order", milestoneHandler.ReorderMilestones)
+	authenticated.PUT("/goals/:id/milestones/:milestoneId", milestoneHandler.UpdateMilestone)
+	authenticated.DELETE("/goals/:id/milestones/:milestoneId", milestoneHandler.DeleteMilestone)
+	authenticated.GET("/goals/:id/checklist", milestoneHandler.ListChecklist)
+	authenticated.POST("/goals/:id/checklist", milestoneHandler.CreateChecklistItem)
+	authenticated.PUT("/goals/:id/checklist/order", milestoneHandler.ReorderChecklist)
+	authenticated.PUT("/goals/:id/checklist/:itemId", milestoneHandler.UpdateChecklistItem)
+	authenticated.DELETE("/goals/:id/checklist/:itemId", milestoneHandler.DeleteChecklistItem)
+	authenticated.GET("/goals/:id/progress", milestoneHandler.GoalProgress)
+	authenticated.GET("/goals/:id/timeline", milestoneHandler.GoalTimeline)
+	authenticated.GET("/goals/:id/strength", strengthHandler.GoalStrength)
+
+	authenticated.GET("/goals/:id/reminders", reminderHandler.ListReminders)
+	authenticated.POST("/goals/:id/reminders", reminderHandler.CreateReminder)
+	authenticated.PUT("/goals/:id/reminders/:reminderId", reminderHandler.UpdateReminder)
+	authenticated.DELETE("/goals/:id/reminders/:reminderId", reminderHandler.DeleteReminder)
+	authenticated.POST("/goals/:id/reminders/:reminderId/test", reminderHandler.TestReminder)
+	authenticated.GET("/reminders/deliveries", reminderHandler.ListDeliveries)
+
+	authenticated.GET("/goals/:id/inbound-hooks", inboundHookHandler.ListHooks)
+	authenticated.POST("/goals/:id/inbound-hooks", inboundHookHandler.CreateHook)
+	authenticated.DELETE("/goals/:id/inbound-hooks/:hookId", inboundHookHandler.DeleteHook)
+	authenticated.POST("/goals/:id/inbound-hooks/:hookId/rotate", inboundHookHandler.RotateToken)
+	authenticated.GET("/goals/:id/inbound-hooks/:hookId/calls", inboundHookHandler.ListCalls)
+
+	authenticated.GET("/push/vapid-public-key", pushHandler.GetVAPIDPublicKey)
+	authenticated.GET("/push/subscriptions", pushHandler.ListSubscriptions)
+	authenticated.POST("/push/subscriptions", pushHandler.Subscribe)
+	authenticated.DELETE("/push/subscriptions/:id", pushHandler.Unsubscribe)
+	authenticated.POST("/push/test", pushHandler.SendTest)
+
+	authenticated.GET("/digest", digestHandler.GetSubscription)
+	authenticated.PUT("/digest", digestHandler.UpdateSubscription)
+	authenticated.POST("/digest/test", digestHandler.TestDigest)
+	authenticated.GET("/digest/deliveries", digestHandler.ListDeliveries)
+
+	authenticated.GET("/notifications", notificationHandler.ListNotifications)
+	authenticated.POST("/notifications/read-all", notificationHandler.MarkAllRead)
+	authenticated.POST("/notifications/:id/read", notificationHandler.MarkRead)
+
+	authenticated.GET("/webhooks", webhookHandler.ListWebhooks)
+	authenticated.POST("/webhooks", webhookHandler.CreateWebhook)
+	authenticated.GET("/webhooks/events", webhookHandler.ListEventTypes)
+	authenticated.PUT("/webhooks/:id", webhookHandler.UpdateWebhook)
+	authenticated.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+	authenticated.POST("/webhooks/:id/rotate-secret", webhookHandler.RotateSecret)
+	authenticated.POST("/webhooks/:id/test", webhookHandler.SendTestEvent)
+	authenticated.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+
+	authenticated.GET("/admin/jobs", adminHandler.ListJobs)
+	authenticated.POST("/admin/jobs/:name/run", adminHandler.TriggerJob)
+	authenticated.GET("/admin/jobs/:name/runs", adminHandler.ListJobRuns)
+	authenticated.POST("/admin/announcements", adminHandler.Announce)
+
+	authenticated.GET("/goals/:id/temptations", temptationHandler.ListTemptations)
+	authenticated.POST("/goals/:id/temptations", temptationHandler.CreateTemptation)
+	authenticated.GET("/goals/:id/temptations/analytics", temptationHandler.TemptationAnalytics)
+	authenticated.POST("/goals/:id/temptations/check-in", temptationHandler.DeriveCheckIn)
+
+	authenticated.GET("/goals/:id/relapses", relapseHandler.ListRelapses)
+	authenticated.POST("/goals/:id/relapses", relapseHandler.CreateRelapse)
+	authenticated.DELETE("/goals/:id/relapses/:relapseId", relapseHandler.DeleteRelapse)
+
+	authenticated.GET("/tags", tagHandler.ListTags)
+	authenticated.POST("/tags", tagHandler.CreateTag)
+	authenticated.PUT("/tags/:id", tagHandler.UpdateTag)
+	authenticated.DELETE("/tags/:id", tagHandler.DeleteTag)
+	authenticated.PUT("/goals/:id/tags", tagHandler.SetGoalTags)
+
+	authenticated.GET("/templates", templateHandler.ListTemplates)
+	authenticated.DELETE("/templates/:id", templateHandler.DeleteTemplate)
+	authenticated.POST("/goals/:id/template", templateHandler.SaveGoalAsTemplate)
+	authenticated.POST("/goals/from-template/:id", templateHandler.CreateGoalFromTemplate)
+
 	authenticated.POST("/checkins", checkInHandler.CreateOrUpdateCheckIn)
 	authenticated.GET("/checkins", checkInHandler.ListCheckIns)
 	authenticated.GET("/checkins/summary", checkInHandler.GoalSummaries)
+	authenticated.GET("/checkins/summary/tags", checkInHandler.TagSummaries)
+	authenticated.GET("/checkins/ratings", checkInHandler.RatingAnalytics)
+	authenticated.GET("/stats/strength", strengthHandler.Dashboard)
+	authenticated.GET("/achievements", achievementHandler.ListAchievements)
+	authenticated.GET("/points", pointsHandler.GetBalance)
+	authenticated.GET("/points/history", pointsHandler.ListHistory)
+	authenticated.POST("/points/freezes", pointsHandler.SpendFreeze)
+
+	authenticated.GET("/routines", routineHandler.ListRoutines)
+	authenticated.POST("/routines", routineHandler.CreateRoutine)
+	authenticated.GET("/routines/:id", routineHandler.GetRoutine)
+	authenticated.PUT("/routines/:id", routineHandler.UpdateRoutine)
+	authenticated.DELETE("/routines/:id", routineHandler.DeleteRoutine)
+	authenticated.DELETE("/routines/:id/goals/:goalId", routineHandler.RemoveStep)
+	authenticated.POST("/routines/:id/check-in", routineHandler.CheckInRoutine)
+	authenticated.GET("/routines/:id/stats", routineHandler.RoutineStats)
+
+	authenticated.GET("/journal", journalHandler.ListEntries)
+	authenticated.POST("/journal", journalHandler.CreateEntry)
+	authenticated.GET("/journal/prompts", journalHandler.ListPrompts)
+	authenticated.GET("/journal/export", journalHandler.ExportEntries)
+	authenticated.GET("/journal/:date", journalHandler.GetEntry)
+	authenticated.PUT("/journal/:date", journalHandler.UpdateEntry)
+	authenticated.DELETE("/journal/:date", journalHandler.DeleteEntry)
+
+	authenticated.GET("/reports", reportHandler.ListSavedReports)
+	authenticated.GET("/reports/saved/:id", reportHandler.GetSavedReport)
+	authenticated.GET("/reports/year/:year", reportHandler.GetYearReview)
+	authenticated.GET("/reports/:period", reportHandler.GetReport)
+	authenticated.POST("/reports/:period", reportHandler.SaveReport)
+
+	authenticated.GET("/vacations", vacationHandler.ListVacations)
+	authenticated.POST("/vacations", vacationHandler.CreateVacation)
+	authenticated.DELETE("/vacations/:id", vacationHandler.DeleteVacation)
 }
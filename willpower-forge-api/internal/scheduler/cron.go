@@ -0,0 +1,211 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// descriptors maps the supported shorthands to their five-field form.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed cron expression. Each field is a bit set of the
+// values it matches.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record a day field starting with "*": when both
+	// day fields are restricted, a day matching either one matches, as in
+	// cron.
+	domStar, dowStar bool
+}
+
+type fieldBounds struct {
+	name     string
+	min, max int
+}
+
+// allHours is the hour field of "*".
+const allHours = 1<<24 - 1
+
+var (
+	minuteBounds = fieldBounds{"minute", 0, 59}
+	hourBounds   = fieldBounds{"hour", 0, 23}
+	domBounds    = fieldBounds{"day of month", 1, 31}
+	monthBounds  = fieldBounds{"month", 1, 12}
+	// Day of week accepts 7 as well as 0 for Sunday.
+	dowBounds = fieldBounds{"day of week", 0, 7}
+)
+
+// Parse parses a standard five-field cron expression ("minute hour
+// day-of-month month day-of-week") or one of the @hourly, @daily, @weekly,
+// @monthly and @yearly shorthands. Fields accept *, values, ranges (1-5),
+// steps (*/15, 1-30/5) and comma separated lists of these.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := descriptors[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("cron expression %q needs 5 fields, got %d", spec, len(fields))
+	}
+
+	var schedule Schedule
+	var err error
+	if schedule.minute, err = parseField(fields[0], minuteBounds); err != nil {
+		return Schedule{}, err
+	}
+	if schedule.hour, err = parseField(fields[1], hourBounds); err != nil {
+		return Schedule{}, err
+	}
+	if schedule.dom, err = parseField(fields[2], domBounds); err != nil {
+		return Schedule{}, err
+	}
+	if schedule.month, err = parseField(fields[3], monthBounds); err != nil {
+		return Schedule{}, err
+	}
+	if schedule.dow, err = parseField(fields[4], dowBounds); err != nil {
+		return Schedule{}, err
+	}
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	schedule.domStar = strings.HasPrefix(fields[2], "*")
+	schedule.dowStar = strings.HasPrefix(fields[4], "*")
+
+	return schedule, nil
+}
+
+func parseField(field string, bounds fieldBounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		partBits, err := parseRange(part, bounds)
+		if err != nil {
+			return 0, err
+		}
+		bits |= partBits
+	}
+	return bits, nil
+}
+
+func parseRange(part string, bounds fieldBounds) (uint64, error) {
+	invalid := fmt.Errorf("invalid %s %q", bounds.name, part)
+
+	rangePart, step := part, 1
+	if idx := strings.Index(part, "/"); idx >= 0 {
+		var err error
+		rangePart = part[:idx]
+		if step, err = strconv.Atoi(part[idx+1:]); err != nil || step < 1 {
+			return 0, invalid
+		}
+	}
+
+	low, high := bounds.min, bounds.max
+	switch {
+	case rangePart == "*":
+	case strings.Contains(rangePart, "-"):
+		ends := strings.SplitN(rangePart, "-", 2)
+		var err1, err2 error
+		low, err1 = strconv.Atoi(ends[0])
+		high, err2 = strconv.Atoi(ends[1])
+		if err1 != nil || err2 != nil || low > high {
+			return 0, invalid
+		}
+	default:
+		value, err := strconv.Atoi(rangePart)
+		if err != nil {
+			return 0, invalid
+		}
+		low = value
+		if strings.Contains(part, "/") {
+			// "5/15" means every 15 starting at 5.
+			high = bounds.max
+		} else {
+			high = value
+		}
+	}
+	if low < bounds.min || high > bounds.max {
+		return 0, fmt.Errorf("%s %q out of range %d-%d", bounds.name, part, bounds.min, bounds.max)
+	}
+
+	var bits uint64
+	for value := low; value <= high; value += step {
+		bits |= 1 << uint(value)
+	}
+	return bits, nil
+}
+
+// Next returns the first minute after t matched by the schedule, in t's
+// location. It returns the zero time if nothing matches within five years,
+// as for "0 0 30 2 *".
+//
+// Around daylight saving changes, times skipped when clocks go forward do
+// not match, and when clocks go back a schedule with fixed hours only
+// matches the first pass through the repeated hour.
+func (s Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = later(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = later(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = later(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc))
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		if s.hour != allHours && repeatedWallClock(t) {
+			t = later(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc))
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// later returns next, the start of a later hour, day or month than t. When
+// clocks go forward past that start, time.Date normalizes it to an hour
+// earlier, which may not be after t; the moment the clocks resume is used
+// instead.
+func later(t, next time.Time) time.Time {
+	if !next.After(t) {
+		return next.Add(time.Hour)
+	}
+	return next
+}
+
+// repeatedWallClock reports whether t's clock time already occurred an hour
+// earlier, because the clocks went back.
+func repeatedWallClock(t time.Time) bool {
+	earlier := t.Add(-time.Hour)
+	return earlier.Hour() == t.Hour() && earlier.Minute() == t.Minute()
+}
@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	tests := []struct {
+		spec, from, want string
+	}{
+		{"*/15 * * * *", "2026-10-19 10:07", "2026-10-19 10:15"},
+		{"*/15 * * * *", "2026-10-19 10:45", "2026-10-19 11:00"},
+		{"*/15 * * * *", "2026-10-19 10:15", "2026-10-19 10:30"},
+		{"5/15 * * * *", "2026-10-19 10:05", "2026-10-19 10:20"},
+		{"5/15 * * * *", "2026-10-19 10:50", "2026-10-19 11:05"},
+		{"0 0 * * 1-5/2", "2026-10-19 00:00", "2026-10-21 00:00"},
+		{"0 0 * * 1-5/2", "2026-10-23 00:00", "2026-10-26 00:00"},
+		{"0 9,17 * * *", "2026-10-19 09:00", "2026-10-19 17:00"},
+		{"0 0 * * 7", "2026-10-19 00:00", "2026-10-25 00:00"},
+		{"0 0 * * 0", "2026-10-19 00:00", "2026-10-25 00:00"},
+		{"@weekly", "2026-10-19 00:00", "2026-10-25 00:00"},
+		{"@monthly", "2026-10-19 00:00", "2026-11-01 00:00"},
+		{"@yearly", "2026-10-19 00:00", "2027-01-01 00:00"},
+		// Both day fields restricted: either one matches.
+		{"0 0 13 * 1", "2026-10-10 00:00", "2026-10-12 00:00"},
+		{"0 0 13 * 1", "2026-10-12 00:00", "2026-10-13 00:00"},
+		// One day field starting with *: both must match.
+		{"0 0 13 * *", "2026-10-10 00:00", "2026-10-13 00:00"},
+		{"0 0 * * 1", "2026-10-12 00:00", "2026-10-19 00:00"},
+		{"0 0 */2 * 1", "2026-10-12 00:00", "2026-10-19 00:00"},
+		{"0 0 29 2 *", "2026-10-19 00:00", "2028-02-29 00:00"},
+	}
+
+	for _, tt := range tests {
+		schedule, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.spec, err)
+			continue
+		}
+		from, _ := time.Parse("2006-01-02 15:04", tt.from)
+		if got := schedule.Next(from).Format("2006-01-02 15:04"); got != tt.want {
+			t.Errorf("%q after %s = %s, want %s", tt.spec, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleNeverMatches(t *testing.T) {
+	schedule, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Next = %v, want the zero time", next)
+	}
+}
+
+func TestParseRejectsInvalidExpressions(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+		"@reboot",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestScheduleNextAcrossDaylightSaving(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("load location: %v", err)
+	}
+	at := func(value string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", value, newYork)
+		if err != nil {
+			t.Fatalf("parse %s: %v", value, err)
+		}
+		return parsed
+	}
+	// Clocks go back from 02:00 EDT to 01:00 EST on 2026-11-01.
+	firstPass := at("2026-11-01 01:30")
+	secondPass := firstPass.Add(time.Hour)
+
+	tests := []struct {
+		name, spec string
+		from       time.Time
+		want       time.Time
+	}{
+		// Clocks go forward from 02:00 EST to 03:00 EDT on 2026-03-08.
+		{"skipped time does not run", "30 2 * * *", at("2026-03-08 00:00"), at("2026-03-09 02:30")},
+		{"hourly across the gap", "0 * * * *", at("2026-03-08 01:30"), at("2026-03-08 03:00")},
+		{"first pass of a repeated time", "30 1 * * *", at("2026-11-01 00:00"), firstPass},
+		{"repeated time runs once", "30 1 * * *", firstPass, at("2026-11-02 01:30")},
+		{"repeated last minute runs once", "59 1 * * *", firstPass.Add(29 * time.Minute), at("2026-11-02 01:59")},
+		{"every minute keeps running", "* * * * *", secondPass.Add(-time.Minute), secondPass},
+		{"hourly keeps running", "0 * * * *", at("2026-11-01 01:00"), at("2026-11-01 01:00").Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.spec, err)
+		}
+		if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%s: %q after %v = %v, want %v", tt.name, tt.spec, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleNextWhenMidnightIsSkipped(t *testing.T) {
+	santiago, err := time.LoadLocation("America/Santiago")
+	if err != nil {
+		t.Skipf("load location: %v", err)
+	}
+	// Clocks go forward from 00:00 to 01:00 on 2026-09-06.
+	from := time.Date(2026, 9, 5, 12, 0, 0, 0, santiago)
+
+	tests := map[string]time.Time{
+		"0 0 * * *":  time.Date(2026, 9, 7, 0, 0, 0, 0, santiago),
+		"0 12 * * *": time.Date(2026, 9, 6, 12, 0, 0, 0, santiago),
+		"0 * 6 9 *":  time.Date(2026, 9, 6, 1, 0, 0, 0, santiago),
+	}
+	for spec, want := range tests {
+		schedule, err := Parse(spec)
+		if err != nil {
+			t.Fatalf("parse %q: %v", spec, err)
+		}
+		if got := schedule.Next(from); !got.Equal(want) {
+			t.Errorf("%q after %v = %v, want %v", spec, from, got, want)
+		}
+	}
+}
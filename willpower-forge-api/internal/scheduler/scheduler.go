@@ -0,0 +1,365 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+// runHistoryPerJob is how many runs are kept per job; older ones are pruned
+// after each run.
+const runHistoryPerJob = 500
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("job is already running")
+	ErrStopped     = errors.New("scheduler is stopped")
+)
+
+// Job is a named task run on a cron schedule, in the server's local time.
+type Job struct {
+	Name        string
+	Spec        string
+	Description string
+	// RunOnStart also runs the job when the scheduler starts, so work missed
+	// while the server was down is caught up.
+	RunOnStart bool
+	Run        func() error
+}
+
+// JobStatus describes a registered job for the admin API.
+type JobStatus struct {
+	Name        string         `json:"name"`
+	Spec        string         `json:"spec"`
+	Description string         `json:"description"`
+	Running     bool           `json:"running"`
+	NextRunAt   *time.Time     `json:"next_run_at"`
+	LastRun     *models.JobRun `json:"last_run"`
+}
+
+type entry struct {
+	job      Job
+	schedule Schedule
+	next     time.Time
+	running  bool
+}
+
+// Scheduler runs registered jobs on their schedules and records every run
+// in the job_runs table. A job never runs twice at once: a start while it is
+// still running is recorded as skipped.
+type Scheduler struct {
+	db *gorm.DB
+
+	mu      sync.Mutex
+	entries []*entry
+	started bool
+	stopped bool
+
+	stop chan struct{}
+	done chan struct{}
+	runs sync.WaitGroup
+}
+
+func New(db *gorm.DB) *Scheduler {
+	return &Scheduler{
+		db:   db,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// Register adds a job. Jobs must be registered before Start.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("job needs a name and a run function")
+	}
+	schedule, err := Parse(job.Spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return fmt.Errorf("job %s: cron expression %q never matches", job.Name, job.Spec)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("job %s: scheduler already started", job.Name)
+	}
+	if s.find(job.Name) != nil {
+		return fmt.Errorf("job %s is already registered", job.Name)
+	}
+	s.entries = append(s.entries, &entry{job: job, schedule: schedule})
+	return nil
+}
+
+// Start marks runs left unfinished by a previous process as failed, runs
+// the RunOnStart jobs and starts the background goroutine that runs jobs
+// when they are due.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return
+	}
+	s.started = true
+	now := time.Now()
+	for _, e := range s.entries {
+		e.next = e.schedule.Next(now)
+	}
+	s.mu.Unlock()
+
+	if err := s.db.Model(&models.JobRun{}).Where("status = ?", models.JobRunRunning).Updates(map[string]interface{}{
+		"status":      models.JobRunFailed,
+		"error":       "interrupted by server shutdown",
+		"finished_at": now,
+	}).Error; err != nil {
+		log.Printf("Error closing interrupted job runs: %v", err)
+	}
+
+	for _, e := range s.entries {
+		if e.job.RunOnStart {
+			s.launch(e, models.JobTriggerStartup)
+		}
+	}
+
+	go s.loop()
+	log.Printf("Job scheduler started with %d jobs", len(s.entries))
+}
+
+// Stop stops starting jobs and waits for running ones to finish or for ctx
+// to be done, whichever comes first.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopped = true
+	started := s.started
+	s.mu.Unlock()
+
+	if started {
+		close(s.stop)
+		<-s.done
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		log.Println("Job scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Trigger starts a job right away, outside its schedule.
+func (s *Scheduler) Trigger(name string) (models.JobRun, error) {
+	s.mu.Lock()
+	e := s.find(name)
+	s.mu.Unlock()
+	if e == nil {
+		return models.JobRun{}, ErrJobNotFound
+	}
+	return s.launch(e, models.JobTriggerManual)
+}
+
+// Jobs lists the registered jobs in registration order with their latest
+// run.
+func (s *Scheduler) Jobs() ([]JobStatus, error) {
+	s.mu.Lock()
+	statuses := make([]JobStatus, 0, len(s.entries))
+	for _, e := range s.entries {
+		status := JobStatus{
+			Name:        e.job.Name,
+			Spec:        e.job.Spec,
+			Description: e.job.Description,
+			Running:     e.running,
+		}
+		if !e.next.IsZero() && !s.stopped {
+			next := e.next
+			status.NextRunAt = &next
+		}
+		statuses = append(statuses, status)
+	}
+	s.mu.Unlock()
+
+	for i := range statuses {
+		var run models.JobRun
+		err := s.db.Where("job = ?", statuses[i].Name).Order("id DESC").First(&run).Error
+		if err == nil {
+			statuses[i].LastRun = &run
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	return statuses, nil
+}
+
+// Runs returns a job's run history, newest first, and its total size.
+func (s *Scheduler) Runs(name string, limit, offset int) ([]models.JobRun, int64, error) {
+	s.mu.Lock()
+	e := s.find(name)
+	s.mu.Unlock()
+	if e == nil {
+		return nil, 0, ErrJobNotFound
+	}
+
+	query := s.db.Model(&models.JobRun{}).Where("job = ?", name)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var runs []models.JobRun
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+func (s *Scheduler) loop() {
+	defer close(s.done)
+
+	for {
+		s.mu.Lock()
+		var wake time.Time
+		for _, e := range s.entries {
+			if !e.next.IsZero() && (wake.IsZero() || e.next.Before(wake)) {
+				wake = e.next
+			}
+		}
+		s.mu.Unlock()
+		if wake.IsZero() {
+			<-s.stop
+			return
+		}
+
+		timer := time.NewTimer(time.Until(wake))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case now := <-timer.C:
+			s.mu.Lock()
+			var due []*entry
+			for _, e := range s.entries {
+				if !e.next.IsZero() && !e.next.After(now) {
+					due = append(due, e)
+					e.next = e.schedule.Next(now)
+				}
+			}
+			s.mu.Unlock()
+
+			for _, e := range due {
+				s.launch(e, models.JobTriggerSchedule)
+			}
+		}
+	}
+}
+
+// launch starts a run of the job in its own goroutine unless the job is
+// still running, in which case scheduled starts are recorded as skipped.
+func (s *Scheduler) launch(e *entry, trigger string) (models.JobRun, error) {
+	now := time.Now()
+	run := models.JobRun{Job: e.job.Name, Trigger: trigger, StartedAt: now}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return run, ErrStopped
+	}
+	if e.running {
+		s.mu.Unlock()
+		if trigger != models.JobTriggerManual {
+			run.Status = models.JobRunSkipped
+			run.Error = "previous run still in progress"
+			run.FinishedAt = &now
+			if err := s.db.Create(&run).Error; err != nil {
+				log.Printf("Error recording skipped run of job %s: %v", e.job.Name, err)
+			}
+			log.Printf("Skipped job %s: previous run still in progress", e.job.Name)
+		}
+		return run, ErrJobRunning
+	}
+	e.running = true
+	s.runs.Add(1)
+	s.mu.Unlock()
+
+	run.Status = models.JobRunRunning
+	if err := s.db.Create(&run).Error; err != nil {
+		log.Printf("Error recording run of job %s: %v", e.job.Name, err)
+	}
+
+	go s.execute(e, run)
+	return run, nil
+}
+
+func (s *Scheduler) execute(e *entry, run models.JobRun) {
+	defer s.runs.Done()
+
+	err := runJob(e.job)
+	finished := time.Now()
+
+	s.mu.Lock()
+	e.running = false
+	s.mu.Unlock()
+
+	status, message := models.JobRunSucceeded, ""
+	if err != nil {
+		status, message = models.JobRunFailed, err.Error()
+		log.Printf("Job %s failed: %v", e.job.Name, err)
+	}
+
+	if run.ID == 0 {
+		return
+	}
+	if err := s.db.Model(&run).Updates(map[string]interface{}{
+		"status":      status,
+		"error":       message,
+		"finished_at": finished,
+	}).Error; err != nil {
+		log.Printf("Error recording result of job %s: %v", e.job.Name, err)
+	}
+
+	keep := s.db.Model(&models.JobRun{}).Select("id").Where("job = ?", e.job.Name).
+		Order("id DESC").Limit(runHistoryPerJob)
+	if err := s.db.Where("job = ? AND id NOT IN (?)", e.job.Name, keep).Delete(&models.JobRun{}).Error; err != nil {
+		log.Printf("Error pruning runs of job %s: %v", e.job.Name, err)
+	}
+}
+
+// runJob runs the job, turning a panic into an error so one broken job
+// cannot take down the server.
+func runJob(job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run()
+}
+
+func (s *Scheduler) find(name string) *entry {
+	for _, e := range s.entries {
+		if e.job.Name == name {
+			return e
+		}
+	}
+	return nil
+}
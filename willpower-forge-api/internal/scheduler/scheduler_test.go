@@ -0,0 +1,183 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+// blockingJob returns a job that signals started and then waits for
+// release.
+func blockingJob(name string) (Job, chan struct{}, chan struct{}) {
+	started, release := make(chan struct{}, 10), make(chan struct{})
+	return Job{
+		Name: name,
+		Spec: "0 0 1 1 *",
+		Run: func() error {
+			started <- struct{}{}
+			<-release
+			return nil
+		},
+	}, started, release
+}
+
+func waitFor(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+	}
+}
+
+func jobRuns(t *testing.T, db *gorm.DB, name string) []models.JobRun {
+	t.Helper()
+	var runs []models.JobRun
+	if err := db.Where("job = ?", name).Order("id ASC").Find(&runs).Error; err != nil {
+		t.Fatalf("load runs: %v", err)
+	}
+	return runs
+}
+
+func TestRegisterRejectsInvalidJobs(t *testing.T) {
+	jobs := New(testdb.Open(t))
+	run := func() error { return nil }
+
+	for _, job := range []Job{
+		{Spec: "* * * * *", Run: run},
+		{Name: "no-run", Spec: "* * * * *"},
+		{Name: "bad-spec", Spec: "61 * * * *", Run: run},
+		{Name: "never", Spec: "0 0 30 2 *", Run: run},
+	} {
+		if err := jobs.Register(job); err == nil {
+			t.Errorf("Register(%+v) succeeded, want an error", job)
+		}
+	}
+	if err := jobs.Register(Job{Name: "ok", Spec: "@daily", Run: run}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := jobs.Register(Job{Name: "ok", Spec: "@daily", Run: run}); err == nil {
+		t.Error("registering a name twice succeeded")
+	}
+}
+
+func TestOverlappingRunsAreSkipped(t *testing.T) {
+	db := testdb.Open(t)
+	jobs := New(db)
+	job, started, release := blockingJob("slow")
+	if err := jobs.Register(job); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	jobs.Start()
+	defer jobs.Stop(context.Background())
+
+	if _, err := jobs.Trigger("slow"); err != nil {
+		t.Fatalf("trigger: %v", err)
+	}
+	waitFor(t, started)
+
+	// A scheduled start while running is recorded as skipped; a manual one
+	// is refused without a record.
+	if _, err := jobs.launch(jobs.find("slow"), models.JobTriggerSchedule); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("scheduled start while running: got %v, want ErrJobRunning", err)
+	}
+	if _, err := jobs.Trigger("slow"); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("manual start while running: got %v, want ErrJobRunning", err)
+	}
+	statuses, err := jobs.Jobs()
+	if err != nil || len(statuses) != 1 || !statuses[0].Running {
+		t.Errorf("Jobs() = %+v, %v; want the job running", statuses, err)
+	}
+
+	close(release)
+	if err := jobs.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	runs := jobRuns(t, db, "slow")
+	if len(runs) != 2 {
+		t.Fatalf("got %d runs, want 2: %+v", len(runs), runs)
+	}
+	if runs[0].Trigger != models.JobTriggerManual || runs[0].Status != models.JobRunSucceeded || runs[0].FinishedAt == nil {
+		t.Errorf("first run = %+v, want a finished manual run", runs[0])
+	}
+	if runs[1].Trigger != models.JobTriggerSchedule || runs[1].Status != models.JobRunSkipped {
+		t.Errorf("second run = %+v, want a skipped scheduled run", runs[1])
+	}
+}
+
+func TestStopWaitsForRunningJobs(t *testing.T) {
+	db := testdb.Open(t)
+	jobs := New(db)
+	job, started, release := blockingJob("slow")
+	if err := jobs.Register(job); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	jobs.Start()
+	if _, err := jobs.Trigger("slow"); err != nil {
+		t.Fatalf("trigger: %v", err)
+	}
+	waitFor(t, started)
+
+	// Stop gives up when its context ends first.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := jobs.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("stop with a short deadline: got %v, want DeadlineExceeded", err)
+	}
+
+	// No new runs start once stopping.
+	if _, err := jobs.Trigger("slow"); !errors.Is(err, ErrStopped) {
+		t.Errorf("trigger after stop: got %v, want ErrStopped", err)
+	}
+	if statuses, _ := jobs.Jobs(); len(statuses) != 1 || statuses[0].NextRunAt != nil {
+		t.Errorf("Jobs() after stop = %+v, want no next run", statuses)
+	}
+
+	close(release)
+	jobs.runs.Wait()
+	if runs := jobRuns(t, db, "slow"); len(runs) != 1 || runs[0].Status != models.JobRunSucceeded {
+		t.Errorf("runs = %+v, want the running job to finish", runs)
+	}
+}
+
+func TestStartClosesInterruptedRunsAndRunsOnStart(t *testing.T) {
+	db := testdb.Open(t)
+	interrupted := models.JobRun{Job: "startup", Trigger: models.JobTriggerSchedule, Status: models.JobRunRunning, StartedAt: time.Now()}
+	if err := db.Create(&interrupted).Error; err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+
+	jobs := New(db)
+	ran := make(chan struct{}, 1)
+	if err := jobs.Register(Job{Name: "startup", Spec: "@yearly", RunOnStart: true, Run: func() error {
+		ran <- struct{}{}
+		return nil
+	}}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := jobs.Register(Job{Name: "panics", Spec: "@yearly", RunOnStart: true, Run: func() error {
+		panic("boom")
+	}}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	jobs.Start()
+	waitFor(t, ran)
+	if err := jobs.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	runs := jobRuns(t, db, "startup")
+	if len(runs) != 2 || runs[0].Status != models.JobRunFailed || runs[1].Trigger != models.JobTriggerStartup || runs[1].Status != models.JobRunSucceeded {
+		t.Errorf("runs = %+v, want the interrupted run failed and a startup run", runs)
+	}
+	if runs := jobRuns(t, db, "panics"); len(runs) != 1 || runs[0].Status != models.JobRunFailed || runs[0].Error != "panic: boom" {
+		t.Errorf("runs of a panicking job = %+v, want one failed run", runs)
+	}
+}
@@ -0,0 +1,428 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/notify"
+)
+
+// Metrics achievement rules can be defined on. Each is a single number
+// derived from the user's whole history.
+const (
+	// MetricCheckInDays counts days checked in, summed over goals.
+	MetricCheckInDays = "check_in_days"
+	// MetricBestStreak is the longest streak reached on any goal.
+	MetricBestStreak = "best_streak"
+	// MetricPerfectWeeks counts finished Monday-to-Sunday weeks in which
+	// every due goal was completed every day it was due.
+	MetricPerfectWeeks = "perfect_weeks"
+	// MetricComebacks counts goals that reached a streak of comebackStreak
+	// days after a failed day or a relapse.
+	MetricComebacks = "comebacks"
+	// MetricGoalsCompleted counts goals that reached their end date.
+	MetricGoalsCompleted = "goals_completed"
+	// MetricJournalEntries counts journal entries.
+	MetricJournalEntries = "journal_entries"
+)
+
+// comebackStreak is the streak that counts as recovered after a setback.
+const comebackStreak = 7
+
+// achievementRule unlocks a badge once Metric reaches Threshold. New badges
+// only need a new entry in achievementRules.
+type achievementRule struct {
+	Key         string
+	Metric      string
+	Threshold   int
+	Title       localizedText
+	Description localizedText
+}
+
+var achievementRules = []achievementRule{
+	{
+		Key: "first_check_in", Metric: MetricCheckInDays, Threshold: 1,
+		Title:       localizedText{LocaleEnglish: "First step", LocaleChinese: "第一步"},
+		Description: localizedText{LocaleEnglish: "Record your first check-in.", LocaleChinese: "完成第一次打卡。"},
+	},
+	{
+		Key: "check_in_days_100", Metric: MetricCheckInDays, Threshold: 100,
+		Title:       localizedText{LocaleEnglish: "Showing up", LocaleChinese: "坚持出席"},
+		Description: localizedText{LocaleEnglish: "Check in on 100 goal days.", LocaleChinese: "累计打卡 100 天。"},
+	},
+	{
+		Key: "streak_7", Metric: MetricBestStreak, Threshold: 7,
+		Title:       localizedText{LocaleEnglish: "One week strong", LocaleChinese: "坚持一周"},
+		Description: localizedText{LocaleEnglish: "Reach a 7-day streak.", LocaleChinese: "连续完成 7 天。"},
+	},
+	{
+		Key: "streak_30", Metric: MetricBestStreak, Threshold: 30,
+		Title:       localizedText{LocaleEnglish: "Habit forming", LocaleChinese: "习惯养成"},
+		Description: localizedText{LocaleEnglish: "Reach a 30-day streak.", LocaleChinese: "连续完成 30 天。"},
+	},
+	{
+		Key: "streak_100", Metric: MetricBestStreak, Threshold: 100,
+		Title:       localizedText{LocaleEnglish: "Unbreakable", LocaleChinese: "坚不可摧"},
+		Description: localizedText{LocaleEnglish: "Reach a 100-day streak.", LocaleChinese: "连续完成 100 天。"},
+	},
+	{
+		Key: "perfect_week", Metric: MetricPerfectWeeks, Threshold: 1,
+		Title:       localizedText{LocaleEnglish: "Perfect week", LocaleChinese: "完美一周"},
+		Description: localizedText{LocaleEnglish: "Complete every due goal for a whole week.", LocaleChinese: "一整周完成所有应做的目标。"},
+	},
+	{
+		Key: "comeback", Metric: MetricComebacks, Threshold: 1,
+		Title:       localizedText{LocaleEnglish: "Comeback", LocaleChinese: "东山再起"},
+		Description: localizedText{LocaleEnglish: "Build a 7-day streak after a setback.", LocaleChinese: "在挫折之后重新连续完成 7 天。"},
+	},
+	{
+		Key: "goal_completed", Metric: MetricGoalsCompleted, Threshold: 1,
+		Title:       localizedText{LocaleEnglish: "Finisher", LocaleChinese: "善始善终"},
+		Description: localizedText{LocaleEnglish: "See a goal through to its end date.", LocaleChinese: "坚持一个目标直到结束日期。"},
+	},
+	{
+		Key: "journal_7", Metric: MetricJournalEntries, Threshold: 7,
+		Title:       localizedText{LocaleEnglish: "Reflective", LocaleChinese: "善于反思"},
+		Description: localizedText{LocaleEnglish: "Write 7 journal entries.", LocaleChinese: "写下 7 篇日记。"},
+	},
+}
+
+// AchievementView is a badge in the requested locale with the user's
+// progress towards it.
+type AchievementView struct {
+	Key         string     `json:"key"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Metric      string     `json:"metric"`
+	Threshold   int        `json:"threshold"`
+	Progress    int        `json:"progress"`
+	Unlocked    bool       `json:"unlocked"`
+	UnlockedAt  *time.Time `json:"unlocked_at,omitempty"`
+}
+
+// AchievementService unlocks badges, adds them to the user's inbox and
+// announces them over Web Push when push is set.
+type AchievementService struct {
+	db            *gorm.DB
+	push          *PushService
+	notifications *NotificationService
+}
+
+func NewAchievementService(db *gorm.DB, push *PushService, notifications *NotificationService) *AchievementService {
+	return &AchievementService{db: db, push: push, notifications: notifications}
+}
+
+// Evaluate unlocks every badge whose rule the user now meets and returns the
+// ones unlocked by this call. Badges already unlocked stay unlocked and are
+// never returned twice, so it is safe to call after every check-in.
+func (s *AchievementService) Evaluate(userID uint, locale string) ([]AchievementView, error) {
+	metrics, err := s.metrics(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlocked := []AchievementView{}
+	now := time.Now()
+	for _, rule := range achievementRules {
+		if metrics[rule.Metric] < rule.Threshold {
+			continue
+		}
+
+		achievement := models.Achievement{UserID: userID, Key: rule.Key, UnlockedAt: now}
+		result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&achievement)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			unlocked = append(unlocked, rule.view(locale, metrics, &achievement.UnlockedAt))
+		}
+	}
+
+	for _, achievement := range unlocked {
+		if _, err := s.notifications.Notify(userID, NewNotification{
+			Kind:  models.NotificationAchievement,
+			Title: "🏆 " + achievement.Title,
+			Body:  achievement.Description,
+			Link:  "/",
+		}); err != nil {
+			log.Printf("Error adding achievement %s to user %d's inbox: %v", achievement.Key, userID, err)
+		}
+	}
+	if len(unlocked) > 0 && s.push != nil {
+		go s.announce(userID, unlocked)
+	}
+	return unlocked, nil
+}
+
+// announce pushes newly unlocked badges to the user's devices. Users without
+// subscriptions are skipped silently.
+func (s *AchievementService) announce(userID uint, unlocked []AchievementView) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for _, achievement := range unlocked {
+		_, err := s.push.SendToUser(ctx, userID, notify.Message{
+			Title: "🏆 " + achievement.Title,
+			Body:  achievement.Description,
+			Link:  "/",
+		}, "achievement-"+achievement.Key)
+		if errors.Is(err, ErrNoPushSubscriptions) {
+			return
+		}
+		if err != nil {
+			log.Printf("Error pushing achievement %s to user %d: %v", achievement.Key, userID, err)
+		}
+	}
+}
+
+// List returns the whole catalogue with the user's progress, after
+// evaluating it so badges added since the last check-in are caught up.
+func (s *AchievementService) List(userID uint, locale string) ([]AchievementView, error) {
+	if _, err := s.Evaluate(userID, locale); err != nil {
+		return nil, err
+	}
+
+	metrics, err := s.metrics(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var achievements []models.Achievement
+	if err := s.db.Where("user_id = ?", userID).Find(&achievements).Error; err != nil {
+		return nil, err
+	}
+	unlockedAt := make(map[string]time.Time, len(achievements))
+	for _, achievement := range achievements {
+		unlockedAt[achievement.Key] = achievement.UnlockedAt
+	}
+
+	views := make([]AchievementView, 0, len(achievementRules))
+	for _, rule := range achievementRules {
+		var at *time.Time
+		if value, ok := unlockedAt[rule.Key]; ok {
+			at = &value
+		}
+		views = append(views, rule.view(locale, metrics, at))
+	}
+	return views, nil
+}
+
+func (r achievementRule) view(locale string, metrics map[string]int, unlockedAt *time.Time) AchievementView {
+	progress := metrics[r.Metric]
+	if progress > r.Threshold {
+		progress = r.Threshold
+	}
+	return AchievementView{
+		Key:         r.Key,
+		Title:       r.Title.in(locale),
+		Description: r.Description.in(locale),
+		Metric:      r.Metric,
+		Threshold:   r.Threshold,
+		Progress:    progress,
+		Unlocked:    unlockedAt != nil,
+		UnlockedAt:  unlockedAt,
+	}
+}
+
+// metrics computes every achievement metric for the user. Each goal's share
+// is cached, so only goals whose history changed since the last call, or all
+// of them once the user's date moves on, are replayed.
+func (s *AchievementService) metrics(userID uint) (map[string]int, error) {
+	loc, err := UserLocationOf(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	today := time.Now().In(loc).Format(dateLayout)
+
+	var goals []models.Goal
+	if err := s.db.Preload("Pauses", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("StreakFreezes", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("user_id = ?", userID).Find(&goals).Error; err != nil {
+		return nil, err
+	}
+	var vacations []models.VacationPeriod
+	if err := s.db.Where("user_id = ?", userID).Order("id ASC").Find(&vacations).Error; err != nil {
+		return nil, err
+	}
+	checkInVersions, err := s.rowVersions(&models.CheckIn{}, userID)
+	if err != nil {
+		return nil, err
+	}
+	relapseVersions, err := s.rowVersions(&models.Relapse{}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var goalsCompleted, journalEntries int64
+	if err := s.db.Model(&models.GoalOutcome{}).Where("user_id = ?", userID).Count(&goalsCompleted).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&models.JournalEntry{}).Where("user_id = ?", userID).Count(&journalEntries).Error; err != nil {
+		return nil, err
+	}
+
+	goalIDs := make([]uint, 0, len(goals))
+	for _, goal := range goals {
+		goalIDs = append(goalIDs, goal.ID)
+	}
+	var cached []models.GoalAchievementStats
+	if len(goalIDs) > 0 {
+		if err := s.db.Where("goal_id IN ?", goalIDs).Find(&cached).Error; err != nil {
+			return nil, err
+		}
+	}
+	cachedByGoal := make(map[uint]models.GoalAchievementStats, len(cached))
+	for _, stats := range cached {
+		cachedByGoal[stats.GoalID] = stats
+	}
+
+	metrics := map[string]int{
+		MetricGoalsCompleted: int(goalsCompleted),
+		MetricJournalEntries: int(journalEntries),
+	}
+	dueWeeks := make(map[string]bool)
+	missedWeeks := make(map[string]bool)
+
+	for _, goal := range goals {
+		fingerprint := fmt.Sprintf("%s|%s|c%s|r%s", strengthFingerprint(goal, vacations), loc,
+			checkInVersions[goal.ID], relapseVersions[goal.ID])
+		stats, ok := cachedByGoal[goal.ID]
+		if !ok || stats.ComputedOn != today || stats.Fingerprint != fingerprint {
+			if stats, err = s.goalStats(goal, vacations, loc, today); err != nil {
+				return nil, err
+			}
+			stats.Fingerprint = fingerprint
+			if err := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&stats).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		metrics[MetricCheckInDays] += stats.CheckInDays
+		if stats.BestStreak > metrics[MetricBestStreak] {
+			metrics[MetricBestStreak] = stats.BestStreak
+		}
+		if stats.Comeback {
+			metrics[MetricComebacks]++
+		}
+		for _, week := range splitWeeks(stats.DueWeeks) {
+			dueWeeks[week] = true
+		}
+		for _, week := range splitWeeks(stats.MissedWeeks) {
+			missedWeeks[week] = true
+		}
+	}
+
+	for week := range dueWeeks {
+		if addDays(week, 6) < today && !missedWeeks[week] {
+			metrics[MetricPerfectWeeks]++
+		}
+	}
+
+	return metrics, nil
+}
+
+// rowVersions returns, per goal, the number of the user's rows of model and
+// their highest ID, which changes whenever one is added or removed.
+func (s *AchievementService) rowVersions(model interface{}, userID uint) (map[uint]string, error) {
+	var rows []struct {
+		GoalID uint
+		Count  int
+		MaxID  uint
+	}
+	if err := s.db.Model(model).Select("goal_id, COUNT(*) AS count, MAX(id) AS max_id").
+		Where("user_id = ?", userID).Group("goal_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	versions := make(map[uint]string, len(rows))
+	for _, row := range rows {
+		versions[row.GoalID] = fmt.Sprintf("%d:%d", row.Count, row.MaxID)
+	}
+	return versions, nil
+}
+
+// goalStats replays one goal's history up to today. Relapses are dated in
+// loc, the user's timezone.
+func (s *AchievementService) goalStats(goal models.Goal, vacations []models.VacationPeriod, loc *time.Location, today string) (models.GoalAchievementStats, error) {
+	var checkIns []models.CheckIn
+	if err := s.db.Where("goal_id = ?", goal.ID).Find(&checkIns).Error; err != nil {
+		return models.GoalAchievementStats{}, err
+	}
+	var relapses []models.Relapse
+	if err := s.db.Where("goal_id = ?", goal.ID).Find(&relapses).Error; err != nil {
+		return models.GoalAchievementStats{}, err
+	}
+
+	stats := models.GoalAchievementStats{GoalID: goal.ID, ComputedOn: today}
+	statuses := LatestStatusByDate(checkIns)
+	neutral := NeutralDates(goal, vacations)
+	start := goal.EffectiveStartDate()
+	end := today
+	if goal.EndDate != "" && goal.EndDate < end {
+		end = goal.EndDate
+	}
+
+	for _, status := range statuses {
+		if status != models.CheckInStatusMissed {
+			stats.CheckInDays++
+		}
+	}
+
+	stats.BestStreak = ComputeStreaks(statuses, start, end, neutral).LongestStreak
+
+	setback := ""
+	for date, status := range statuses {
+		if (status == "failed" || status == models.CheckInStatusMissed) && (setback == "" || date < setback) {
+			setback = date
+		}
+	}
+	for _, relapse := range relapses {
+		if date := relapse.OccurredAt.In(loc).Format(dateLayout); setback == "" || date < setback {
+			setback = date
+		}
+	}
+	stats.Comeback = setback != "" && setback < end &&
+		ComputeStreaks(statuses, addDays(setback, 1), end, neutral).LongestStreak >= comebackStreak
+
+	var due, missed []string
+	ForEachDate(start, end, func(date string) {
+		status := statuses[date]
+		if !judgeable(goal, date, neutral) || status == "excused" {
+			return
+		}
+		week := weekStart(date)
+		if len(due) == 0 || due[len(due)-1] != week {
+			due = append(due, week)
+		}
+		if status != "completed" && (len(missed) == 0 || missed[len(missed)-1] != week) {
+			missed = append(missed, week)
+		}
+	})
+	stats.DueWeeks = strings.Join(due, ",")
+	stats.MissedWeeks = strings.Join(missed, ",")
+
+	return stats, nil
+}
+
+func splitWeeks(weeks string) []string {
+	if weeks == "" {
+		return nil
+	}
+	return strings.Split(weeks, ",")
+}
+
+// weekStart returns the Monday of the week containing date.
+func weekStart(date string) string {
+	start, _, err := PeriodBounds(ReportPeriodWeek, date)
+	if err != nil {
+		return date
+	}
+	return start
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+func newTestAchievementService(db *gorm.DB) *AchievementService {
+	return NewAchievementService(db, nil, NewNotificationService(db, NewEventBroker()))
+}
+
+func createCheckIns(t *testing.T, db *gorm.DB, goal models.Goal, status string, start, end string) {
+	t.Helper()
+	ForEachDate(start, end, func(date string) {
+		checkIn := models.CheckIn{GoalID: goal.ID, UserID: goal.UserID, Date: date, Status: status}
+		if err := db.Create(&checkIn).Error; err != nil {
+			t.Fatalf("create check-in: %v", err)
+		}
+	})
+}
+
+func TestAchievementMetrics(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	first := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: "2026-03-02", EndDate: "2026-03-15"})
+	second := createTestGoal(t, db, models.Goal{UserID: user.ID, Title: "Walk", StartDate: "2026-03-02", EndDate: "2026-03-15"})
+
+	// Both goals are completed every day of the week of March 2nd; the
+	// second misses a day of the week after.
+	createCheckIns(t, db, first, "completed", "2026-03-02", "2026-03-15")
+	createCheckIns(t, db, second, "completed", "2026-03-02", "2026-03-10")
+	createCheckIns(t, db, second, "failed", "2026-03-11", "2026-03-11")
+	createCheckIns(t, db, second, "completed", "2026-03-12", "2026-03-15")
+
+	metrics, err := newTestAchievementService(db).metrics(user.ID)
+	if err != nil {
+		t.Fatalf("metrics: %v", err)
+	}
+	want := map[string]int{
+		MetricCheckInDays:  28,
+		MetricBestStreak:   14,
+		MetricPerfectWeeks: 1,
+		MetricComebacks:    0,
+	}
+	for metric, value := range want {
+		if metrics[metric] != value {
+			t.Errorf("%s = %d, want %d", metric, metrics[metric], value)
+		}
+	}
+}
+
+func TestAchievementMetricsOnlyReplayChangedGoals(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	first := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: "2026-03-02"})
+	second := createTestGoal(t, db, models.Goal{UserID: user.ID, Title: "Walk", StartDate: "2026-03-02"})
+	createCheckIns(t, db, first, "completed", "2026-03-02", "2026-03-04")
+	createCheckIns(t, db, second, "completed", "2026-03-02", "2026-03-03")
+	service := newTestAchievementService(db)
+
+	if _, err := service.metrics(user.ID); err != nil {
+		t.Fatalf("metrics: %v", err)
+	}
+	var cached int64
+	db.Model(&models.GoalAchievementStats{}).Count(&cached)
+	if cached != 2 {
+		t.Fatalf("cached stats = %d, want one per goal", cached)
+	}
+
+	// Marking both cached rows lets the test tell which goal is replayed.
+	db.Model(&models.GoalAchievementStats{}).Where("1 = 1").Update("check_in_days", 100)
+	createCheckIns(t, db, second, "completed", "2026-03-04", "2026-03-04")
+
+	metrics, err := service.metrics(user.ID)
+	if err != nil {
+		t.Fatalf("metrics: %v", err)
+	}
+	if metrics[MetricCheckInDays] != 103 {
+		t.Errorf("check-in days = %d, want 103 from the cached first goal and the replayed second", metrics[MetricCheckInDays])
+	}
+
+	// Removing a check-in invalidates the cache as well.
+	db.Where("goal_id = ? AND date = ?", first.ID, "2026-03-02").Delete(&models.CheckIn{})
+	if metrics, err = service.metrics(user.ID); err != nil {
+		t.Fatalf("metrics: %v", err)
+	}
+	if metrics[MetricCheckInDays] != 5 {
+		t.Errorf("check-in days = %d, want 5 after the deletion", metrics[MetricCheckInDays])
+	}
+}
+
+func TestAchievementMetricsDateRelapsesInUserTimezone(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{Timezone: "Asia/Tokyo"})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, Type: "I_WONT", StartDate: "2026-03-01"})
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("load timezone: %v", err)
+	}
+
+	// The relapse is on March 2nd in Tokyo but still March 1st in UTC, so
+	// only six clean days follow it.
+	relapse := models.Relapse{GoalID: goal.ID, UserID: user.ID, OccurredAt: time.Date(2026, 3, 2, 5, 0, 0, 0, tokyo)}
+	if err := db.Create(&relapse).Error; err != nil {
+		t.Fatalf("create relapse: %v", err)
+	}
+	createCheckIns(t, db, goal, "completed", "2026-03-02", "2026-03-08")
+
+	metrics, err := newTestAchievementService(db).metrics(user.ID)
+	if err != nil {
+		t.Fatalf("metrics: %v", err)
+	}
+	if metrics[MetricComebacks] != 0 {
+		t.Errorf("comebacks = %d, want 0 with six days after the relapse", metrics[MetricComebacks])
+	}
+
+	createCheckIns(t, db, goal, "completed", "2026-03-09", "2026-03-09")
+	if metrics, err = newTestAchievementService(db).metrics(user.ID); err != nil {
+		t.Fatalf("metrics: %v", err)
+	}
+	if metrics[MetricComebacks] != 1 {
+		t.Errorf("comebacks = %d, want 1 after a seventh clean day", metrics[MetricComebacks])
+	}
+}
+
+func TestEvaluateUnlocksOnce(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: "2026-03-02"})
+	createCheckIns(t, db, goal, "completed", "2026-03-02", "2026-03-08")
+	service := newTestAchievementService(db)
+
+	unlocked, err := service.Evaluate(user.ID, LocaleEnglish)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	keys := make(map[string]bool, len(unlocked))
+	for _, achievement := range unlocked {
+		keys[achievement.Key] = true
+	}
+	for _, key := range []string{"first_check_in", "streak_7", "perfect_week"} {
+		if !keys[key] {
+			t.Errorf("%s was not unlocked, got %v", key, keys)
+		}
+	}
+
+	again, err := service.Evaluate(user.ID, LocaleEnglish)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("second evaluation unlocked %d badges, want none", len(again))
+	}
+}
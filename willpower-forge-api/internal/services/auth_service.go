@@ -16,8 +16,23 @@ import (
 var (
 	ErrUserExists        = errors.New("user already exists")
 	ErrInvalidCredential = errors.New("invalid credentials")
+	ErrInvalidTimezone   = errors.New("invalid timezone")
+	ErrInvalidMissedDays = errors.New("invalid missed-day mode")
+	ErrInvalidGraceHours = errors.New("invalid missed-day grace hours")
 )
 
+// maxMissedDayGraceHours caps how long a finished day stays open for
+// check-ins before it is recorded as missed.
+const maxMissedDayGraceHours = 72
+
+// ProfileUpdate holds the profile settings to change; nil fields are left
+// as they are.
+type ProfileUpdate struct {
+	Timezone            *string
+	MissedDays          *string
+	MissedDayGraceHours *int
+}
+
 type AuthService struct {
 	db        *gorm.DB
 	jwtSecret []byte
@@ -83,3 +98,45 @@ func (s *AuthService) LoginUser(username, password string) (string, uint, error)
 
 	return tokenString, user.ID, nil
 }
+
+// GetUser loads the user's account.
+func (s *AuthService) GetUser(userID uint) (models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// UpdateProfile changes the user's settings. An empty timezone resets it to
+// the server's local time.
+func (s *AuthService) UpdateProfile(userID uint, update ProfileUpdate) (models.User, error) {
+	updates := make(map[string]interface{})
+	if update.Timezone != nil {
+		if *update.Timezone != "" {
+			if _, err := time.LoadLocation(*update.Timezone); err != nil {
+				return models.User{}, ErrInvalidTimezone
+			}
+		}
+		updates["timezone"] = *update.Timezone
+	}
+	if update.MissedDays != nil {
+		if *update.MissedDays != models.MissedDaysRecord && *update.MissedDays != models.MissedDaysIgnore {
+			return models.User{}, ErrInvalidMissedDays
+		}
+		updates["missed_days"] = *update.MissedDays
+	}
+	if update.MissedDayGraceHours != nil {
+		if *update.MissedDayGraceHours < 0 || *update.MissedDayGraceHours > maxMissedDayGraceHours {
+			return models.User{}, ErrInvalidGraceHours
+		}
+		updates["missed_day_grace_hours"] = *update.MissedDayGraceHours
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error; err != nil {
+			return models.User{}, err
+		}
+	}
+	return s.GetUser(userID)
+}
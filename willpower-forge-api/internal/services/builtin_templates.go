@@ -0,0 +1,225 @@
+package services
+
+import "willpower-forge-api/internal/models"
+
+// Supported locales, matching the frontend's vue-i18n messages.
+const (
+	LocaleEnglish = "en"
+	LocaleChinese = "zh"
+)
+
+// localizedText holds one string per supported locale.
+type localizedText map[string]string
+
+func (t localizedText) in(locale string) string {
+	if text, ok := t[locale]; ok {
+		return text
+	}
+	return t[LocaleEnglish]
+}
+
+type localizedIntention struct {
+	Cue    localizedText
+	Action localizedText
+}
+
+type builtinTemplate struct {
+	Key         string
+	Type        string
+	Schedule    string
+	Name        localizedText
+	Title       localizedText
+	Description localizedText
+	Motivation  localizedText
+	Intentions  []localizedIntention
+}
+
+func (t builtinTemplate) localize(locale string) TemplateView {
+	intentions := make([]models.TemplateIntention, 0, len(t.Intentions))
+	for _, intention := range t.Intentions {
+		intentions = append(intentions, models.TemplateIntention{
+			Cue:    intention.Cue.in(locale),
+			Action: intention.Action.in(locale),
+		})
+	}
+
+	return TemplateView{
+		ID:          t.Key,
+		BuiltIn:     true,
+		Name:        t.Name.in(locale),
+		Type:        t.Type,
+		Title:       t.Title.in(locale),
+		Schedule:    t.Schedule,
+		Description: t.Description.in(locale),
+		Motivation:  t.Motivation.in(locale),
+		Intentions:  intentions,
+	}
+}
+
+var builtinTemplates = []builtinTemplate{
+	{
+		Key:  "no-sugar",
+		Type: "I_WONT",
+		Name: localizedText{
+			LocaleEnglish: "No sugar",
+			LocaleChinese: "戒糖",
+		},
+		Title: localizedText{
+			LocaleEnglish: "I won't eat added sugar",
+			LocaleChinese: "我不吃添加糖",
+		},
+		Description: localizedText{
+			LocaleEnglish: "Skip sweets, sugary drinks and desserts. Fruit is fine.",
+			LocaleChinese: "不吃甜食、不喝含糖饮料、不吃甜点。水果可以吃。",
+		},
+		Motivation: localizedText{
+			LocaleEnglish: "Steadier energy through the day and fewer cravings.",
+			LocaleChinese: "全天精力更稳定，嘴馋的次数更少。",
+		},
+		Intentions: []localizedIntention{
+			{
+				Cue:    localizedText{LocaleEnglish: "If I crave something sweet after lunch", LocaleChinese: "如果午饭后想吃甜的"},
+				Action: localizedText{LocaleEnglish: "then I will eat a piece of fruit", LocaleChinese: "那么我就吃一个水果"},
+			},
+			{
+				Cue:    localizedText{LocaleEnglish: "If someone offers me dessert", LocaleChinese: "如果有人请我吃甜点"},
+				Action: localizedText{LocaleEnglish: "then I will ask for tea instead", LocaleChinese: "那么我就改要一杯茶"},
+			},
+		},
+	},
+	{
+		Key:  "daily-meditation",
+		Type: "I_WILL",
+		Name: localizedText{
+			LocaleEnglish: "Daily meditation",
+			LocaleChinese: "每日冥想",
+		},
+		Title: localizedText{
+			LocaleEnglish: "I will meditate for 10 minutes",
+			LocaleChinese: "我要冥想 10 分钟",
+		},
+		Description: localizedText{
+			LocaleEnglish: "Sit quietly and follow your breath for ten minutes every day.",
+			LocaleChinese: "每天安静地坐下，专注呼吸十分钟。",
+		},
+		Motivation: localizedText{
+			LocaleEnglish: "A calmer mind makes every other goal easier.",
+			LocaleChinese: "平静的心让其他目标都更容易实现。",
+		},
+		Intentions: []localizedIntention{
+			{
+				Cue:    localizedText{LocaleEnglish: "If I have just finished my morning coffee", LocaleChinese: "如果我刚喝完早上的咖啡"},
+				Action: localizedText{LocaleEnglish: "then I will sit down and meditate", LocaleChinese: "那么我就坐下来冥想"},
+			},
+			{
+				Cue:    localizedText{LocaleEnglish: "If I think I don't have time today", LocaleChinese: "如果我觉得今天没时间"},
+				Action: localizedText{LocaleEnglish: "then I will do three minutes instead of none", LocaleChinese: "那么我就做三分钟而不是放弃"},
+			},
+		},
+	},
+	{
+		Key:      "morning-run",
+		Type:     "I_WILL",
+		Schedule: "mon,wed,fri",
+		Name: localizedText{
+			LocaleEnglish: "Morning run",
+			LocaleChinese: "晨跑",
+		},
+		Title: localizedText{
+			LocaleEnglish: "I will go for a morning run",
+			LocaleChinese: "我要去晨跑",
+		},
+		Description: localizedText{
+			LocaleEnglish: "Run for at least 20 minutes on Monday, Wednesday and Friday mornings.",
+			LocaleChinese: "每周一、三、五早上跑步至少 20 分钟。",
+		},
+		Motivation: localizedText{
+			LocaleEnglish: "Starting the day with movement sets the tone for everything else.",
+			LocaleChinese: "用运动开启一天，为其他事情定下基调。",
+		},
+		Intentions: []localizedIntention{
+			{
+				Cue:    localizedText{LocaleEnglish: "If my alarm goes off on a running day", LocaleChinese: "如果跑步日的闹钟响了"},
+				Action: localizedText{LocaleEnglish: "then I will put on the clothes I laid out the night before", LocaleChinese: "那么我就穿上前一晚准备好的衣服"},
+			},
+		},
+	},
+	{
+		Key:  "read-before-bed",
+		Type: "I_WILL",
+		Name: localizedText{
+			LocaleEnglish: "Read before bed",
+			LocaleChinese: "睡前阅读",
+		},
+		Title: localizedText{
+			LocaleEnglish: "I will read 20 pages before bed",
+			LocaleChinese: "我要睡前读 20 页书",
+		},
+		Description: localizedText{
+			LocaleEnglish: "Read a paper book in bed instead of looking at a screen.",
+			LocaleChinese: "在床上读纸质书，而不是看屏幕。",
+		},
+		Motivation: localizedText{
+			LocaleEnglish: "Better sleep and a book finished every few weeks.",
+			LocaleChinese: "睡得更好，每隔几周读完一本书。",
+		},
+		Intentions: []localizedIntention{
+			{
+				Cue:    localizedText{LocaleEnglish: "If I feel like scrolling after 10pm", LocaleChinese: "如果晚上 10 点后想刷手机"},
+				Action: localizedText{LocaleEnglish: "then I will read", LocaleChinese: "那么我就去看书"},
+			},
+		},
+	},
+	{
+		Key:  "no-late-night-phone",
+		Type: "I_WONT",
+		Name: localizedText{
+			LocaleEnglish: "No phone after 11pm",
+			LocaleChinese: "晚上 11 点后不用手机",
+		},
+		Title: localizedText{
+			LocaleEnglish: "I won't use my phone after 11pm",
+			LocaleChinese: "我晚上 11 点后不用手机",
+		},
+		Description: localizedText{
+			LocaleEnglish: "Charge the phone outside the bedroom and keep the last hour screen-free.",
+			LocaleChinese: "把手机放在卧室外充电，睡前一小时不看屏幕。",
+		},
+		Motivation: localizedText{
+			LocaleEnglish: "Falling asleep faster and waking up rested.",
+			LocaleChinese: "更快入睡，醒来精神饱满。",
+		},
+		Intentions: []localizedIntention{
+			{
+				Cue:    localizedText{LocaleEnglish: "If it is 11pm", LocaleChinese: "如果到了晚上 11 点"},
+				Action: localizedText{LocaleEnglish: "then I will plug my phone in in the kitchen", LocaleChinese: "那么我就把手机放到厨房充电"},
+			},
+		},
+	},
+	{
+		Key:  "learn-a-language",
+		Type: "I_WANT",
+		Name: localizedText{
+			LocaleEnglish: "Learn a language",
+			LocaleChinese: "学习一门语言",
+		},
+		Title: localizedText{
+			LocaleEnglish: "I want to hold a conversation in a new language",
+			LocaleChinese: "我想用一门新语言进行对话",
+		},
+		Description: localizedText{
+			LocaleEnglish: "Practice vocabulary and listening every day and speak with a partner weekly.",
+			LocaleChinese: "每天练习词汇和听力，每周和语伴对话一次。",
+		},
+		Motivation: localizedText{
+			LocaleEnglish: "Travel, new friends and a sharper mind.",
+			LocaleChinese: "旅行、结交新朋友，让头脑更敏锐。",
+		},
+		Intentions: []localizedIntention{
+			{
+				Cue:    localizedText{LocaleEnglish: "If I am on my commute", LocaleChinese: "如果我在通勤路上"},
+				Action: localizedText{LocaleEnglish: "then I will do one lesson instead of browsing", LocaleChinese: "那么我就学一课而不是随便刷手机"},
+			},
+		},
+	},
+}
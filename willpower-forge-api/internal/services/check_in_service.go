@@ -0,0 +1,221 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+var (
+	ErrGoalNotCheckable     = errors.New("goal cannot be checked in")
+	ErrExcuseReasonRequired = errors.New("excused check-in needs a reason")
+	ErrIntentionMismatch    = errors.New("intention does not belong to goal")
+	ErrRatingOutOfRange     = errors.New("rating out of range")
+	ErrNegativeAmount       = errors.New("amount is negative")
+	ErrCheckInDateInFuture  = errors.New("check-in date is in the future")
+	ErrCheckInOutsideGoal   = errors.New("check-in date is outside the goal's dates")
+)
+
+// CheckInInput carries the user-supplied fields of a new check-in. An empty
+// Date records the check-in for today in the user's timezone and nil
+// ratings are left unset.
+type CheckInInput struct {
+	Date         string
+	Status       string
+	ReviewNotes  string
+	ExcuseReason string
+	Amount       *float64
+	IntentionID  *uint
+	Mood         *int
+	Energy       *int
+	Stress       *int
+	Effort       *int
+}
+
+// CheckInListener is told about every check-in once it is stored.
+type CheckInListener interface {
+	CheckInRecorded(goal models.Goal, checkIn models.CheckIn)
+}
+
+// CheckInService records check-ins. Every way of creating a check-in goes
+// through RecordCheckIn or RecordMissed so they share the same validation,
+// award the same points and reach the same listeners.
+type CheckInService struct {
+	db        *gorm.DB
+	points    *PointsService
+	listeners []CheckInListener
+	// batch collects the check-ins recorded inside Batch until its
+	// transaction commits.
+	batch *[]recordedCheckIn
+}
+
+type recordedCheckIn struct {
+	goal    models.Goal
+	checkIn models.CheckIn
+}
+
+func NewCheckInService(db *gorm.DB, points *PointsService, listeners ...CheckInListener) *CheckInService {
+	return &CheckInService{db: db, points: points, listeners: listeners}
+}
+
+// Batch runs fn with a CheckInService recording inside one transaction, so
+// callers can create several check-ins atomically. Listeners hear about the
+// check-ins once the transaction has committed.
+func (s *CheckInService) Batch(fn func(checkIns *CheckInService) error) error {
+	var recorded []recordedCheckIn
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&CheckInService{db: tx, points: s.points, batch: &recorded})
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range recorded {
+		s.announce(r.goal, r.checkIn)
+	}
+	return nil
+}
+
+// announce tells the listeners about a stored check-in, or holds it back
+// until the surrounding Batch commits.
+func (s *CheckInService) announce(goal models.Goal, checkIn models.CheckIn) {
+	if s.batch != nil {
+		*s.batch = append(*s.batch, recordedCheckIn{goal: goal, checkIn: checkIn})
+		return
+	}
+	for _, listener := range s.listeners {
+		listener.CheckInRecorded(goal, checkIn)
+	}
+}
+
+// RecordCheckIn validates the input against the goal and stores a new
+// check-in for it together with the XP it earns. Only active goals accept
+// check-ins, and only for dates from the goal's start to its end date.
+func (s *CheckInService) RecordCheckIn(goal models.Goal, input CheckInInput) (models.CheckIn, error) {
+	if uncheckableReason(goal.Status) != "" {
+		return models.CheckIn{}, ErrGoalNotCheckable
+	}
+
+	excuseReason := strings.TrimSpace(input.ExcuseReason)
+	if input.Status != "excused" {
+		excuseReason = ""
+	} else if excuseReason == "" {
+		return models.CheckIn{}, ErrExcuseReasonRequired
+	}
+
+	for _, rating := range []*int{input.Mood, input.Energy, input.Stress, input.Effort} {
+		if rating != nil && (*rating < models.RatingMin || *rating > models.RatingMax) {
+			return models.CheckIn{}, ErrRatingOutOfRange
+		}
+	}
+
+	if input.Amount != nil && *input.Amount < 0 {
+		return models.CheckIn{}, ErrNegativeAmount
+	}
+
+	if input.IntentionID != nil {
+		var intention models.ImplementationIntention
+		if err := s.db.Where("id = ? AND goal_id = ?", *input.IntentionID, goal.ID).First(&intention).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.CheckIn{}, ErrIntentionMismatch
+			}
+			return models.CheckIn{}, err
+		}
+	}
+
+	today, err := UserToday(s.db, goal.UserID)
+	if err != nil {
+		return models.CheckIn{}, err
+	}
+	date := input.Date
+	if date == "" {
+		date = today
+	}
+	if date > today {
+		return models.CheckIn{}, ErrCheckInDateInFuture
+	}
+	if outsideGoalDates(goal, date) {
+		return models.CheckIn{}, ErrCheckInOutsideGoal
+	}
+
+	checkIn := models.CheckIn{
+		GoalID:       goal.ID,
+		UserID:       goal.UserID,
+		Date:         date,
+		Status:       input.Status,
+		ReviewNotes:  input.ReviewNotes,
+		ExcuseReason: excuseReason,
+		Amount:       input.Amount,
+		IntentionID:  input.IntentionID,
+		Mood:         input.Mood,
+		Energy:       input.Energy,
+		Stress:       input.Stress,
+		Effort:       input.Effort,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// A check-in made for a day after it was recorded as missed, such as
+		// a backdated one, replaces the missed marker.
+		if err := tx.Where("goal_id = ? AND date = ? AND status = ?", goal.ID, date, models.CheckInStatusMissed).
+			Delete(&models.CheckIn{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&checkIn).Error; err != nil {
+			return err
+		}
+		return s.points.WithTx(tx).AwardCheckIn(goal, checkIn)
+	})
+	if err != nil {
+		return models.CheckIn{}, err
+	}
+
+	s.announce(goal, checkIn)
+	return checkIn, nil
+}
+
+// uncheckableReason explains why goals in status do not accept check-ins,
+// or returns "" when they do. Only active goals are checked in.
+func uncheckableReason(status string) string {
+	switch status {
+	case models.GoalStatusActive:
+		return ""
+	case models.GoalStatusScheduled:
+		return "not started"
+	}
+	return status
+}
+
+// outsideGoalDates reports whether date falls before the goal's start date
+// or after its end date.
+func outsideGoalDates(goal models.Goal, date string) bool {
+	return date < goal.EffectiveStartDate() || (goal.EndDate != "" && date > goal.EndDate)
+}
+
+// RecordMissed records a missed check-in for the goal on date unless the day
+// already has a check-in, and reports whether it did. The check and insert
+// are a single statement, so a check-in made concurrently is never
+// shadowed. Missed check-ins earn no XP.
+func (s *CheckInService) RecordMissed(goal models.Goal, date string) (bool, error) {
+	now := time.Now()
+	result := s.db.Exec(
+		"INSERT INTO check_ins (goal_id, user_id, date, status, review_notes, excuse_reason, created_at, updated_at) "+
+			"SELECT ?, ?, ?, ?, '', '', ?, ? WHERE NOT EXISTS (SELECT 1 FROM check_ins WHERE goal_id = ? AND date = ?)",
+		goal.ID, goal.UserID, date, models.CheckInStatusMissed, now, now, goal.ID, date)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	var checkIn models.CheckIn
+	if err := s.db.Where("goal_id = ? AND date = ? AND status = ?", goal.ID, date, models.CheckInStatusMissed).
+		First(&checkIn).Error; err != nil {
+		return true, err
+	}
+	s.announce(goal, checkIn)
+	return true, nil
+}
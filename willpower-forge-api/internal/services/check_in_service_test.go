@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+// zoneAheadOfServer returns a timezone whose date is currently later than
+// the server's, or skips the test if there is none.
+func zoneAheadOfServer(t *testing.T) string {
+	t.Helper()
+	for _, zone := range []string{"Pacific/Kiritimati", "Pacific/Auckland", "Asia/Tokyo"} {
+		if TodayIn(zone) > time.Now().Format(dateLayout) {
+			return zone
+		}
+	}
+	t.Skip("no timezone is ahead of the server's date right now")
+	return ""
+}
+
+func TestRecordCheckInDefaultsToUserToday(t *testing.T) {
+	db := testdb.Open(t)
+	zone := zoneAheadOfServer(t)
+	user := createTestUser(t, db, models.User{Timezone: zone})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: "2026-01-01"})
+	checkIns := NewCheckInService(db, NewPointsService(db))
+
+	checkIn, err := checkIns.RecordCheckIn(goal, CheckInInput{Status: "completed"})
+	if err != nil {
+		t.Fatalf("record check-in: %v", err)
+	}
+	if want := TodayIn(zone); checkIn.Date != want {
+		t.Errorf("check-in date = %s, want the user's today %s", checkIn.Date, want)
+	}
+}
+
+func TestRecordCheckInRejectsFutureDates(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{Timezone: "Pacific/Pago_Pago"})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: "2026-01-01"})
+	checkIns := NewCheckInService(db, NewPointsService(db))
+
+	tomorrow := addDays(TodayIn(user.Timezone), 1)
+	_, err := checkIns.RecordCheckIn(goal, CheckInInput{Date: tomorrow, Status: "completed"})
+	if !errors.Is(err, ErrCheckInDateInFuture) {
+		t.Errorf("check-in for %s: got %v, want ErrCheckInDateInFuture", tomorrow, err)
+	}
+}
+
+type recordingListener struct {
+	checkIns []models.CheckIn
+}
+
+func (l *recordingListener) CheckInRecorded(goal models.Goal, checkIn models.CheckIn) {
+	l.checkIns = append(l.checkIns, checkIn)
+}
+
+func TestCheckInListeners(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{Timezone: "UTC"})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: "2026-01-01"})
+	listener := &recordingListener{}
+	checkIns := NewCheckInService(db, NewPointsService(db), listener)
+	today := TodayIn("UTC")
+
+	if _, err := checkIns.RecordCheckIn(goal, CheckInInput{Date: today, Status: "completed"}); err != nil {
+		t.Fatalf("record check-in: %v", err)
+	}
+	if created, err := checkIns.RecordMissed(goal, addDays(today, -1)); err != nil || !created {
+		t.Fatalf("record missed: %v, %v", created, err)
+	}
+	if len(listener.checkIns) != 2 || listener.checkIns[1].Status != models.CheckInStatusMissed || listener.checkIns[1].ID == 0 {
+		t.Fatalf("listener heard %+v, want the check-in and the missed marker", listener.checkIns)
+	}
+
+	// A rolled back batch is never announced; a committed one only once
+	// it commits.
+	failed := errors.New("roll back")
+	err := checkIns.Batch(func(batch *CheckInService) error {
+		if _, err := batch.RecordCheckIn(goal, CheckInInput{Date: addDays(today, -2), Status: "completed"}); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) || len(listener.checkIns) != 2 {
+		t.Fatalf("rolled back batch: err %v, listener heard %d check-ins", err, len(listener.checkIns))
+	}
+	err = checkIns.Batch(func(batch *CheckInService) error {
+		_, err := batch.RecordCheckIn(goal, CheckInInput{Date: addDays(today, -2), Status: "completed"})
+		if len(listener.checkIns) != 2 {
+			t.Error("check-in announced before its batch committed")
+		}
+		return err
+	})
+	if err != nil || len(listener.checkIns) != 3 {
+		t.Fatalf("committed batch: err %v, listener heard %d check-ins", err, len(listener.checkIns))
+	}
+}
+
+func TestRecordCheckInWithinGoalDates(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: "2026-03-02", EndDate: "2026-03-08"})
+	checkIns := NewCheckInService(db, NewPointsService(db))
+
+	tests := []struct {
+		date string
+		want error
+	}{
+		{"2026-03-01", ErrCheckInOutsideGoal},
+		{"2026-03-02", nil},
+		{"2026-03-08", nil},
+		{"2026-03-09", ErrCheckInOutsideGoal},
+	}
+	for _, tt := range tests {
+		_, err := checkIns.RecordCheckIn(goal, CheckInInput{Date: tt.date, Status: "completed"})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("check-in on %s: got %v, want %v", tt.date, err, tt.want)
+		}
+	}
+}
+
+func TestArchivedGoalsAreNotCheckable(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, Status: models.GoalStatusArchived, StartDate: "2026-01-01"})
+	checkIns := NewCheckInService(db, NewPointsService(db))
+
+	if _, err := checkIns.RecordCheckIn(goal, CheckInInput{Date: "2026-03-02", Status: "completed"}); !errors.Is(err, ErrGoalNotCheckable) {
+		t.Errorf("check-in on an archived goal: got %v, want ErrGoalNotCheckable", err)
+	}
+	if reason := routineSkipReason(goal, "2026-03-02"); reason != models.GoalStatusArchived {
+		t.Errorf("routine skip reason = %q, want %q", reason, models.GoalStatusArchived)
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"fmt"
 	"log"
 	"time"
 
@@ -16,24 +17,8 @@ func NewCleanupService(db *gorm.DB) *CleanupService {
 	return &CleanupService{db: db}
 }
 
-// StartScheduledCleanup starts a background goroutine that periodically cleans up old deleted goals
-func (s *CleanupService) StartScheduledCleanup() {
-	go func() {
-		ticker := time.NewTicker(24 * time.Hour) // Run once per day
-		defer ticker.Stop()
-
-		// Run immediately on start
-		s.CleanupOldDeletedGoals()
-
-		for range ticker.C {
-			s.CleanupOldDeletedGoals()
-		}
-	}()
-	log.Println("Scheduled cleanup service started")
-}
-
 // CleanupOldDeletedGoals permanently deletes goals that have been in recycle bin for more than 30 days
-func (s *CleanupService) CleanupOldDeletedGoals() {
+func (s *CleanupService) CleanupOldDeletedGoals() error {
 	thirtyDaysAgo := time.Now().AddDate(0, 0, -30)
 
 	var goals []models.Goal
@@ -42,13 +27,11 @@ func (s *CleanupService) CleanupOldDeletedGoals() {
 		Find(&goals)
 
 	if result.Error != nil {
-		log.Printf("Error finding old deleted goals: %v", result.Error)
-		return
+		return fmt.Errorf("finding old deleted goals: %w", result.Error)
 	}
 
 	if len(goals) == 0 {
-		log.Println("No old deleted goals to clean up")
-		return
+		return nil
 	}
 
 	// Permanently delete these goals
@@ -57,9 +40,9 @@ func (s *CleanupService) CleanupOldDeletedGoals() {
 		Delete(&models.Goal{})
 
 	if result.Error != nil {
-		log.Printf("Error permanently deleting old goals: %v", result.Error)
-		return
+		return fmt.Errorf("permanently deleting old goals: %w", result.Error)
 	}
 
 	log.Printf("Successfully cleaned up %d old deleted goals", result.RowsAffected)
+	return nil
 }
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+// digestText holds the digest emails' strings per locale. Entries with
+// verbs are printf formats.
+var digestText = map[string]localizedText{
+	"daily_subject": {
+		LocaleEnglish: "Today's goals – %s",
+		LocaleChinese: "今日目标 – %s",
+	},
+	"daily_intro": {
+		LocaleEnglish: "Good morning! Here is what is due today, %s:",
+		LocaleChinese: "早上好！以下是今天（%s）要完成的目标：",
+	},
+	"daily_empty": {
+		LocaleEnglish: "Nothing is due today. Enjoy the rest!",
+		LocaleChinese: "今天没有需要完成的目标，好好休息吧！",
+	},
+	"checked_in": {
+		LocaleEnglish: "checked in",
+		LocaleChinese: "已打卡",
+	},
+	"streak": {
+		LocaleEnglish: "%d-day streak",
+		LocaleChinese: "连续 %d 天",
+	},
+	"weekly_subject": {
+		LocaleEnglish: "Your week: %s – %s",
+		LocaleChinese: "每周回顾：%s – %s",
+	},
+	"weekly_intro": {
+		LocaleEnglish: "Overall completion last week: %s (the week before: %s).",
+		LocaleChinese: "上周总体完成率：%s（前一周：%s）。",
+	},
+	"weekly_empty": {
+		LocaleEnglish: "No goals were due last week.",
+		LocaleChinese: "上周没有需要完成的目标。",
+	},
+	"goal_line": {
+		LocaleEnglish: "%s: %d of %d days done, %s",
+		LocaleChinese: "%s：完成 %d/%d 天，%s",
+	},
+	"column_goal": {
+		LocaleEnglish: "Goal",
+		LocaleChinese: "目标",
+	},
+	"column_done": {
+		LocaleEnglish: "Done",
+		LocaleChinese: "完成",
+	},
+	"column_rate": {
+		LocaleEnglish: "Rate",
+		LocaleChinese: "完成率",
+	},
+	"column_streak": {
+		LocaleEnglish: "Streak",
+		LocaleChinese: "连续天数",
+	},
+	"best_day": {
+		LocaleEnglish: "Best day: %s (%d of %d done)",
+		LocaleChinese: "最佳一天：%s（完成 %d/%d）",
+	},
+	"open_app": {
+		LocaleEnglish: "Open Willpower Forge",
+		LocaleChinese: "打开 Willpower Forge",
+	},
+	"footer": {
+		LocaleEnglish: "You get this email because you turned on digests in Willpower Forge.",
+		LocaleChinese: "你收到这封邮件是因为你在 Willpower Forge 中开启了摘要邮件。",
+	},
+	"unsubscribe": {
+		LocaleEnglish: "Unsubscribe",
+		LocaleChinese: "退订",
+	},
+}
+
+func digestStrings(locale string) map[string]string {
+	texts := make(map[string]string, len(digestText))
+	for key, text := range digestText {
+		texts[key] = text.in(locale)
+	}
+	return texts
+}
+
+// digestRow is one goal's line in the weekly summary.
+type digestRow struct {
+	Title  string
+	Done   int
+	Judged int
+	Rate   string
+	Streak int
+}
+
+// digestView is what the digest templates render. S holds the strings in
+// the email's locale.
+type digestView struct {
+	S              map[string]string
+	Daily          *DailyDigest
+	Weekly         *ReportData
+	Rows           []digestRow
+	AppURL         string
+	UnsubscribeURL string
+}
+
+const digestTextFooter = `{{define "footer"}}
+--
+{{.S.footer}}
+{{.S.unsubscribe}}: {{.UnsubscribeURL}}
+{{end}}`
+
+var dailyTextTemplate = texttemplate.Must(texttemplate.New("daily").Parse(digestTextFooter + `{{if .Daily.Goals}}{{printf .S.daily_intro .Daily.Date}}
+
+{{range .Daily.Goals}}- {{.Title}}{{if .CheckedIn}} ({{$.S.checked_in}}){{end}}{{if .CurrentStreak}} · {{printf $.S.streak .CurrentStreak}}{{end}}
+{{end}}{{else}}{{.S.daily_empty}}
+{{end}}
+{{.S.open_app}}: {{.AppURL}}
+{{template "footer" .}}`))
+
+var weeklyTextTemplate = texttemplate.Must(texttemplate.New("weekly").Funcs(texttemplate.FuncMap{
+	"rate": formatRate,
+}).Parse(digestTextFooter + `{{if .Rows}}{{printf .S.weekly_intro (rate .Weekly.CompletionRate) (rate .Weekly.PreviousCompletionRate)}}
+
+{{range .Rows}}- {{printf $.S.goal_line .Title .Done .Judged .Rate}}{{if .Streak}} · {{printf $.S.streak .Streak}}{{end}}
+{{end}}{{with .Weekly.BestDay}}
+{{printf $.S.best_day .Date .Completed .Due}}
+{{end}}{{else}}{{.S.weekly_empty}}
+{{end}}
+{{.S.open_app}}: {{.AppURL}}
+{{template "footer" .}}`))
+
+const digestHTMLLayout = `{{define "layout"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body style="font-family: sans-serif; max-width: 36rem; margin: 0 auto; padding: 1rem; color: #222;">
+<h1 style="font-size: 1.4rem;">{{.Title}}</h1>
+{{template "content" .View}}
+<p><a href="{{.View.AppURL}}" style="display: inline-block; padding: .5rem 1rem; background: #2563eb; color: #fff; text-decoration: none; border-radius: .4rem;">{{.View.S.open_app}}</a></p>
+<hr style="border: 0; border-top: 1px solid #ddd; margin-top: 2rem;">
+<p style="font-size: .8rem; color: #777;">{{.View.S.footer}} <a href="{{.View.UnsubscribeURL}}" style="color: #777;">{{.View.S.unsubscribe}}</a></p>
+</body>
+</html>
+{{end}}`
+
+var dailyHTMLTemplate = htmltemplate.Must(htmltemplate.New("daily").Parse(digestHTMLLayout + `{{define "content"}}{{if .Daily.Goals}}
+<p>{{printf .S.daily_intro .Daily.Date}}</p>
+<ul>
+{{range .Daily.Goals}}<li><strong>{{.Title}}</strong>{{if .CheckedIn}} ✓ {{$.S.checked_in}}{{end}}{{if .CurrentStreak}} <span style="color: #777;">· {{printf $.S.streak .CurrentStreak}}</span>{{end}}</li>
+{{end}}</ul>
+{{else}}
+<p>{{.S.daily_empty}}</p>
+{{end}}{{end}}`))
+
+var weeklyHTMLTemplate = htmltemplate.Must(htmltemplate.New("weekly").Funcs(htmltemplate.FuncMap{
+	"rate": formatRate,
+}).Parse(digestHTMLLayout + `{{define "content"}}{{if .Rows}}
+<p>{{printf .S.weekly_intro (rate .Weekly.CompletionRate) (rate .Weekly.PreviousCompletionRate)}}</p>
+<table style="border-collapse: collapse; width: 100%;">
+<tr><th align="left">{{.S.column_goal}}</th><th align="left">{{.S.column_done}}</th><th align="left">{{.S.column_rate}}</th><th align="left">{{.S.column_streak}}</th></tr>
+{{range .Rows}}<tr><td style="border-top: 1px solid #ddd; padding: .3rem 0;">{{.Title}}</td><td style="border-top: 1px solid #ddd;">{{.Done}}/{{.Judged}}</td><td style="border-top: 1px solid #ddd;">{{.Rate}}</td><td style="border-top: 1px solid #ddd;">{{.Streak}}</td></tr>
+{{end}}</table>
+{{with .Weekly.BestDay}}<p>{{printf $.S.best_day .Date .Completed .Due}}</p>{{end}}
+{{else}}
+<p>{{.S.weekly_empty}}</p>
+{{end}}{{end}}`))
+
+// renderDailyDigest renders a daily digest's subject, plain-text and HTML
+// bodies in the locale.
+func renderDailyDigest(digest DailyDigest, locale, appURL, unsubscribeURL string) (string, string, string, error) {
+	view := digestView{
+		S:              digestStrings(locale),
+		Daily:          &digest,
+		AppURL:         appURL,
+		UnsubscribeURL: unsubscribeURL,
+	}
+	subject := fmt.Sprintf(view.S["daily_subject"], digest.Date)
+	return renderDigest(subject, view, dailyTextTemplate, dailyHTMLTemplate)
+}
+
+// renderWeeklyDigest renders a weekly summary of the report's week in the
+// locale.
+func renderWeeklyDigest(report ReportData, locale, appURL, unsubscribeURL string) (string, string, string, error) {
+	view := digestView{
+		S:              digestStrings(locale),
+		Weekly:         &report,
+		AppURL:         appURL,
+		UnsubscribeURL: unsubscribeURL,
+	}
+	for _, goal := range report.Goals {
+		if goal.DueDays == 0 {
+			continue
+		}
+		view.Rows = append(view.Rows, digestRow{
+			Title:  goal.Title,
+			Done:   goal.Completed,
+			Judged: goal.Completed + goal.Partial + goal.Failed + goal.Missed,
+			Rate:   formatRate(goal.CompletionRate),
+			Streak: goal.StreakAfter,
+		})
+	}
+	subject := fmt.Sprintf(view.S["weekly_subject"], report.StartDate, report.EndDate)
+	return renderDigest(subject, view, weeklyTextTemplate, weeklyHTMLTemplate)
+}
+
+func renderDigest(subject string, view digestView, text *texttemplate.Template, html *htmltemplate.Template) (string, string, string, error) {
+	var textBody bytes.Buffer
+	if err := text.Execute(&textBody, view); err != nil {
+		return "", "", "", err
+	}
+	var htmlBody bytes.Buffer
+	err := html.ExecuteTemplate(&htmlBody, "layout", struct {
+		Title string
+		View  digestView
+	}{Title: subject, View: view})
+	if err != nil {
+		return "", "", "", err
+	}
+	return subject, textBody.String(), htmlBody.String(), nil
+}
+
+// Unsubscribe page states.
+const (
+	UnsubscribeConfirm = "confirm"
+	UnsubscribeDone    = "done"
+	UnsubscribeInvalid = "invalid"
+	UnsubscribeFailed  = "failed"
+)
+
+// UnsubscribePage is the page an email's unsubscribe link leads to. Token
+// and Kind are posted back when confirming.
+type UnsubscribePage struct {
+	Locale string
+	State  string
+	Token  string
+	Kind   string
+}
+
+var unsubscribeText = map[string]localizedText{
+	"title": {
+		LocaleEnglish: "Unsubscribe from digests",
+		LocaleChinese: "退订摘要邮件",
+	},
+	UnsubscribeConfirm: {
+		LocaleEnglish: "Stop receiving Willpower Forge digest emails?",
+		LocaleChinese: "确定不再接收 Willpower Forge 摘要邮件吗？",
+	},
+	"button": {
+		LocaleEnglish: "Unsubscribe",
+		LocaleChinese: "退订",
+	},
+	UnsubscribeDone: {
+		LocaleEnglish: "You will no longer receive these emails. You can turn digests back on in the app at any time.",
+		LocaleChinese: "你将不再收到这些邮件。你可以随时在应用中重新开启摘要邮件。",
+	},
+	UnsubscribeInvalid: {
+		LocaleEnglish: "This unsubscribe link is not valid.",
+		LocaleChinese: "此退订链接无效。",
+	},
+	UnsubscribeFailed: {
+		LocaleEnglish: "Something went wrong. Please try again later.",
+		LocaleChinese: "出了点问题，请稍后再试。",
+	},
+}
+
+var unsubscribeTemplate = htmltemplate.Must(htmltemplate.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.S.title}}</title>
+</head>
+<body style="font-family: sans-serif; max-width: 32rem; margin: 3rem auto; padding: 0 1rem; color: #222;">
+<h1 style="font-size: 1.4rem;">{{.S.title}}</h1>
+<p>{{index .S .Page.State}}</p>
+{{if eq .Page.State "confirm"}}<form method="post" action="?token={{.Page.Token}}{{if .Page.Kind}}&amp;kind={{.Page.Kind}}{{end}}">
+<button type="submit" style="padding: .5rem 1rem;">{{.S.button}}</button>
+</form>
+{{end}}</body>
+</html>
+`))
+
+// RenderUnsubscribePage renders the unsubscribe page in its locale.
+func RenderUnsubscribePage(page UnsubscribePage) (string, error) {
+	texts := make(map[string]string, len(unsubscribeText))
+	for key, text := range unsubscribeText {
+		texts[key] = text.in(page.Locale)
+	}
+
+	var buf bytes.Buffer
+	if err := unsubscribeTemplate.Execute(&buf, struct {
+		S    map[string]string
+		Page UnsubscribePage
+	}{S: texts, Page: page}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
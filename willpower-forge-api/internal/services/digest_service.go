@@ -0,0 +1,476 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/notify"
+)
+
+const (
+	// digestWindow is how late a daily digest may still go out after its
+	// send time; a morning digest arriving in the evening is no use.
+	digestWindow = 3 * time.Hour
+	// digestSendTimeout bounds a single digest delivery.
+	digestSendTimeout = 30 * time.Second
+)
+
+var (
+	ErrDigestUnavailable  = errors.New("digest emails are not configured")
+	ErrDigestNotFound     = errors.New("digest subscription not found")
+	ErrInvalidDigestEmail = errors.New("invalid digest email address")
+	ErrInvalidDigestTime  = errors.New("invalid digest send time")
+)
+
+// DigestGoal is one goal due on a daily digest's day. CurrentStreak is the
+// streak going into the day.
+type DigestGoal struct {
+	GoalID        uint
+	Title         string
+	CheckedIn     bool
+	CurrentStreak int
+}
+
+// DailyDigest lists the goals due on Date.
+type DailyDigest struct {
+	Date  string
+	Goals []DigestGoal
+}
+
+// DigestUpdate holds the subscription settings to change; nil fields are
+// left as they are.
+type DigestUpdate struct {
+	Email    *string
+	Locale   *string
+	Daily    *bool
+	Weekly   *bool
+	SendTime *string
+}
+
+// DigestService sends the opt-in daily and weekly digest emails and records
+// every delivery attempt.
+type DigestService struct {
+	db      *gorm.DB
+	mailer  notify.Mailer
+	reports *ReportService
+}
+
+// NewDigestService creates the service. Without a mailer digests cannot be
+// turned on.
+func NewDigestService(db *gorm.DB, mailer notify.Mailer, reports *ReportService) *DigestService {
+	return &DigestService{db: db, mailer: mailer, reports: reports}
+}
+
+// Get returns the user's subscription.
+func (s *DigestService) Get(userID uint) (models.DigestSubscription, error) {
+	var subscription models.DigestSubscription
+	if err := s.db.Where("user_id = ?", userID).First(&subscription).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return subscription, ErrDigestNotFound
+		}
+		return subscription, err
+	}
+	return subscription, nil
+}
+
+// Save creates or updates the user's subscription. Turning a digest on
+// needs a configured mailer and an email address. The first weekly summary
+// after turning it on covers the week that follows.
+func (s *DigestService) Save(userID uint, update DigestUpdate) (models.DigestSubscription, error) {
+	subscription, err := s.Get(userID)
+	if err != nil && !errors.Is(err, ErrDigestNotFound) {
+		return subscription, err
+	}
+	if errors.Is(err, ErrDigestNotFound) {
+		token, err := newUnsubscribeToken()
+		if err != nil {
+			return subscription, err
+		}
+		subscription = models.DigestSubscription{
+			UserID:           userID,
+			Locale:           LocaleEnglish,
+			SendTime:         "07:00",
+			UnsubscribeToken: token,
+		}
+	}
+
+	wasWeekly := subscription.Weekly
+	if update.Email != nil {
+		subscription.Email = ""
+		if email := strings.TrimSpace(*update.Email); email != "" {
+			address, err := mail.ParseAddress(email)
+			if err != nil {
+				return subscription, ErrInvalidDigestEmail
+			}
+			subscription.Email = address.Address
+		}
+	}
+	if update.Locale != nil {
+		subscription.Locale = NormalizeLocale(*update.Locale)
+	}
+	if update.SendTime != nil {
+		sendTime, err := time.Parse("15:04", strings.TrimSpace(*update.SendTime))
+		if err != nil {
+			return subscription, ErrInvalidDigestTime
+		}
+		subscription.SendTime = sendTime.Format("15:04")
+	}
+	if update.Daily != nil {
+		subscription.Daily = *update.Daily
+	}
+	if update.Weekly != nil {
+		subscription.Weekly = *update.Weekly
+	}
+
+	if subscription.Daily || subscription.Weekly {
+		if s.mailer == nil {
+			return subscription, ErrDigestUnavailable
+		}
+		if subscription.Email == "" {
+			return subscription, ErrInvalidDigestEmail
+		}
+	}
+
+	if subscription.Weekly && !wasWeekly {
+		var user models.User
+		if err := s.db.First(&user, userID).Error; err != nil {
+			return subscription, err
+		}
+		weekStart, _, err := PeriodBounds(ReportPeriodWeek, TodayIn(user.Timezone))
+		if err != nil {
+			return subscription, err
+		}
+		subscription.LastWeekStart = addDays(weekStart, -7)
+	}
+
+	if err := s.db.Save(&subscription).Error; err != nil {
+		return subscription, err
+	}
+	return subscription, nil
+}
+
+// Unsubscribe turns off the digests of the subscription with the token:
+// kind is "daily" or "weekly" for one of them, or empty for both.
+func (s *DigestService) Unsubscribe(token, kind string) (models.DigestSubscription, error) {
+	var subscription models.DigestSubscription
+	if token == "" {
+		return subscription, ErrDigestNotFound
+	}
+	if err := s.db.Where("unsubscribe_token = ?", token).First(&subscription).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return subscription, ErrDigestNotFound
+		}
+		return subscription, err
+	}
+
+	if kind != models.DigestWeekly {
+		subscription.Daily = false
+	}
+	if kind != models.DigestDaily {
+		subscription.Weekly = false
+	}
+	if err := s.db.Model(&subscription).Updates(map[string]interface{}{
+		"daily":  subscription.Daily,
+		"weekly": subscription.Weekly,
+	}).Error; err != nil {
+		return subscription, err
+	}
+	return subscription, nil
+}
+
+// RunDueDigests sends each subscriber's daily digest once its send time has
+// come in their timezone, within digestWindow, and their weekly summary of
+// the previous week from Monday's send time on. Each digest is claimed
+// before sending so it goes out at most once even if runs overlap. Daily
+// digests with nothing due and weekly ones without goals are skipped. Failed
+// sends are recorded as deliveries; the returned error only reports
+// subscriptions that could not be processed.
+func (s *DigestService) RunDueDigests(now time.Time) error {
+	if s.mailer == nil {
+		return nil
+	}
+
+	var subscriptions []models.DigestSubscription
+	if err := s.db.Where("daily = ? OR weekly = ?", true, true).Order("id").Find(&subscriptions).Error; err != nil {
+		return fmt.Errorf("loading digest subscriptions: %w", err)
+	}
+
+	failed := 0
+	for _, subscription := range subscriptions {
+		if err := s.runSubscription(subscription, now); err != nil {
+			log.Printf("Error sending digests of user %d: %v", subscription.UserID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("sending digests of %d users failed", failed)
+	}
+	return nil
+}
+
+func (s *DigestService) runSubscription(subscription models.DigestSubscription, now time.Time) error {
+	var user models.User
+	if err := s.db.First(&user, subscription.UserID).Error; err != nil {
+		return err
+	}
+
+	local := now.In(UserLocation(user.Timezone))
+	clock, err := time.Parse("15:04", subscription.SendTime)
+	if err != nil {
+		return ErrInvalidDigestTime
+	}
+	sendAt := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, local.Location())
+	today := local.Format(dateLayout)
+
+	if subscription.Daily && subscription.LastDailyDate < today &&
+		!local.Before(sendAt) && local.Sub(sendAt) < digestWindow {
+		claimed, err := s.claim(subscription.ID, "last_daily_date", today)
+		if err != nil {
+			return err
+		}
+		if claimed {
+			if _, err := s.sendDaily(subscription, today, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	weekStart, _, err := PeriodBounds(ReportPeriodWeek, today)
+	if err != nil {
+		return err
+	}
+	previousStart := addDays(weekStart, -7)
+	if subscription.Weekly && subscription.LastWeekStart < previousStart &&
+		(today != weekStart || !local.Before(sendAt)) {
+		claimed, err := s.claim(subscription.ID, "last_week_start", previousStart)
+		if err != nil {
+			return err
+		}
+		if claimed {
+			if _, err := s.sendWeekly(subscription, previousStart, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// claim advances one of the subscription's last-sent markers and reports
+// whether this call did so.
+func (s *DigestService) claim(subscriptionID uint, column, value string) (bool, error) {
+	result := s.db.Model(&models.DigestSubscription{}).
+		Where("id = ? AND "+column+" < ?", subscriptionID, value).
+		Update(column, value)
+	return result.RowsAffected > 0, result.Error
+}
+
+// SendTest sends the digest of the given kind right away: the daily digest
+// for today or the weekly summary of last week, even when empty.
+func (s *DigestService) SendTest(subscription models.DigestSubscription, kind string) (models.DigestDelivery, error) {
+	if s.mailer == nil {
+		return models.DigestDelivery{}, ErrDigestUnavailable
+	}
+	if subscription.Email == "" {
+		return models.DigestDelivery{}, ErrInvalidDigestEmail
+	}
+
+	var user models.User
+	if err := s.db.First(&user, subscription.UserID).Error; err != nil {
+		return models.DigestDelivery{}, err
+	}
+	today := TodayIn(user.Timezone)
+
+	var delivery *models.DigestDelivery
+	var err error
+	if kind == models.DigestWeekly {
+		weekStart, _, boundsErr := PeriodBounds(ReportPeriodWeek, today)
+		if boundsErr != nil {
+			return models.DigestDelivery{}, boundsErr
+		}
+		delivery, err = s.sendWeekly(subscription, addDays(weekStart, -7), true)
+	} else {
+		delivery, err = s.sendDaily(subscription, today, true)
+	}
+	if err != nil {
+		return models.DigestDelivery{}, err
+	}
+	return *delivery, nil
+}
+
+// Deliveries returns the user's latest digest deliveries, newest first.
+func (s *DigestService) Deliveries(userID uint, limit, offset int) ([]models.DigestDelivery, int64, error) {
+	query := s.db.Model(&models.DigestDelivery{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []models.DigestDelivery
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// sendDaily sends the daily digest of date. Unless test is set, nothing is
+// sent when no goal is due and the returned delivery is nil.
+func (s *DigestService) sendDaily(subscription models.DigestSubscription, date string, test bool) (*models.DigestDelivery, error) {
+	digest, err := s.dailyDigest(subscription.UserID, date)
+	if err != nil {
+		return nil, err
+	}
+	if len(digest.Goals) == 0 && !test {
+		return nil, nil
+	}
+
+	subject, text, html, err := renderDailyDigest(digest, subscription.Locale, PublicURL(), unsubscribeURL(subscription))
+	if err != nil {
+		return nil, err
+	}
+	return s.deliver(subscription, models.DigestDaily, date, subject, text, html, test)
+}
+
+// sendWeekly sends the weekly summary of the week starting on weekStart.
+// Unless test is set, nothing is sent when no goal was due that week and
+// the returned delivery is nil.
+func (s *DigestService) sendWeekly(subscription models.DigestSubscription, weekStart string, test bool) (*models.DigestDelivery, error) {
+	report, err := s.reports.Generate(subscription.UserID, ReportPeriodWeek, weekStart)
+	if err != nil {
+		return nil, err
+	}
+	due := 0
+	for _, goal := range report.Goals {
+		due += goal.DueDays
+	}
+	if due == 0 && !test {
+		return nil, nil
+	}
+
+	subject, text, html, err := renderWeeklyDigest(report, subscription.Locale, PublicURL(), unsubscribeURL(subscription))
+	if err != nil {
+		return nil, err
+	}
+	return s.deliver(subscription, models.DigestWeekly, weekStart, subject, text, html, test)
+}
+
+// deliver sends a rendered digest and records the attempt. A failed send is
+// not an error: it is recorded on the delivery for the user to see.
+func (s *DigestService) deliver(subscription models.DigestSubscription, kind, date, subject, text, html string, test bool) (*models.DigestDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), digestSendTimeout)
+	defer cancel()
+
+	unsubscribe := unsubscribeURL(subscription)
+	err := s.mailer.SendEmail(ctx, notify.Email{
+		To:      subscription.Email,
+		Subject: subject,
+		Text:    text,
+		HTML:    html,
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribe + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	})
+
+	delivery := models.DigestDelivery{
+		SubscriptionID: subscription.ID,
+		UserID:         subscription.UserID,
+		Kind:           kind,
+		Date:           date,
+		Email:          subscription.Email,
+		Status:         models.DeliveryStatusSent,
+		Test:           test,
+	}
+	if err != nil {
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.Error = err.Error()
+	}
+
+	if err := s.db.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// dailyDigest lists the user's active goals due on date, skipping paused,
+// frozen and vacation days.
+func (s *DigestService) dailyDigest(userID uint, date string) (DailyDigest, error) {
+	digest := DailyDigest{Date: date}
+
+	var goals []models.Goal
+	if err := s.db.Preload("Pauses").Preload("StreakFreezes").
+		Where("user_id = ? AND status = ?", userID, models.GoalStatusActive).
+		Order("created_at ASC").Find(&goals).Error; err != nil {
+		return digest, err
+	}
+	if len(goals) == 0 {
+		return digest, nil
+	}
+
+	var vacations []models.VacationPeriod
+	if err := s.db.Where("user_id = ?", userID).Find(&vacations).Error; err != nil {
+		return digest, err
+	}
+	var checkIns []models.CheckIn
+	if err := s.db.Where("user_id = ? AND date <= ?", userID, date).Find(&checkIns).Error; err != nil {
+		return digest, err
+	}
+	checkInsByGoal := make(map[uint][]models.CheckIn)
+	for _, checkIn := range checkIns {
+		checkInsByGoal[checkIn.GoalID] = append(checkInsByGoal[checkIn.GoalID], checkIn)
+	}
+
+	for _, goal := range goals {
+		neutral := NeutralDates(goal, vacations)
+		if !judgeable(goal, date, neutral) {
+			continue
+		}
+		statuses := LatestStatusByDate(checkInsByGoal[goal.ID])
+		status, checkedIn := statuses[date]
+		digest.Goals = append(digest.Goals, DigestGoal{
+			GoalID:        goal.ID,
+			Title:         goal.Title,
+			CheckedIn:     checkedIn && status != models.CheckInStatusMissed,
+			CurrentStreak: ComputeStreaks(statuses, goal.EffectiveStartDate(), date, neutral).CurrentStreak,
+		})
+	}
+	return digest, nil
+}
+
+// PublicURL is the address users reach the app at, used for links in
+// emails. It is taken from PUBLIC_URL and defaults to the local server.
+func PublicURL() string {
+	if public := strings.TrimRight(os.Getenv("PUBLIC_URL"), "/"); public != "" {
+		return public
+	}
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "5173"
+	}
+	return "http://localhost:" + port
+}
+
+func unsubscribeURL(subscription models.DigestSubscription) string {
+	return PublicURL() + "/api/v1/digest/unsubscribe?token=" + url.QueryEscape(subscription.UnsubscribeToken)
+}
+
+func newUnsubscribeToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
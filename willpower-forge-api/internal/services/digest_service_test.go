@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/notify"
+)
+
+// recordingMailer stands in for the mail server and keeps every email.
+type recordingMailer struct {
+	sent []notify.Email
+}
+
+func (m *recordingMailer) SendEmail(ctx context.Context, email notify.Email) error {
+	m.sent = append(m.sent, email)
+	return nil
+}
+
+func TestRunDueDigests(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{Timezone: "Asia/Tokyo"})
+	createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: "2026-02-23"})
+	idle := createTestUser(t, db, models.User{Username: "bob", Timezone: "Asia/Tokyo"})
+
+	// The weekly summary of the week of February 23rd already went out.
+	subscription := models.DigestSubscription{UserID: user.ID, Email: "alice@example.com", Daily: true, Weekly: true,
+		SendTime: "07:00", UnsubscribeToken: "alice-token", LastWeekStart: "2026-02-23"}
+	idleSubscription := models.DigestSubscription{UserID: idle.ID, Email: "bob@example.com", Daily: true,
+		SendTime: "07:00", UnsubscribeToken: "bob-token"}
+	for _, s := range []*models.DigestSubscription{&subscription, &idleSubscription} {
+		if err := db.Create(s).Error; err != nil {
+			t.Fatalf("create subscription: %v", err)
+		}
+	}
+	mailer := &recordingMailer{}
+	digests := NewDigestService(db, mailer, NewReportService(db))
+
+	type sent struct{ kind, date string }
+	deliveries := func() []sent {
+		t.Helper()
+		var rows []models.DigestDelivery
+		if err := db.Order("id ASC").Find(&rows).Error; err != nil {
+			t.Fatalf("load deliveries: %v", err)
+		}
+		list := make([]sent, 0, len(rows))
+		for _, row := range rows {
+			if row.UserID != user.ID || row.Status != models.DeliveryStatusSent {
+				t.Errorf("unexpected delivery %+v", row)
+			}
+			list = append(list, sent{row.Kind, row.Date})
+		}
+		return list
+	}
+
+	steps := []struct {
+		name string
+		at   time.Time
+		want []sent
+	}{
+		{"before the send time", time.Date(2026, 3, 3, 6, 59, 0, 0, tokyo), nil},
+		{"at the send time", time.Date(2026, 3, 3, 7, 5, 0, 0, tokyo), []sent{{"daily", "2026-03-03"}}},
+		{"again the same day", time.Date(2026, 3, 3, 7, 30, 0, 0, tokyo), []sent{{"daily", "2026-03-03"}}},
+		{"after the window", time.Date(2026, 3, 4, 10, 0, 0, 0, tokyo), []sent{{"daily", "2026-03-03"}}},
+		{"Monday before the send time", time.Date(2026, 3, 9, 6, 0, 0, 0, tokyo), []sent{{"daily", "2026-03-03"}}},
+		{"Monday at the send time", time.Date(2026, 3, 9, 7, 10, 0, 0, tokyo),
+			[]sent{{"daily", "2026-03-03"}, {"daily", "2026-03-09"}, {"weekly", "2026-03-02"}}},
+		{"Tuesday", time.Date(2026, 3, 10, 7, 10, 0, 0, tokyo),
+			[]sent{{"daily", "2026-03-03"}, {"daily", "2026-03-09"}, {"weekly", "2026-03-02"}, {"daily", "2026-03-10"}}},
+	}
+	for _, step := range steps {
+		if err := digests.RunDueDigests(step.at); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		got := deliveries()
+		if len(got) != len(step.want) {
+			t.Fatalf("%s: deliveries %v, want %v", step.name, got, step.want)
+		}
+		for idx := range got {
+			if got[idx] != step.want[idx] {
+				t.Fatalf("%s: deliveries %v, want %v", step.name, got, step.want)
+			}
+		}
+	}
+
+	if len(mailer.sent) != 4 {
+		t.Fatalf("sent %d emails, want 4", len(mailer.sent))
+	}
+	if header := mailer.sent[0].Headers["List-Unsubscribe"]; !strings.Contains(header, "token=alice-token") {
+		t.Errorf("List-Unsubscribe = %q, want the subscription's unsubscribe link", header)
+	}
+
+	// Nothing was due for bob, but his days are still claimed.
+	var stored models.DigestSubscription
+	db.First(&stored, idleSubscription.ID)
+	if stored.LastDailyDate != "2026-03-10" {
+		t.Errorf("idle subscription's last daily date = %q, want 2026-03-10", stored.LastDailyDate)
+	}
+}
+
+func TestDigestClaim(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	subscription := models.DigestSubscription{UserID: user.ID, Email: "alice@example.com", Daily: true, UnsubscribeToken: "token"}
+	db.Create(&subscription)
+	digests := NewDigestService(db, &recordingMailer{}, NewReportService(db))
+
+	// Two overlapping runs claim the same day; only one of them wins.
+	first, err := digests.claim(subscription.ID, "last_daily_date", "2026-03-03")
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	second, err := digests.claim(subscription.ID, "last_daily_date", "2026-03-03")
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if !first || second {
+		t.Errorf("claims = %v, %v, want only the first to succeed", first, second)
+	}
+	if older, _ := digests.claim(subscription.ID, "last_daily_date", "2026-03-02"); older {
+		t.Error("claimed an earlier day after a later one")
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	db.Create(&models.DigestSubscription{UserID: user.ID, Email: "alice@example.com", Daily: true, Weekly: true, UnsubscribeToken: "token"})
+	digests := NewDigestService(db, &recordingMailer{}, NewReportService(db))
+
+	for _, token := range []string{"", "wrong"} {
+		if _, err := digests.Unsubscribe(token, ""); !errors.Is(err, ErrDigestNotFound) {
+			t.Errorf("unsubscribe with %q: got %v, want ErrDigestNotFound", token, err)
+		}
+	}
+
+	subscription, err := digests.Unsubscribe("token", models.DigestDaily)
+	if err != nil {
+		t.Fatalf("unsubscribe: %v", err)
+	}
+	if subscription.Daily || !subscription.Weekly {
+		t.Errorf("after unsubscribing from daily: daily %v, weekly %v", subscription.Daily, subscription.Weekly)
+	}
+	if subscription, _ = digests.Unsubscribe("token", ""); subscription.Weekly {
+		t.Error("unsubscribing from everything kept the weekly summary")
+	}
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"willpower-forge-api/internal/models"
+)
+
+const (
+	// eventBufferSize is how many recent events, across all users, are kept
+	// for clients resuming with Last-Event-ID.
+	eventBufferSize = 1024
+	// eventSubscriberBuffer is how many events a stream may fall behind by
+	// before it is dropped. The client then reconnects and catches up from
+	// the buffer.
+	eventSubscriberBuffer = 64
+)
+
+// Stream event types, alongside the goal.* and checkin.recorded webhook
+// events.
+const (
+	EventGoalPurged            = "goal.purged"
+	EventNotificationCreated   = "notification.created"
+	EventNotificationAnnounced = "notification.announced"
+	EventNotificationRead      = "notification.read"
+	EventNotificationsReadAll  = "notification.read_all"
+	// EventReset tells a resuming client that events it missed are no
+	// longer buffered, so it should reload its data.
+	EventReset = "reset"
+)
+
+// StreamEvent is one change pushed to a user's event streams, or to every
+// user's when UserID is 0. Data is JSON.
+type StreamEvent struct {
+	ID     uint64
+	UserID uint
+	Type   string
+	Data   []byte
+}
+
+// EventBroker is the in-process pub/sub behind the event stream. Handlers
+// publish changes after they are written; every open stream of the user
+// receives them. Event IDs increase across all users and start from the
+// process start time, so IDs from before a restart are recognised as stale.
+type EventBroker struct {
+	mu          sync.Mutex
+	nextID      uint64
+	buffer      []StreamEvent
+	subscribers map[*EventSubscription]struct{}
+	closed      bool
+}
+
+func NewEventBroker() *EventBroker {
+	return &EventBroker{
+		nextID:      uint64(time.Now().UnixNano()),
+		subscribers: make(map[*EventSubscription]struct{}),
+	}
+}
+
+// EventSubscription is one open stream. Its channel is closed when the
+// subscription is closed, dropped for falling behind, or the broker shuts
+// down.
+type EventSubscription struct {
+	broker *EventBroker
+	userID uint
+	events chan StreamEvent
+}
+
+func (s *EventSubscription) Events() <-chan StreamEvent {
+	return s.events
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *EventSubscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
+
+// Subscribe opens a stream of the user's events. With resume, the events
+// after lastEventID still in the buffer are returned for replay; complete
+// is false when some may already have been dropped from it.
+func (b *EventBroker) Subscribe(userID uint, lastEventID uint64, resume bool) (sub *EventSubscription, replay []StreamEvent, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub = &EventSubscription{
+		broker: b,
+		userID: userID,
+		events: make(chan StreamEvent, eventSubscriberBuffer),
+	}
+	if b.closed {
+		close(sub.events)
+		return sub, nil, true
+	}
+	b.subscribers[sub] = struct{}{}
+
+	if !resume {
+		return sub, nil, true
+	}
+
+	oldest := b.nextID
+	if len(b.buffer) > 0 {
+		oldest = b.buffer[0].ID
+	}
+	complete = lastEventID+1 >= oldest && lastEventID < b.nextID
+	for _, event := range b.buffer {
+		if event.ID > lastEventID && (event.UserID == 0 || event.UserID == userID) {
+			replay = append(replay, event)
+		}
+	}
+	return sub, replay, complete
+}
+
+// Publish sends an event to the user's streams.
+func (b *EventBroker) Publish(userID uint, eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error encoding %s event: %v", eventType, err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event := StreamEvent{ID: b.nextID, UserID: userID, Type: eventType, Data: payload}
+	b.nextID++
+	if len(b.buffer) == eventBufferSize {
+		b.buffer = append(b.buffer[:0], b.buffer[1:]...)
+	}
+	b.buffer = append(b.buffer, event)
+
+	for sub := range b.subscribers {
+		if userID != 0 && sub.userID != userID {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(sub)
+		}
+	}
+}
+
+// PublishAll sends an event to every user's streams.
+func (b *EventBroker) PublishAll(eventType string, data interface{}) {
+	b.Publish(0, eventType, data)
+}
+
+// GoalEvent publishes a goal.* event carrying the goal.
+func (b *EventBroker) GoalEvent(event string, goal models.Goal) {
+	b.Publish(goal.UserID, event, goal)
+}
+
+// CheckInRecorded publishes checkin.recorded carrying the check-in.
+func (b *EventBroker) CheckInRecorded(goal models.Goal, checkIn models.CheckIn) {
+	b.Publish(checkIn.UserID, models.EventCheckInRecorded, checkIn)
+}
+
+// Close ends every stream and refuses new ones, so the server can shut down
+// without waiting for clients to disconnect.
+func (b *EventBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		b.remove(sub)
+	}
+}
+
+// remove closes the subscription's channel. b.mu must be held.
+func (b *EventBroker) remove(sub *EventSubscription) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.events)
+}
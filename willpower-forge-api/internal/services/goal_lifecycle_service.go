@@ -0,0 +1,303 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"willpower-forge-api/internal/models"
+)
+
+var ErrInvalidStatusTransition = errors.New("invalid goal status transition")
+
+// allowedTransitions lists the statuses a goal may move to from each status.
+var allowedTransitions = map[string][]string{
+	models.GoalStatusScheduled: {models.GoalStatusActive, models.GoalStatusArchived},
+	models.GoalStatusActive:    {models.GoalStatusPaused, models.GoalStatusCompleted, models.GoalStatusArchived},
+	models.GoalStatusPaused:    {models.GoalStatusActive, models.GoalStatusCompleted, models.GoalStatusArchived},
+	models.GoalStatusCompleted: {models.GoalStatusArchived},
+	models.GoalStatusArchived:  {models.GoalStatusActive},
+}
+
+type GoalLifecycleService struct {
+	db *gorm.DB
+}
+
+func NewGoalLifecycleService(db *gorm.DB) *GoalLifecycleService {
+	return &GoalLifecycleService{db: db}
+}
+
+// RunLifecycle performs a single pass of goal activation and completion.
+// It activates goals on their start date and completes them once their end
+// date has passed, both in the owner's timezone.
+func (s *GoalLifecycleService) RunLifecycle() error {
+	return s.run(time.Now())
+}
+
+func (s *GoalLifecycleService) run(now time.Time) error {
+	var users []models.User
+	if err := s.db.Select("id", "timezone").
+		Where("id IN (SELECT user_id FROM goals WHERE status IN ? AND deleted_at IS NULL)",
+			[]string{models.GoalStatusScheduled, models.GoalStatusActive, models.GoalStatusPaused}).
+		Find(&users).Error; err != nil {
+		return fmt.Errorf("loading users: %w", err)
+	}
+
+	failed := 0
+	for _, user := range users {
+		today := now.In(UserLocation(user.Timezone)).Format(dateLayout)
+		activateErr := s.ActivateDueGoals(user.ID, today)
+		completeErr := s.CompleteEndedGoals(user.ID, today)
+		for _, err := range []error{activateErr, completeErr} {
+			if err != nil {
+				log.Printf("Error running the goal lifecycle of user %d: %v", user.ID, err)
+			}
+		}
+		if activateErr != nil || completeErr != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("running the goal lifecycle of %d users failed", failed)
+	}
+	return nil
+}
+
+// ActivateDueGoals moves the user's scheduled goals whose start date has
+// arrived to active.
+func (s *GoalLifecycleService) ActivateDueGoals(userID uint, today string) error {
+	result := s.db.Model(&models.Goal{}).
+		Where("user_id = ? AND status = ? AND start_date <= ?", userID, models.GoalStatusScheduled, today).
+		Update("status", models.GoalStatusActive)
+
+	if result.Error != nil {
+		return fmt.Errorf("activating scheduled goals: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Activated %d scheduled goals", result.RowsAffected)
+	}
+	return nil
+}
+
+// CompleteEndedGoals closes the user's active and paused goals whose end
+// date is in the past.
+func (s *GoalLifecycleService) CompleteEndedGoals(userID uint, today string) error {
+	var goals []models.Goal
+	if err := s.db.Where("user_id = ? AND status IN ? AND end_date <> '' AND end_date < ?",
+		userID, []string{models.GoalStatusActive, models.GoalStatusPaused}, today).
+		Find(&goals).Error; err != nil {
+		return fmt.Errorf("finding ended goals: %w", err)
+	}
+
+	failed := 0
+	for i := range goals {
+		if err := s.Complete(&goals[i], goals[i].EndDate); err != nil {
+			log.Printf("Error completing goal %d: %v", goals[i].ID, err)
+			failed++
+		}
+	}
+
+	if len(goals) > failed {
+		log.Printf("Completed %d ended goals", len(goals)-failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("completing %d goals failed", failed)
+	}
+	return nil
+}
+
+// ChangeStatus moves a goal to a new status, opening or closing pause periods
+// and recording the final outcome as needed, dated in the owner's timezone.
+func (s *GoalLifecycleService) ChangeStatus(goal *models.Goal, status string) error {
+	if goal.Status == status {
+		return nil
+	}
+	if !transitionAllowed(goal.Status, status) {
+		return ErrInvalidStatusTransition
+	}
+
+	today, err := UserToday(s.db, goal.UserID)
+	if err != nil {
+		return err
+	}
+	if status == models.GoalStatusCompleted {
+		endDate := today
+		if goal.EndDate != "" && goal.EndDate < endDate {
+			endDate = goal.EndDate
+		}
+		return s.Complete(goal, endDate)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if goal.Status == models.GoalStatusPaused {
+			if err := closePause(tx, goal.ID, addDays(today, -1)); err != nil {
+				return err
+			}
+		}
+
+		updates := map[string]interface{}{"status": status}
+		if goal.Status == models.GoalStatusScheduled && status == models.GoalStatusActive && goal.StartDate > today {
+			updates["start_date"] = today
+		}
+
+		if status == models.GoalStatusPaused {
+			pause := models.GoalPause{
+				GoalID:    goal.ID,
+				UserID:    goal.UserID,
+				StartDate: today,
+			}
+			if err := tx.Create(&pause).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(goal).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		goal.Status = status
+		if startDate, ok := updates["start_date"].(string); ok {
+			goal.StartDate = startDate
+		}
+		return nil
+	})
+}
+
+// Complete marks the goal completed as of endDate and stores its outcome.
+func (s *GoalLifecycleService) Complete(goal *models.Goal, endDate string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := closePause(tx, goal.ID, endDate); err != nil {
+			return err
+		}
+
+		outcome, err := BuildGoalOutcome(tx, *goal, endDate)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "goal_id"}},
+			UpdateAll: true,
+		}).Create(&outcome).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(goal).Updates(map[string]interface{}{
+			"status":   models.GoalStatusCompleted,
+			"end_date": endDate,
+		}).Error; err != nil {
+			return err
+		}
+
+		goal.Status = models.GoalStatusCompleted
+		goal.EndDate = endDate
+		goal.Outcome = &outcome
+		return nil
+	})
+}
+
+// BuildGoalOutcome summarizes a goal's check-ins from its start date up to
+// and including endDate.
+func BuildGoalOutcome(db *gorm.DB, goal models.Goal, endDate string) (models.GoalOutcome, error) {
+	startDate := goal.EffectiveStartDate()
+
+	var checkIns []models.CheckIn
+	if err := db.Where("goal_id = ? AND date >= ? AND date <= ?", goal.ID, startDate, endDate).
+		Find(&checkIns).Error; err != nil {
+		return models.GoalOutcome{}, err
+	}
+
+	var pauses []models.GoalPause
+	if err := db.Where("goal_id = ?", goal.ID).Find(&pauses).Error; err != nil {
+		return models.GoalOutcome{}, err
+	}
+
+	var freezes []models.StreakFreeze
+	if err := db.Where("goal_id = ?", goal.ID).Find(&freezes).Error; err != nil {
+		return models.GoalOutcome{}, err
+	}
+
+	var vacations []models.VacationPeriod
+	if err := db.Where("user_id = ? AND end_date >= ? AND start_date <= ?", goal.UserID, startDate, endDate).
+		Find(&vacations).Error; err != nil {
+		return models.GoalOutcome{}, err
+	}
+
+	outcome := models.GoalOutcome{
+		GoalID:    goal.ID,
+		UserID:    goal.UserID,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+
+	goal.Pauses = pauses
+	goal.StreakFreezes = freezes
+	statuses := LatestStatusByDate(checkIns)
+	paused := PausedDates(pauses)
+	frozen := FrozenDates(freezes)
+	onVacation := VacationDates(vacations)
+
+	ForEachDate(startDate, endDate, func(date string) {
+		if !goal.IsDueOn(date) {
+			return
+		}
+		outcome.TotalDays++
+		if paused(date) {
+			outcome.PausedDays++
+			return
+		}
+		status := statuses[date]
+		switch {
+		case status == "completed":
+			outcome.Completed++
+		case status == "excused", onVacation(date), frozen(date):
+			outcome.Excused++
+		case status == "partial":
+			outcome.Partial++
+		case status == "failed":
+			outcome.Failed++
+		default:
+			outcome.Missed++
+		}
+	})
+
+	if trackedDays := outcome.TotalDays - outcome.PausedDays - outcome.Excused; trackedDays > 0 {
+		outcome.CompletionRate = float64(outcome.Completed) / float64(trackedDays)
+	}
+	outcome.LongestStreak = ComputeStreaks(statuses, startDate, endDate, NeutralDates(goal, vacations)).LongestStreak
+
+	return outcome, nil
+}
+
+// closePause ends the goal's open pause, if any, with endDate as its last
+// paused day. A pause that would end before it started is removed entirely.
+func closePause(tx *gorm.DB, goalID uint, endDate string) error {
+	var pause models.GoalPause
+	err := tx.Where("goal_id = ? AND end_date = ''", goalID).First(&pause).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if endDate < pause.StartDate {
+		return tx.Delete(&pause).Error
+	}
+	return tx.Model(&pause).Update("end_date", endDate).Error
+}
+
+func transitionAllowed(from, to string) bool {
+	for _, status := range allowedTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+func TestRunLifecycleUsesOwnerToday(t *testing.T) {
+	db := testdb.Open(t)
+	// 20:00 UTC on March 10th is already March 11th in Tokyo.
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC)
+	tokyo := createTestUser(t, db, models.User{Username: "tokyo", Timezone: "Asia/Tokyo"})
+	losAngeles := createTestUser(t, db, models.User{Username: "la", Timezone: "America/Los_Angeles"})
+	lifecycle := NewGoalLifecycleService(db)
+
+	type goals struct{ scheduled, ending models.Goal }
+	created := make(map[uint]goals)
+	for _, user := range []models.User{tokyo, losAngeles} {
+		created[user.ID] = goals{
+			scheduled: createTestGoal(t, db, models.Goal{UserID: user.ID, Status: models.GoalStatusScheduled, StartDate: "2026-03-11"}),
+			ending:    createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: "2026-03-01", EndDate: "2026-03-10"}),
+		}
+	}
+
+	if err := lifecycle.run(now); err != nil {
+		t.Fatalf("run lifecycle: %v", err)
+	}
+
+	tests := []struct {
+		user                     models.User
+		wantScheduled, wantEnded string
+	}{
+		{tokyo, models.GoalStatusActive, models.GoalStatusCompleted},
+		{losAngeles, models.GoalStatusScheduled, models.GoalStatusActive},
+	}
+	for _, tt := range tests {
+		var scheduled, ending models.Goal
+		db.First(&scheduled, created[tt.user.ID].scheduled.ID)
+		db.First(&ending, created[tt.user.ID].ending.ID)
+		if scheduled.Status != tt.wantScheduled {
+			t.Errorf("%s: goal starting March 11th is %s, want %s", tt.user.Timezone, scheduled.Status, tt.wantScheduled)
+		}
+		if ending.Status != tt.wantEnded {
+			t.Errorf("%s: goal ending March 10th is %s, want %s", tt.user.Timezone, ending.Status, tt.wantEnded)
+		}
+	}
+}
+
+func TestChangeStatusDatesPauseInOwnerToday(t *testing.T) {
+	db := testdb.Open(t)
+	zone := zoneAheadOfServer(t)
+	user := createTestUser(t, db, models.User{Timezone: zone})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: "2026-01-01"})
+	lifecycle := NewGoalLifecycleService(db)
+
+	if err := lifecycle.ChangeStatus(&goal, models.GoalStatusPaused); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	var pause models.GoalPause
+	if err := db.Where("goal_id = ?", goal.ID).First(&pause).Error; err != nil {
+		t.Fatalf("load pause: %v", err)
+	}
+	if want := TodayIn(zone); pause.StartDate != want {
+		t.Errorf("pause starts %s, want the user's today %s", pause.StartDate, want)
+	}
+
+	if err := lifecycle.ChangeStatus(&goal, models.GoalStatusCompleted); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if want := TodayIn(zone); goal.EndDate != want {
+		t.Errorf("completed goal ends %s, want the user's today %s", goal.EndDate, want)
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"willpower-forge-api/internal/models"
+)
+
+// GoalProgress reports how far a goal is through its milestones and
+// checklist. Every milestone and every checklist item weighs the same.
+type GoalProgress struct {
+	MilestonesTotal     int               `json:"milestones_total"`
+	MilestonesCompleted int               `json:"milestones_completed"`
+	ItemsTotal          int               `json:"items_total"`
+	ItemsDone           int               `json:"items_done"`
+	Percent             float64           `json:"percent"`
+	NextMilestone       *models.Milestone `json:"next_milestone,omitempty"`
+}
+
+// ComputeProgress derives a goal's progress from its milestones, in order,
+// and checklist items. The next milestone is the first open one.
+func ComputeProgress(milestones []models.Milestone, items []models.ChecklistItem) GoalProgress {
+	progress := GoalProgress{
+		MilestonesTotal: len(milestones),
+		ItemsTotal:      len(items),
+	}
+
+	for idx := range milestones {
+		if milestones[idx].CompletedAt != nil {
+			progress.MilestonesCompleted++
+		} else if progress.NextMilestone == nil {
+			next := milestones[idx]
+			next.Items = nil
+			progress.NextMilestone = &next
+		}
+	}
+	for _, item := range items {
+		if item.DoneAt != nil {
+			progress.ItemsDone++
+		}
+	}
+
+	if total := progress.MilestonesTotal + progress.ItemsTotal; total > 0 {
+		progress.Percent = float64(progress.MilestonesCompleted+progress.ItemsDone) * 100 / float64(total)
+	}
+	return progress
+}
+
+// TimelineEvent is one entry in a goal's history.
+type TimelineEvent struct {
+	Date   string    `json:"date"`
+	At     time.Time `json:"at"`
+	Type   string    `json:"type"`
+	Title  string    `json:"title"`
+	Status string    `json:"status,omitempty"`
+	RefID  uint      `json:"ref_id,omitempty"`
+}
+
+// BuildTimeline merges a goal's creation, check-ins, pauses, completed
+// milestones, finished checklist items and final outcome into one history,
+// newest first. Timestamps are dated in the user's location loc.
+func BuildTimeline(goal models.Goal, checkIns []models.CheckIn, milestones []models.Milestone, items []models.ChecklistItem, loc *time.Location) []TimelineEvent {
+	events := []TimelineEvent{{
+		Date:  goal.CreatedAt.In(loc).Format(dateLayout),
+		At:    goal.CreatedAt,
+		Type:  "goal_created",
+		Title: goal.Title,
+		RefID: goal.ID,
+	}}
+
+	for _, checkIn := range checkIns {
+		events = append(events, TimelineEvent{
+			Date:   checkIn.Date,
+			At:     checkIn.CreatedAt,
+			Type:   "check_in",
+			Title:  checkIn.ReviewNotes,
+			Status: checkIn.Status,
+			RefID:  checkIn.ID,
+		})
+	}
+
+	for _, pause := range goal.Pauses {
+		events = append(events, TimelineEvent{
+			Date:  pause.StartDate,
+			At:    pause.CreatedAt,
+			Type:  "paused",
+			RefID: pause.ID,
+		})
+		if pause.EndDate != "" {
+			events = append(events, TimelineEvent{
+				Date:  addDays(pause.EndDate, 1),
+				At:    pause.UpdatedAt,
+				Type:  "resumed",
+				RefID: pause.ID,
+			})
+		}
+	}
+
+	for _, milestone := range milestones {
+		if milestone.CompletedAt == nil {
+			continue
+		}
+		events = append(events, TimelineEvent{
+			Date:  milestone.CompletedAt.In(loc).Format(dateLayout),
+			At:    *milestone.CompletedAt,
+			Type:  "milestone_completed",
+			Title: milestone.Title,
+			RefID: milestone.ID,
+		})
+	}
+
+	for _, item := range items {
+		if item.DoneAt == nil {
+			continue
+		}
+		events = append(events, TimelineEvent{
+			Date:  item.DoneAt.In(loc).Format(dateLayout),
+			At:    *item.DoneAt,
+			Type:  "checklist_item_done",
+			Title: item.Title,
+			RefID: item.ID,
+		})
+	}
+
+	if goal.Outcome != nil {
+		events = append(events, TimelineEvent{
+			Date:  goal.Outcome.EndDate,
+			At:    goal.Outcome.CreatedAt,
+			Type:  "goal_completed",
+			Title: goal.Title,
+			RefID: goal.Outcome.ID,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Date != events[j].Date {
+			return events[i].Date > events[j].Date
+		}
+		return events[i].At.After(events[j].At)
+	})
+	return events
+}
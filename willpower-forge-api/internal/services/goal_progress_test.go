@@ -0,0 +1,79 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/models"
+)
+
+func TestComputeProgress(t *testing.T) {
+	done := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	milestones := []models.Milestone{
+		{ID: 1, Title: "Plan", CompletedAt: &done},
+		{ID: 2, Title: "Build", Items: []models.ChecklistItem{{ID: 9}}},
+		{ID: 3, Title: "Ship"},
+	}
+	items := []models.ChecklistItem{{ID: 7, DoneAt: &done}, {ID: 8, DoneAt: &done}, {ID: 9}}
+
+	progress := ComputeProgress(milestones, items)
+	if progress.MilestonesTotal != 3 || progress.MilestonesCompleted != 1 || progress.ItemsTotal != 3 || progress.ItemsDone != 2 {
+		t.Errorf("progress = %+v, want 1 of 3 milestones and 2 of 3 items", progress)
+	}
+	if progress.Percent != 50 {
+		t.Errorf("percent = %v, want 50", progress.Percent)
+	}
+	if progress.NextMilestone == nil || progress.NextMilestone.ID != 2 || progress.NextMilestone.Items != nil {
+		t.Errorf("next milestone = %+v, want Build without its items", progress.NextMilestone)
+	}
+
+	if empty := ComputeProgress(nil, nil); empty.Percent != 0 || empty.NextMilestone != nil {
+		t.Errorf("progress without milestones = %+v, want zero", empty)
+	}
+}
+
+func TestBuildTimeline(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	// 20:00 UTC is 05:00 the next day in Tokyo.
+	created := time.Date(2026, 2, 28, 20, 0, 0, 0, time.UTC)
+	completed := time.Date(2026, 3, 4, 20, 0, 0, 0, time.UTC)
+	itemDone := time.Date(2026, 3, 5, 9, 0, 0, 0, tokyo)
+	goal := models.Goal{
+		ID:        1,
+		Title:     "Write a book",
+		CreatedAt: created,
+		Pauses:    []models.GoalPause{{ID: 4, StartDate: "2026-03-02", EndDate: "2026-03-03", CreatedAt: created.Add(48 * time.Hour)}},
+		Outcome:   &models.GoalOutcome{ID: 5, EndDate: "2026-03-10", CreatedAt: completed.Add(144 * time.Hour)},
+	}
+	checkIns := []models.CheckIn{{ID: 6, Date: "2026-03-01", Status: "completed", CreatedAt: created.Add(24 * time.Hour)}}
+	milestones := []models.Milestone{
+		{ID: 2, Title: "Outline", CompletedAt: &completed},
+		{ID: 3, Title: "Draft"},
+	}
+	items := []models.ChecklistItem{{ID: 7, Title: "Pick a title", DoneAt: &itemDone}, {ID: 8, Title: "Find an editor"}}
+
+	events := BuildTimeline(goal, checkIns, milestones, items, tokyo)
+	want := []struct {
+		date, kind string
+		ref        uint
+	}{
+		{"2026-03-10", "goal_completed", 5},
+		{"2026-03-05", "checklist_item_done", 7},
+		{"2026-03-05", "milestone_completed", 2},
+		{"2026-03-04", "resumed", 4},
+		{"2026-03-02", "paused", 4},
+		{"2026-03-01", "check_in", 6},
+		{"2026-03-01", "goal_created", 1},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for idx, w := range want {
+		if got := events[idx]; got.Date != w.date || got.Type != w.kind || got.RefID != w.ref {
+			t.Errorf("event %d = %s %s #%d, want %s %s #%d", idx, got.Date, got.Type, got.RefID, w.date, w.kind, w.ref)
+		}
+	}
+}
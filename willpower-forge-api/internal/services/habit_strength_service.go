@@ -0,0 +1,333 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"willpower-forge-api/internal/models"
+)
+
+const (
+	// strengthAlpha is the weight of each new due day in the habit strength
+	// score. With 0.1, the last ten due days carry about two thirds of it.
+	strengthAlpha = 0.1
+	// strengthWindowDays covers the rolling rates and the weekday history
+	// used by the forecast.
+	strengthWindowDays = 56
+	// strengthTrendMargin is how far the 7-day rate must move away from the
+	// 30-day rate to count as rising or falling.
+	strengthTrendMargin = 0.1
+)
+
+// HabitStrength describes how established a goal's habit is. Score is the
+// exponentially weighted success over due days, from 0 to 100. Rates are nil
+// when no day in the window was due. TomorrowProbability is the forecast
+// chance of completing the goal tomorrow, nil when it is not due then.
+type HabitStrength struct {
+	GoalID              uint     `json:"goal_id"`
+	Title               string   `json:"title"`
+	Score               float64  `json:"score"`
+	Rate7               *float64 `json:"rate_7d"`
+	Rate30              *float64 `json:"rate_30d"`
+	Trend               string   `json:"trend"`
+	TomorrowDue         bool     `json:"tomorrow_due"`
+	TomorrowProbability *float64 `json:"tomorrow_probability"`
+}
+
+// StrengthDashboard summarizes habit strength across the user's active goals.
+type StrengthDashboard struct {
+	AverageScore float64         `json:"average_score"`
+	AtRisk       []uint          `json:"at_risk"`
+	Goals        []HabitStrength `json:"goals"`
+}
+
+type HabitStrengthService struct {
+	db *gorm.DB
+}
+
+func NewHabitStrengthService(db *gorm.DB) *HabitStrengthService {
+	return &HabitStrengthService{db: db}
+}
+
+// GoalStrength computes the habit strength of one goal as of the user's
+// today.
+func (s *HabitStrengthService) GoalStrength(goal models.Goal) (HabitStrength, error) {
+	today, err := UserToday(s.db, goal.UserID)
+	if err != nil {
+		return HabitStrength{}, err
+	}
+	var vacations []models.VacationPeriod
+	if err := s.db.Where("user_id = ?", goal.UserID).Order("id ASC").Find(&vacations).Error; err != nil {
+		return HabitStrength{}, err
+	}
+	if goal.Pauses == nil {
+		if err := s.db.Where("goal_id = ?", goal.ID).Order("id ASC").Find(&goal.Pauses).Error; err != nil {
+			return HabitStrength{}, err
+		}
+	}
+	if goal.StreakFreezes == nil {
+		if err := s.db.Where("goal_id = ?", goal.ID).Order("id ASC").Find(&goal.StreakFreezes).Error; err != nil {
+			return HabitStrength{}, err
+		}
+	}
+	return s.compute(goal, vacations, today)
+}
+
+// Dashboard computes the habit strength of every active goal of the user.
+// Goals whose forecast for tomorrow is below one half are listed as at risk.
+func (s *HabitStrengthService) Dashboard(userID uint) (StrengthDashboard, error) {
+	today, err := UserToday(s.db, userID)
+	if err != nil {
+		return StrengthDashboard{}, err
+	}
+	var goals []models.Goal
+	if err := s.db.Preload("Pauses", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("StreakFreezes", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("user_id = ? AND status = ?", userID, models.GoalStatusActive).
+		Order("created_at ASC").Find(&goals).Error; err != nil {
+		return StrengthDashboard{}, err
+	}
+
+	var vacations []models.VacationPeriod
+	if err := s.db.Where("user_id = ?", userID).Order("id ASC").Find(&vacations).Error; err != nil {
+		return StrengthDashboard{}, err
+	}
+
+	dashboard := StrengthDashboard{AtRisk: []uint{}, Goals: make([]HabitStrength, 0, len(goals))}
+	for _, goal := range goals {
+		strength, err := s.compute(goal, vacations, today)
+		if err != nil {
+			return StrengthDashboard{}, err
+		}
+		dashboard.Goals = append(dashboard.Goals, strength)
+		dashboard.AverageScore += strength.Score
+		if strength.TomorrowProbability != nil && *strength.TomorrowProbability < 0.5 {
+			dashboard.AtRisk = append(dashboard.AtRisk, goal.ID)
+		}
+	}
+	if len(goals) > 0 {
+		dashboard.AverageScore /= float64(len(goals))
+	}
+	return dashboard, nil
+}
+
+// compute advances the goal's cached score through the day before today,
+// the user's date, then adds the rolling rates and the forecast from the
+// recent window. Today only counts once it has a check-in and is never
+// cached, since it may still change.
+func (s *HabitStrengthService) compute(goal models.Goal, vacations []models.VacationPeriod, today string) (HabitStrength, error) {
+	yesterday := addDays(today, -1)
+	end := yesterday
+	if goal.EndDate != "" && goal.EndDate < end {
+		end = goal.EndDate
+	}
+	neutral := NeutralDates(goal, vacations)
+
+	snapshot, err := s.loadSnapshot(goal, vacations, end)
+	if err != nil {
+		return HabitStrength{}, err
+	}
+
+	if snapshot.ComputedThrough < end {
+		var checkIns []models.CheckIn
+		if err := s.db.Where("goal_id = ? AND date > ? AND date <= ?", goal.ID, snapshot.ComputedThrough, end).
+			Find(&checkIns).Error; err != nil {
+			return HabitStrength{}, err
+		}
+		snapshot.Score = advanceStrength(snapshot.Score, LatestStatusByDate(checkIns),
+			addDays(snapshot.ComputedThrough, 1), end, neutral)
+		snapshot.ComputedThrough = end
+
+		if err := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&snapshot).Error; err != nil {
+			return HabitStrength{}, err
+		}
+	}
+
+	var recent []models.CheckIn
+	if err := s.db.Where("goal_id = ? AND date >= ?", goal.ID, addDays(today, -strengthWindowDays)).
+		Find(&recent).Error; err != nil {
+		return HabitStrength{}, err
+	}
+	statuses := LatestStatusByDate(recent)
+
+	score := snapshot.Score
+	if _, checkedIn := statuses[today]; checkedIn && judgeable(goal, today, neutral) {
+		score = advanceStrength(score, statuses, today, today, neutral)
+	}
+
+	strength := HabitStrength{
+		GoalID: goal.ID,
+		Title:  goal.Title,
+		Score:  score * 100,
+		Rate7:  rollingRate(goal, statuses, addDays(today, -6), today, today, neutral),
+		Rate30: rollingRate(goal, statuses, addDays(today, -29), today, today, neutral),
+		Trend:  "steady",
+	}
+	if strength.Rate7 != nil && strength.Rate30 != nil {
+		switch diff := *strength.Rate7 - *strength.Rate30; {
+		case diff > strengthTrendMargin:
+			strength.Trend = "rising"
+		case diff < -strengthTrendMargin:
+			strength.Trend = "falling"
+		}
+	}
+
+	tomorrow := addDays(today, 1)
+	if goal.Status == models.GoalStatusActive && judgeable(goal, tomorrow, neutral) {
+		strength.TomorrowDue = true
+		probability := forecast(goal, statuses, score, strength.Rate7, today, tomorrow, neutral)
+		strength.TomorrowProbability = &probability
+	}
+
+	return strength, nil
+}
+
+// loadSnapshot returns the goal's cached score, or a fresh one starting the
+// day before the goal began if there is none or it has been invalidated.
+// A score computed past end includes a day the user has not finished, for
+// example after moving to a timezone further west, and is invalid too.
+func (s *HabitStrengthService) loadSnapshot(goal models.Goal, vacations []models.VacationPeriod, end string) (models.GoalStrength, error) {
+	fresh := models.GoalStrength{
+		GoalID:          goal.ID,
+		ComputedThrough: addDays(goal.EffectiveStartDate(), -1),
+		Fingerprint:     strengthFingerprint(goal, vacations),
+	}
+
+	var lastCheckInID uint
+	if err := s.db.Model(&models.CheckIn{}).Where("goal_id = ?", goal.ID).
+		Select("COALESCE(MAX(id), 0)").Scan(&lastCheckInID).Error; err != nil {
+		return models.GoalStrength{}, err
+	}
+	fresh.LastCheckInID = lastCheckInID
+
+	var snapshot models.GoalStrength
+	if err := s.db.Where("goal_id = ?", goal.ID).First(&snapshot).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fresh, nil
+		}
+		return models.GoalStrength{}, err
+	}
+	if snapshot.Fingerprint != fresh.Fingerprint || snapshot.ComputedThrough > end {
+		return fresh, nil
+	}
+
+	// A check-in recorded since the snapshot for a date it already covers
+	// changes history, so the score has to be replayed.
+	var backdated int64
+	if err := s.db.Model(&models.CheckIn{}).
+		Where("goal_id = ? AND id > ? AND date <= ?", goal.ID, snapshot.LastCheckInID, snapshot.ComputedThrough).
+		Count(&backdated).Error; err != nil {
+		return models.GoalStrength{}, err
+	}
+	if backdated > 0 {
+		return fresh, nil
+	}
+
+	snapshot.LastCheckInID = lastCheckInID
+	return snapshot, nil
+}
+
+// strengthFingerprint hashes everything besides check-ins that decides which
+// days are due, so a cached score can tell when it is out of date.
+func strengthFingerprint(goal models.Goal, vacations []models.VacationPeriod) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%s|%s", goal.Schedule, goal.EffectiveStartDate(), goal.EndDate)
+	for _, pause := range goal.Pauses {
+		fmt.Fprintf(h, "|p%d:%s:%s", pause.ID, pause.StartDate, pause.EndDate)
+	}
+	for _, freeze := range goal.StreakFreezes {
+		fmt.Fprintf(h, "|f%d:%s", freeze.ID, freeze.Date)
+	}
+	for _, vacation := range vacations {
+		fmt.Fprintf(h, "|v%d:%s:%s", vacation.ID, vacation.StartDate, vacation.EndDate)
+	}
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+// advanceStrength folds the days from start to end into score. Completed days
+// count as 1, partial days as a half and failed or missed days as 0; excused
+// and neutral days leave the score unchanged.
+func advanceStrength(score float64, statuses map[string]string, start, end string, neutral func(string) bool) float64 {
+	ForEachDate(start, end, func(date string) {
+		if neutral(date) {
+			return
+		}
+		var value float64
+		switch statuses[date] {
+		case "excused":
+			return
+		case "completed":
+			value = 1
+		case "partial":
+			value = 0.5
+		}
+		score += strengthAlpha * (value - score)
+	})
+	return score
+}
+
+// judgeable reports whether the goal is due and running on date.
+func judgeable(goal models.Goal, date string, neutral func(string) bool) bool {
+	if date < goal.EffectiveStartDate() || (goal.EndDate != "" && date > goal.EndDate) {
+		return false
+	}
+	return !neutral(date)
+}
+
+// rollingRate returns the share of due days from start to end that were
+// completed. Today only counts once it has a check-in.
+func rollingRate(goal models.Goal, statuses map[string]string, start, end, today string, neutral func(string) bool) *float64 {
+	due, completed := 0, 0
+	ForEachDate(start, end, func(date string) {
+		status, checkedIn := statuses[date]
+		if !judgeable(goal, date, neutral) || status == "excused" || (date == today && !checkedIn) {
+			return
+		}
+		due++
+		if status == "completed" {
+			completed++
+		}
+	})
+	if due == 0 {
+		return nil
+	}
+	rate := float64(completed) / float64(due)
+	return &rate
+}
+
+// forecast estimates the chance of completing the goal on date as a blend of
+// the habit strength, the last week's rate and the rate on the same weekday
+// over the recent window. Missing rates fall back to the strength score.
+func forecast(goal models.Goal, statuses map[string]string, score float64, rate7 *float64, today, date string, neutral func(string) bool) float64 {
+	recent := score
+	if rate7 != nil {
+		recent = *rate7
+	}
+
+	weekdayRate := score
+	day, err := time.Parse(dateLayout, date)
+	if err == nil {
+		due, completed := 0, 0
+		for weeks := 1; weeks*7 <= strengthWindowDays; weeks++ {
+			past := day.AddDate(0, 0, -7*weeks).Format(dateLayout)
+			status, checkedIn := statuses[past]
+			if !judgeable(goal, past, neutral) || status == "excused" || (past == today && !checkedIn) {
+				continue
+			}
+			due++
+			if status == "completed" {
+				completed++
+			}
+		}
+		if due > 0 {
+			weekdayRate = float64(completed) / float64(due)
+		}
+	}
+
+	return 0.5*score + 0.3*recent + 0.2*weekdayRate
+}
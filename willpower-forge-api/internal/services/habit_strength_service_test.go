@@ -0,0 +1,105 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+func TestStrengthIncrementalMatchesFullRecompute(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: "2026-01-01", Schedule: "mon,tue,wed,thu,fri"})
+	vacation := models.VacationPeriod{UserID: user.ID, StartDate: "2026-02-09", EndDate: "2026-02-13", Reason: "Travelling"}
+	if err := db.Create(&vacation).Error; err != nil {
+		t.Fatalf("create vacation: %v", err)
+	}
+	vacations := []models.VacationPeriod{vacation}
+	statuses := []string{"completed", "completed", "failed", "partial", "completed", "excused", "completed"}
+	idx := 0
+	ForEachDate("2026-01-01", "2026-04-30", func(date string) {
+		if idx%11 != 10 {
+			createCheckIns(t, db, goal, statuses[idx%len(statuses)], date, date)
+		}
+		idx++
+	})
+	strength := NewHabitStrengthService(db)
+
+	full := func(today string) HabitStrength {
+		t.Helper()
+		if err := db.Where("goal_id = ?", goal.ID).Delete(&models.GoalStrength{}).Error; err != nil {
+			t.Fatalf("drop snapshot: %v", err)
+		}
+		result, err := strength.compute(goal, vacations, today)
+		if err != nil {
+			t.Fatalf("compute: %v", err)
+		}
+		return result
+	}
+	incremental := func(todays ...string) HabitStrength {
+		t.Helper()
+		var result HabitStrength
+		for _, today := range todays {
+			var err error
+			if result, err = strength.compute(goal, vacations, today); err != nil {
+				t.Fatalf("compute on %s: %v", today, err)
+			}
+		}
+		return result
+	}
+	assertSame := func(label string, got, want HabitStrength) {
+		t.Helper()
+		if math.Abs(got.Score-want.Score) > 1e-9 {
+			t.Errorf("%s: incremental score %.12f, full recompute %.12f", label, got.Score, want.Score)
+		}
+	}
+
+	want := full("2026-04-30")
+	db.Where("goal_id = ?", goal.ID).Delete(&models.GoalStrength{})
+	assertSame("day by day", incremental("2026-01-20", "2026-02-11", "2026-02-12", "2026-03-31", "2026-04-30"), want)
+
+	// A backdated check-in for a day the snapshot already covers replays it.
+	backdated := models.CheckIn{GoalID: goal.ID, UserID: user.ID, Date: "2026-02-03", Status: "failed",
+		CreatedAt: time.Now().Add(time.Hour)}
+	if err := db.Create(&backdated).Error; err != nil {
+		t.Fatalf("create check-in: %v", err)
+	}
+	got := incremental("2026-04-30")
+	assertSame("after a backdated check-in", got, full("2026-04-30"))
+	if got.Score == want.Score {
+		t.Errorf("backdated check-in did not change the score %.6f", got.Score)
+	}
+}
+
+func TestStrengthSnapshotStopsBeforeUserToday(t *testing.T) {
+	db := testdb.Open(t)
+	zone := zoneAheadOfServer(t)
+	user := createTestUser(t, db, models.User{Timezone: zone})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: "2026-01-01"})
+	strength := NewHabitStrengthService(db)
+
+	if _, err := strength.GoalStrength(goal); err != nil {
+		t.Fatalf("goal strength: %v", err)
+	}
+	var snapshot models.GoalStrength
+	if err := db.First(&snapshot, "goal_id = ?", goal.ID).Error; err != nil {
+		t.Fatalf("load snapshot: %v", err)
+	}
+	if want := addDays(TodayIn(zone), -1); snapshot.ComputedThrough != want {
+		t.Errorf("snapshot computed through %s, want the day before the user's today %s", snapshot.ComputedThrough, want)
+	}
+
+	// A snapshot past the user's yesterday, left by a timezone further
+	// east, is recomputed instead of trusted.
+	db.Model(&snapshot).Updates(map[string]interface{}{"computed_through": TodayIn(zone), "score": 1})
+	result, err := strength.compute(goal, nil, addDays(TodayIn(zone), -1))
+	if err != nil {
+		t.Fatalf("compute: %v", err)
+	}
+	if result.Score != 0 {
+		t.Errorf("score %.2f from a snapshot covering an unfinished day, want 0", result.Score)
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+func createTestUser(t *testing.T, db *gorm.DB, user models.User) models.User {
+	t.Helper()
+	if user.Username == "" {
+		user.Username = "alice"
+	}
+	user.PasswordHash = "x"
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func createTestGoal(t *testing.T, db *gorm.DB, goal models.Goal) models.Goal {
+	t.Helper()
+	if goal.Type == "" {
+		goal.Type = "I_WILL"
+	}
+	if goal.Title == "" {
+		goal.Title = "Read"
+	}
+	if goal.Status == "" {
+		goal.Status = models.GoalStatusActive
+	}
+	if err := db.Create(&goal).Error; err != nil {
+		t.Fatalf("create goal: %v", err)
+	}
+	return goal
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+const (
+	// inboundHookRateLimit is how many calls one hook accepts per minute.
+	inboundHookRateLimit = 10
+	// inboundClientRateLimit is how many calls one client address may make
+	// per minute across all hooks, including calls with unknown tokens.
+	inboundClientRateLimit = 60
+	inboundRateWindow      = time.Minute
+	// inboundHookCallRetention is how long a hook's call log is kept.
+	inboundHookCallRetention = 30 * 24 * time.Hour
+
+	// InboundHookPathPrefix is the path inbound hook tokens are appended to.
+	InboundHookPathPrefix = "/api/v1/hooks/"
+)
+
+var (
+	ErrInboundHookNotFound = errors.New("inbound hook not found")
+	ErrRateLimited         = errors.New("rate limit exceeded")
+)
+
+// InboundHookService records check-ins sent to inbound hook URLs. Check-ins
+// go through CheckInService like those made in the app, and every call with
+// a known token is logged on its hook.
+type InboundHookService struct {
+	db            *gorm.DB
+	checkIns      *CheckInService
+	hookLimiter   *rateLimiter
+	clientLimiter *rateLimiter
+}
+
+func NewInboundHookService(db *gorm.DB, checkIns *CheckInService) *InboundHookService {
+	return &InboundHookService{
+		db:            db,
+		checkIns:      checkIns,
+		hookLimiter:   newRateLimiter(inboundHookRateLimit, inboundRateWindow),
+		clientLimiter: newRateLimiter(inboundClientRateLimit, inboundRateWindow),
+	}
+}
+
+// Create adds an inbound hook to the goal and returns it with its token.
+func (s *InboundHookService) Create(goal models.Goal, name string) (models.InboundHook, string, error) {
+	token, err := newInboundHookToken()
+	if err != nil {
+		return models.InboundHook{}, "", err
+	}
+
+	hook := models.InboundHook{
+		GoalID:    goal.ID,
+		UserID:    goal.UserID,
+		Name:      name,
+		TokenHash: hashInboundHookToken(token),
+		TokenHint: tokenHint(token),
+	}
+	if err := s.db.Create(&hook).Error; err != nil {
+		return models.InboundHook{}, "", err
+	}
+	return hook, token, nil
+}
+
+// Rotate gives the hook a new token; the previous URL stops working.
+func (s *InboundHookService) Rotate(hook *models.InboundHook) (string, error) {
+	token, err := newInboundHookToken()
+	if err != nil {
+		return "", err
+	}
+
+	hook.TokenHash = hashInboundHookToken(token)
+	hook.TokenHint = tokenHint(token)
+	if err := s.db.Model(hook).Updates(map[string]interface{}{
+		"token_hash": hook.TokenHash,
+		"token_hint": hook.TokenHint,
+	}).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Authorize finds the hook identified by token and counts the call against
+// the rate limits. Calls over the limits fail with ErrRateLimited and unknown
+// tokens with ErrInboundHookNotFound.
+func (s *InboundHookService) Authorize(token, remoteAddr string) (models.InboundHook, error) {
+	var hook models.InboundHook
+	now := time.Now()
+	if !s.clientLimiter.Allow(remoteAddr, now) {
+		return hook, ErrRateLimited
+	}
+
+	if err := s.db.Where("token_hash = ?", hashInboundHookToken(token)).First(&hook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return hook, ErrInboundHookNotFound
+		}
+		return hook, err
+	}
+
+	if !s.hookLimiter.Allow(strconv.FormatUint(uint64(hook.ID), 10), now) {
+		s.logCall(hook, remoteAddr, models.InboundCallRateLimited, "", nil)
+		return hook, ErrRateLimited
+	}
+	return hook, nil
+}
+
+// Reject logs a call to the hook that was refused before reaching
+// RecordCheckIn, such as one with a malformed payload.
+func (s *InboundHookService) Reject(hook models.InboundHook, remoteAddr, reason string) {
+	s.logCall(hook, remoteAddr, models.InboundCallRejected, reason, nil)
+}
+
+// Record creates a check-in on the hook's goal and logs the call. Goals in
+// the recycle bin fail with ErrInboundHookNotFound; validation errors are
+// those of CheckInService.RecordCheckIn.
+func (s *InboundHookService) Record(hook models.InboundHook, remoteAddr string, input CheckInInput) (models.Goal, models.CheckIn, error) {
+	var goal models.Goal
+	if err := s.db.Where("id = ? AND user_id = ?", hook.GoalID, hook.UserID).First(&goal).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logCall(hook, remoteAddr, models.InboundCallRejected, "goal is in the recycle bin", nil)
+			return goal, models.CheckIn{}, ErrInboundHookNotFound
+		}
+		return goal, models.CheckIn{}, err
+	}
+
+	checkIn, err := s.checkIns.RecordCheckIn(goal, input)
+	if err != nil {
+		s.logCall(hook, remoteAddr, models.InboundCallRejected, err.Error(), nil)
+		return goal, models.CheckIn{}, err
+	}
+
+	s.logCall(hook, remoteAddr, models.InboundCallAccepted, "", &checkIn.ID)
+	if err := s.db.Model(&hook).Update("last_used_at", time.Now()).Error; err != nil {
+		log.Printf("Error updating inbound hook %d: %v", hook.ID, err)
+	}
+
+	return goal, checkIn, nil
+}
+
+// Calls returns the hook's call log, newest first, and its total size.
+func (s *InboundHookService) Calls(hookID uint, limit, offset int) ([]models.InboundHookCall, int64, error) {
+	query := s.db.Model(&models.InboundHookCall{}).Where("hook_id = ?", hookID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var calls []models.InboundHookCall
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&calls).Error; err != nil {
+		return nil, 0, err
+	}
+	return calls, total, nil
+}
+
+func (s *InboundHookService) logCall(hook models.InboundHook, remoteAddr, outcome, reason string, checkInID *uint) {
+	call := models.InboundHookCall{
+		HookID:     hook.ID,
+		UserID:     hook.UserID,
+		Outcome:    outcome,
+		Error:      reason,
+		CheckInID:  checkInID,
+		RemoteAddr: remoteAddr,
+	}
+	if err := s.db.Create(&call).Error; err != nil {
+		log.Printf("Error logging call to inbound hook %d: %v", hook.ID, err)
+	}
+}
+
+func newInboundHookToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return "wfh_" + hex.EncodeToString(raw), nil
+}
+
+func hashInboundHookToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenHint returns the last characters of a token for display.
+func tokenHint(token string) string {
+	return "…" + token[len(token)-4:]
+}
+
+// PruneCalls deletes logged calls older than 30 days.
+func (s *InboundHookService) PruneCalls() error {
+	result := s.db.Where("created_at < ?", time.Now().Add(-inboundHookCallRetention)).
+		Delete(&models.InboundHookCall{})
+	if result.Error != nil {
+		return fmt.Errorf("deleting old inbound hook calls: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Pruned %d old inbound hook calls", result.RowsAffected)
+	}
+	return nil
+}
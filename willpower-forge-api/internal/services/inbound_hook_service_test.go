@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+func TestPruneCalls(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: "2026-03-01"})
+	hooks := NewInboundHookService(db, NewCheckInService(db, NewPointsService(db)))
+	hook, _, err := hooks.Create(goal, "Shortcut")
+	if err != nil {
+		t.Fatalf("create hook: %v", err)
+	}
+
+	calls := []models.InboundHookCall{
+		{Outcome: models.InboundCallAccepted, CreatedAt: time.Now().Add(-inboundHookCallRetention - time.Hour)},
+		{Outcome: models.InboundCallRejected},
+	}
+	for idx := range calls {
+		calls[idx].HookID = hook.ID
+		calls[idx].UserID = user.ID
+	}
+	if err := db.Create(&calls).Error; err != nil {
+		t.Fatalf("create calls: %v", err)
+	}
+
+	if err := hooks.PruneCalls(); err != nil {
+		t.Fatalf("prune calls: %v", err)
+	}
+	var kept []models.InboundHookCall
+	db.Find(&kept)
+	if len(kept) != 1 || kept[0].ID != calls[1].ID {
+		t.Errorf("kept %+v, want only the recent call", kept)
+	}
+}
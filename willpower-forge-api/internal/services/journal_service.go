@@ -0,0 +1,285 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+var (
+	ErrJournalEntryNotFound = errors.New("journal entry not found")
+	ErrJournalEntryExists   = errors.New("journal entry already exists")
+	ErrUnknownPrompt        = errors.New("unknown journal prompt")
+)
+
+// likeEscaper escapes LIKE wildcards in search text, for use with
+// ESCAPE '\'. Angle brackets are escaped the way the markdown sanitizer
+// stores them, so searches for them still match.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "<", "&lt;", ">", "&gt;")
+
+// JournalPrompt is a guided reflection question in the requested locale.
+type JournalPrompt struct {
+	Key      string `json:"key"`
+	GoalType string `json:"goal_type,omitempty"`
+	Text     string `json:"text"`
+}
+
+type journalPrompt struct {
+	Key      string
+	GoalType string
+	Text     localizedText
+}
+
+// journalPrompts lists every reflection prompt. General prompts have no goal
+// type and are always offered; the others only on days with a check-in for a
+// goal of that type.
+var journalPrompts = []journalPrompt{
+	{
+		Key:  "highlight",
+		Text: localizedText{LocaleEnglish: "What was the best moment of today?", LocaleChinese: "今天最好的时刻是什么？"},
+	},
+	{
+		Key:  "tomorrow",
+		Text: localizedText{LocaleEnglish: "What is one thing you will do differently tomorrow?", LocaleChinese: "明天你会做哪一件不同的事？"},
+	},
+	{
+		Key:      "will_follow_through",
+		GoalType: "I_WILL",
+		Text:     localizedText{LocaleEnglish: "What helped you follow through today, or what got in the way?", LocaleChinese: "今天是什么帮助你坚持下来，又是什么阻碍了你？"},
+	},
+	{
+		Key:      "wont_urges",
+		GoalType: "I_WONT",
+		Text:     localizedText{LocaleEnglish: "Which urges showed up today and how did you respond?", LocaleChinese: "今天出现了哪些冲动？你是如何应对的？"},
+	},
+	{
+		Key:      "wont_trigger",
+		GoalType: "I_WONT",
+		Text:     localizedText{LocaleEnglish: "What situation would you rather avoid tomorrow?", LocaleChinese: "明天你希望避开什么情境？"},
+	},
+	{
+		Key:      "want_progress",
+		GoalType: "I_WANT",
+		Text:     localizedText{LocaleEnglish: "What step did you take toward what you want most?", LocaleChinese: "今天你朝最想要的目标迈出了哪一步？"},
+	},
+}
+
+// JournalAnswerInput is the answer to one prompt, identified by its key.
+type JournalAnswerInput struct {
+	PromptKey string
+	Answer    string
+}
+
+type JournalService struct {
+	db *gorm.DB
+}
+
+func NewJournalService(db *gorm.DB) *JournalService {
+	return &JournalService{db: db}
+}
+
+// Prompts returns the reflection prompts for the user's day: the general
+// prompts plus those matching the types of the goals checked in that day.
+func (s *JournalService) Prompts(userID uint, date, locale string) ([]JournalPrompt, error) {
+	var goalTypes []string
+	if err := s.db.Model(&models.Goal{}).
+		Where("id IN (SELECT goal_id FROM check_ins WHERE user_id = ? AND date = ?)", userID, date).
+		Distinct().Pluck("type", &goalTypes).Error; err != nil {
+		return nil, err
+	}
+
+	checkedIn := make(map[string]bool, len(goalTypes))
+	for _, goalType := range goalTypes {
+		checkedIn[goalType] = true
+	}
+
+	prompts := make([]JournalPrompt, 0, len(journalPrompts))
+	for _, prompt := range journalPrompts {
+		if prompt.GoalType != "" && !checkedIn[prompt.GoalType] {
+			continue
+		}
+		prompts = append(prompts, JournalPrompt{
+			Key:      prompt.Key,
+			GoalType: prompt.GoalType,
+			Text:     prompt.Text.in(locale),
+		})
+	}
+	return prompts, nil
+}
+
+// ListEntries returns the user's entries between the given dates, both
+// optional and inclusive, newest first. A non-empty query keeps only entries
+// whose body or answers contain it.
+func (s *JournalService) ListEntries(userID uint, from, to, query string) ([]models.JournalEntry, error) {
+	db := s.db.Preload("Answers").Where("user_id = ?", userID)
+	if from != "" {
+		db = db.Where("date >= ?", from)
+	}
+	if to != "" {
+		db = db.Where("date <= ?", to)
+	}
+	if query != "" {
+		pattern := "%" + likeEscaper.Replace(query) + "%"
+		db = db.Where(`body LIKE ? ESCAPE '\' OR id IN (SELECT journal_entry_id FROM journal_answers WHERE answer LIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+
+	var entries []models.JournalEntry
+	if err := db.Order("date DESC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetEntry returns the user's entry for date.
+func (s *JournalService) GetEntry(userID uint, date string) (models.JournalEntry, error) {
+	var entry models.JournalEntry
+	if err := s.db.Preload("Answers").Where("user_id = ? AND date = ?", userID, date).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.JournalEntry{}, ErrJournalEntryNotFound
+		}
+		return models.JournalEntry{}, err
+	}
+	return entry, nil
+}
+
+// CreateEntry stores the user's entry for date, which must not exist yet.
+func (s *JournalService) CreateEntry(userID uint, date, body string, answers []JournalAnswerInput, locale string) (models.JournalEntry, error) {
+	resolved, err := resolveAnswers(answers, locale)
+	if err != nil {
+		return models.JournalEntry{}, err
+	}
+
+	entry := models.JournalEntry{
+		UserID:  userID,
+		Date:    date,
+		Body:    body,
+		Answers: resolved,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.JournalEntry{}).Where("user_id = ? AND date = ?", userID, date).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrJournalEntryExists
+		}
+		return tx.Create(&entry).Error
+	})
+	if err != nil {
+		return models.JournalEntry{}, err
+	}
+	return entry, nil
+}
+
+// UpdateEntry changes the body and, if answers is not nil, replaces all
+// prompt answers of the user's entry for date.
+func (s *JournalService) UpdateEntry(userID uint, date string, body *string, answers []JournalAnswerInput, locale string) (models.JournalEntry, error) {
+	entry, err := s.GetEntry(userID, date)
+	if err != nil {
+		return models.JournalEntry{}, err
+	}
+
+	var resolved []models.JournalAnswer
+	if answers != nil {
+		if resolved, err = resolveAnswers(answers, locale); err != nil {
+			return models.JournalEntry{}, err
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if body != nil {
+			if err := tx.Model(&entry).Update("body", *body).Error; err != nil {
+				return err
+			}
+		}
+		if answers == nil {
+			return nil
+		}
+		if err := tx.Where("journal_entry_id = ?", entry.ID).Delete(&models.JournalAnswer{}).Error; err != nil {
+			return err
+		}
+		for idx := range resolved {
+			resolved[idx].JournalEntryID = entry.ID
+		}
+		if len(resolved) == 0 {
+			return nil
+		}
+		return tx.Create(&resolved).Error
+	})
+	if err != nil {
+		return models.JournalEntry{}, err
+	}
+
+	return s.GetEntry(userID, date)
+}
+
+// DeleteEntry removes the user's entry for date together with its answers.
+func (s *JournalService) DeleteEntry(userID uint, date string) error {
+	entry, err := s.GetEntry(userID, date)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("journal_entry_id = ?", entry.ID).Delete(&models.JournalAnswer{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entry).Error
+	})
+}
+
+// ExportMarkdown renders entries as a single Markdown document, one section
+// per day.
+func ExportMarkdown(entries []models.JournalEntry) string {
+	var b strings.Builder
+	b.WriteString("# Journal\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "\n## %s\n", entry.Date)
+		if body := strings.TrimSpace(entry.Body); body != "" {
+			fmt.Fprintf(&b, "\n%s\n", body)
+		}
+		for _, answer := range entry.Answers {
+			fmt.Fprintf(&b, "\n### %s\n\n%s\n", answer.Prompt, strings.TrimSpace(answer.Answer))
+		}
+	}
+	return b.String()
+}
+
+// resolveAnswers checks that every answer refers to a known prompt, at most
+// once, and attaches the prompt text in the given locale. Empty answers are
+// dropped.
+func resolveAnswers(answers []JournalAnswerInput, locale string) ([]models.JournalAnswer, error) {
+	resolved := make([]models.JournalAnswer, 0, len(answers))
+	seen := make(map[string]bool, len(answers))
+	for _, answer := range answers {
+		prompt, ok := findJournalPrompt(answer.PromptKey)
+		if !ok || seen[answer.PromptKey] {
+			return nil, ErrUnknownPrompt
+		}
+		seen[answer.PromptKey] = true
+
+		if strings.TrimSpace(answer.Answer) == "" {
+			continue
+		}
+		resolved = append(resolved, models.JournalAnswer{
+			PromptKey: prompt.Key,
+			Prompt:    prompt.Text.in(locale),
+			Answer:    answer.Answer,
+		})
+	}
+	return resolved, nil
+}
+
+func findJournalPrompt(key string) (journalPrompt, bool) {
+	for _, prompt := range journalPrompts {
+		if prompt.Key == key {
+			return prompt, true
+		}
+	}
+	return journalPrompt{}, false
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"testing"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+func TestListEntriesEscapesLikeWildcards(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	journal := NewJournalService(db)
+
+	for date, body := range map[string]string{
+		"2026-01-01": "Finished 100% of my reading",
+		"2026-01-02": "Read 1000 pages",
+		"2026-01-03": "snake_case and a &lt;b&gt; tag",
+		"2026-01-04": "snakeXcase",
+	} {
+		if _, err := journal.CreateEntry(user.ID, date, body, nil, LocaleEnglish); err != nil {
+			t.Fatalf("create entry: %v", err)
+		}
+	}
+
+	tests := map[string][]string{
+		"100%":  {"2026-01-01"},
+		"_case": {"2026-01-03"},
+		"<b>":   {"2026-01-03"},
+		"%":     {"2026-01-01"},
+		`\`:     nil,
+	}
+	for query, want := range tests {
+		entries, err := journal.ListEntries(user.ID, "", "", query)
+		if err != nil {
+			t.Fatalf("list %q: %v", query, err)
+		}
+		var got []string
+		for _, entry := range entries {
+			got = append(got, entry.Date)
+		}
+		if len(got) != len(want) || (len(want) > 0 && got[0] != want[0]) {
+			t.Errorf("ListEntries(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
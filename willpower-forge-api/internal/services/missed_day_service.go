@@ -0,0 +1,108 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+// missedDayLookback is how many finished days each run checks, so days are
+// still finalized after the server was down for a while.
+const missedDayLookback = 7
+
+// MissedDayService records explicit missed check-ins for due days that ended
+// without one, so summaries and rates count them.
+type MissedDayService struct {
+	db       *gorm.DB
+	checkIns *CheckInService
+}
+
+func NewMissedDayService(db *gorm.DB, checkIns *CheckInService) *MissedDayService {
+	return &MissedDayService{db: db, checkIns: checkIns}
+}
+
+// FinalizeMissedDays records a missed check-in for every running goal on
+// each of its due days that has ended, plus the user's grace period, in the
+// user's timezone without a check-in. Goals and users that ignore missed
+// days are skipped, as are paused, frozen and vacation days. Days that
+// already have a check-in are never touched.
+func (s *MissedDayService) FinalizeMissedDays() error {
+	return s.finalize(time.Now())
+}
+
+func (s *MissedDayService) finalize(now time.Time) error {
+	var users []models.User
+	if err := s.db.Find(&users).Error; err != nil {
+		return fmt.Errorf("loading users: %w", err)
+	}
+
+	failed, recorded := 0, 0
+	for _, user := range users {
+		count, err := s.finalizeUser(user, now)
+		recorded += count
+		if err != nil {
+			log.Printf("Error finalizing missed days of user %d: %v", user.ID, err)
+			failed++
+		}
+	}
+
+	if recorded > 0 {
+		log.Printf("Recorded %d missed days", recorded)
+	}
+	if failed > 0 {
+		return fmt.Errorf("finalizing missed days of %d users failed", failed)
+	}
+	return nil
+}
+
+// finalizeUser finalizes the user's goals and returns how many missed days
+// it recorded.
+func (s *MissedDayService) finalizeUser(user models.User, now time.Time) (int, error) {
+	grace := time.Duration(user.MissedDayGraceHours) * time.Hour
+	// The last finished day is the one before the day it still is once the
+	// grace period is taken off.
+	lastDay := addDays(now.Add(-grace).In(UserLocation(user.Timezone)).Format(dateLayout), -1)
+	firstDay := addDays(lastDay, 1-missedDayLookback)
+
+	// Goals completed since the first day still get their last days
+	// finalized.
+	var goals []models.Goal
+	if err := s.db.Preload("Pauses").Preload("StreakFreezes").
+		Where("user_id = ? AND (status = ? OR (status = ? AND end_date >= ?))",
+			user.ID, models.GoalStatusActive, models.GoalStatusCompleted, firstDay).
+		Find(&goals).Error; err != nil {
+		return 0, err
+	}
+
+	var vacations []models.VacationPeriod
+	if err := s.db.Where("user_id = ?", user.ID).Find(&vacations).Error; err != nil {
+		return 0, err
+	}
+
+	recorded := 0
+	for _, goal := range goals {
+		if !goal.RecordsMissedDays(user.MissedDays) {
+			continue
+		}
+
+		neutral := NeutralDates(goal, vacations)
+		var err error
+		ForEachDate(firstDay, lastDay, func(date string) {
+			if err != nil || !judgeable(goal, date, neutral) {
+				return
+			}
+			var created bool
+			if created, err = s.checkIns.RecordMissed(goal, date); created {
+				recorded++
+			}
+		})
+		if err != nil {
+			return recorded, err
+		}
+	}
+	return recorded, nil
+}
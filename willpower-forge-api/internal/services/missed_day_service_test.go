@@ -0,0 +1,156 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+// missedDates returns the dates recorded as missed for the goal, oldest
+// first.
+func missedDates(t *testing.T, service *MissedDayService, goalID uint) []string {
+	t.Helper()
+	var dates []string
+	if err := service.db.Model(&models.CheckIn{}).Where("goal_id = ? AND status = ?", goalID, models.CheckInStatusMissed).
+		Order("date ASC").Pluck("date", &dates).Error; err != nil {
+		t.Fatalf("load missed days: %v", err)
+	}
+	return dates
+}
+
+func equalDates(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func newMissedDayService(t *testing.T) *MissedDayService {
+	t.Helper()
+	db := testdb.Open(t)
+	return NewMissedDayService(db, NewCheckInService(db, NewPointsService(db)))
+}
+
+func TestFinalizeMissedDaysUsesUserTimezone(t *testing.T) {
+	service := newMissedDayService(t)
+	tokyo := createTestUser(t, service.db, models.User{Username: "tokyo", Timezone: "Asia/Tokyo"})
+	utc := createTestUser(t, service.db, models.User{Username: "utc", Timezone: "UTC"})
+	for _, user := range []*models.User{&tokyo, &utc} {
+		if err := service.db.Model(user).Update("missed_day_grace_hours", 0).Error; err != nil {
+			t.Fatalf("update grace: %v", err)
+		}
+	}
+	tokyoGoal := createTestGoal(t, service.db, models.Goal{UserID: tokyo.ID, StartDate: "2026-10-18"})
+	utcGoal := createTestGoal(t, service.db, models.Goal{UserID: utc.ID, StartDate: "2026-10-18"})
+
+	// 01:00 on the 20th in Tokyo, still the 19th in UTC.
+	if err := service.finalize(time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+
+	if got, want := missedDates(t, service, tokyoGoal.ID), []string{"2026-10-18", "2026-10-19"}; !equalDates(got, want) {
+		t.Errorf("Tokyo missed days = %v, want %v", got, want)
+	}
+	if got, want := missedDates(t, service, utcGoal.ID), []string{"2026-10-18"}; !equalDates(got, want) {
+		t.Errorf("UTC missed days = %v, want %v", got, want)
+	}
+}
+
+func TestFinalizeMissedDaysWaitsForGracePeriod(t *testing.T) {
+	service := newMissedDayService(t)
+	user := createTestUser(t, service.db, models.User{Timezone: "UTC", MissedDayGraceHours: 6})
+	goal := createTestGoal(t, service.db, models.Goal{UserID: user.ID, StartDate: "2026-10-19"})
+
+	// The 19th ended five hours ago: still within the grace period.
+	if err := service.finalize(time.Date(2026, 10, 20, 5, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	if got := missedDates(t, service, goal.ID); len(got) != 0 {
+		t.Errorf("missed days within the grace period = %v, want none", got)
+	}
+
+	if err := service.finalize(time.Date(2026, 10, 20, 6, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	if got, want := missedDates(t, service, goal.ID), []string{"2026-10-19"}; !equalDates(got, want) {
+		t.Errorf("missed days after the grace period = %v, want %v", got, want)
+	}
+}
+
+func TestFinalizeMissedDaysHonoursOverrides(t *testing.T) {
+	service := newMissedDayService(t)
+	recording := createTestUser(t, service.db, models.User{Username: "recording", Timezone: "UTC", MissedDays: models.MissedDaysRecord})
+	ignoring := createTestUser(t, service.db, models.User{Username: "ignoring", Timezone: "UTC", MissedDays: models.MissedDaysIgnore})
+
+	tests := []struct {
+		name string
+		goal models.Goal
+		want bool
+	}{
+		{"user records", models.Goal{UserID: recording.ID}, true},
+		{"goal ignores for a recording user", models.Goal{UserID: recording.ID, MissedDays: models.MissedDaysIgnore}, false},
+		{"user ignores", models.Goal{UserID: ignoring.ID}, false},
+		{"goal records for an ignoring user", models.Goal{UserID: ignoring.ID, MissedDays: models.MissedDaysRecord}, true},
+	}
+	for i := range tests {
+		tests[i].goal.StartDate = "2026-10-18"
+		tests[i].goal = createTestGoal(t, service.db, tests[i].goal)
+	}
+
+	if err := service.finalize(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	for _, tt := range tests {
+		if got := len(missedDates(t, service, tt.goal.ID)) > 0; got != tt.want {
+			t.Errorf("%s: recorded = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFinalizeMissedDaysKeepsCheckIns(t *testing.T) {
+	service := newMissedDayService(t)
+	user := createTestUser(t, service.db, models.User{Timezone: "UTC"})
+	goal := createTestGoal(t, service.db, models.Goal{UserID: user.ID, StartDate: "2026-10-16"})
+	if _, err := service.checkIns.RecordCheckIn(goal, CheckInInput{Date: "2026-10-17", Status: "completed"}); err != nil {
+		t.Fatalf("record check-in: %v", err)
+	}
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		if err := service.finalize(now); err != nil {
+			t.Fatalf("finalize: %v", err)
+		}
+	}
+	if got, want := missedDates(t, service, goal.ID), []string{"2026-10-16", "2026-10-18"}; !equalDates(got, want) {
+		t.Errorf("missed days = %v, want %v", got, want)
+	}
+}
+
+func TestBackdatedCheckInReplacesMissedDay(t *testing.T) {
+	service := newMissedDayService(t)
+	user := createTestUser(t, service.db, models.User{Timezone: "UTC"})
+	goal := createTestGoal(t, service.db, models.Goal{UserID: user.ID, StartDate: "2026-10-18"})
+
+	if err := service.finalize(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	if got := missedDates(t, service, goal.ID); !equalDates(got, []string{"2026-10-18"}) {
+		t.Fatalf("missed days = %v, want 2026-10-18", got)
+	}
+
+	if _, err := service.checkIns.RecordCheckIn(goal, CheckInInput{Date: "2026-10-18", Status: "completed"}); err != nil {
+		t.Fatalf("backdated check-in: %v", err)
+	}
+	var statuses []string
+	service.db.Model(&models.CheckIn{}).Where("goal_id = ? AND date = ?", goal.ID, "2026-10-18").Pluck("status", &statuses)
+	if len(statuses) != 1 || statuses[0] != "completed" {
+		t.Errorf("check-ins on 2026-10-18 = %v, want only the completed one", statuses)
+	}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+const (
+	// notificationReadRetention is how long read notifications are kept.
+	notificationReadRetention = 30 * 24 * time.Hour
+	// notificationRetention is how long any notification is kept, read or
+	// not.
+	notificationRetention = 90 * 24 * time.Hour
+)
+
+var ErrInvalidNotification = errors.New("notification needs a kind and a title")
+
+// NewNotification is a notification to add to a user's inbox.
+type NewNotification struct {
+	Kind  string
+	Title string
+	Body  string
+	Link  string
+}
+
+// NotificationService manages the in-app inbox. Other services add
+// notifications through Notify and Broadcast rather than writing rows
+// directly, and every change is published to the user's event streams.
+type NotificationService struct {
+	db     *gorm.DB
+	events *EventBroker
+}
+
+func NewNotificationService(db *gorm.DB, events *EventBroker) *NotificationService {
+	return &NotificationService{db: db, events: events}
+}
+
+// Notify adds a notification to the user's inbox.
+func (s *NotificationService) Notify(userID uint, n NewNotification) (models.Notification, error) {
+	notification := models.Notification{
+		UserID: userID,
+		Kind:   n.Kind,
+		Title:  strings.TrimSpace(n.Title),
+		Body:   strings.TrimSpace(n.Body),
+		Link:   n.Link,
+	}
+	if notification.Kind == "" || notification.Title == "" {
+		return notification, ErrInvalidNotification
+	}
+	if err := s.db.Create(&notification).Error; err != nil {
+		return notification, err
+	}
+	s.events.Publish(userID, EventNotificationCreated, notification)
+	return notification, nil
+}
+
+// Broadcast adds the notification to every user's inbox and returns how
+// many users received it.
+func (s *NotificationService) Broadcast(n NewNotification) (int64, error) {
+	title := strings.TrimSpace(n.Title)
+	if n.Kind == "" || title == "" {
+		return 0, ErrInvalidNotification
+	}
+
+	body := strings.TrimSpace(n.Body)
+	result := s.db.Exec(
+		`INSERT INTO notifications (user_id, kind, title, body, link, created_at)
+		 SELECT id, ?, ?, ?, ?, ? FROM users`,
+		n.Kind, title, body, n.Link, time.Now(),
+	)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	// Each user's copy has its own ID, so streams get the announcement
+	// itself and reload the inbox for the rest.
+	s.events.PublishAll(EventNotificationAnnounced, map[string]string{
+		"kind":  n.Kind,
+		"title": title,
+		"body":  body,
+		"link":  n.Link,
+	})
+	return result.RowsAffected, nil
+}
+
+// List returns the user's notifications, newest first, with the total
+// matching and the number unread. With unreadOnly only unread ones are
+// listed.
+func (s *NotificationService) List(userID uint, unreadOnly bool, limit, offset int) ([]models.Notification, int64, int64, error) {
+	query := s.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, 0, err
+	}
+
+	notifications := []models.Notification{}
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&notifications).Error; err != nil {
+		return nil, 0, 0, err
+	}
+
+	unread, err := s.UnreadCount(userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return notifications, total, unread, nil
+}
+
+// UnreadCount returns how many of the user's notifications are unread.
+func (s *NotificationService) UnreadCount(userID uint) (int64, error) {
+	var count int64
+	err := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks one of the user's notifications as read. Marking a read
+// notification again keeps its original ReadAt.
+func (s *NotificationService) MarkRead(userID, id uint) (models.Notification, error) {
+	var notification models.Notification
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		return notification, err
+	}
+	if notification.ReadAt != nil {
+		return notification, nil
+	}
+
+	now := time.Now()
+	if err := s.db.Model(&notification).Update("read_at", now).Error; err != nil {
+		return notification, err
+	}
+	notification.ReadAt = &now
+	s.events.Publish(userID, EventNotificationRead, notification)
+	return notification, nil
+}
+
+// MarkAllRead marks all of the user's unread notifications as read and
+// returns how many there were.
+func (s *NotificationService) MarkAllRead(userID uint) (int64, error) {
+	result := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		s.events.Publish(userID, EventNotificationsReadAll, map[string]int64{"updated": result.RowsAffected})
+	}
+	return result.RowsAffected, nil
+}
+
+// PruneNotifications deletes read notifications older than 30 days and any
+// notification older than 90 days.
+func (s *NotificationService) PruneNotifications() error {
+	now := time.Now()
+	result := s.db.
+		Where("(read_at IS NOT NULL AND created_at < ?) OR created_at < ?",
+			now.Add(-notificationReadRetention), now.Add(-notificationRetention)).
+		Delete(&models.Notification{})
+	if result.Error != nil {
+		return fmt.Errorf("deleting old notifications: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Pruned %d old notifications", result.RowsAffected)
+	}
+	return nil
+}
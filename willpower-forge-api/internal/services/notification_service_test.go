@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+// nextEvent returns the subscription's next event without blocking.
+func nextEvent(t *testing.T, sub *EventSubscription) StreamEvent {
+	t.Helper()
+	select {
+	case event := <-sub.Events():
+		return event
+	default:
+		t.Fatal("no event was published")
+		return StreamEvent{}
+	}
+}
+
+func TestNotificationInbox(t *testing.T) {
+	db := testdb.Open(t)
+	alice := createTestUser(t, db, models.User{})
+	bob := createTestUser(t, db, models.User{Username: "bob"})
+	events := NewEventBroker()
+	notifications := NewNotificationService(db, events)
+
+	aliceStream, _, _ := events.Subscribe(alice.ID, 0, false)
+	bobStream, _, _ := events.Subscribe(bob.ID, 0, false)
+	defer aliceStream.Close()
+	defer bobStream.Close()
+
+	if _, err := notifications.Notify(alice.ID, NewNotification{Kind: models.NotificationReminder, Title: "  "}); !errors.Is(err, ErrInvalidNotification) {
+		t.Errorf("notify without a title: got %v, want ErrInvalidNotification", err)
+	}
+	first, err := notifications.Notify(alice.ID, NewNotification{Kind: models.NotificationReminder, Title: " Read "})
+	if err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if first.Title != "Read" {
+		t.Errorf("title = %q, want it trimmed", first.Title)
+	}
+	if event := nextEvent(t, aliceStream); event.Type != EventNotificationCreated {
+		t.Errorf("alice's event = %s, want %s", event.Type, EventNotificationCreated)
+	}
+
+	// An announcement lands in every inbox and on every stream.
+	sent, err := notifications.Broadcast(NewNotification{Kind: models.NotificationAnnouncement, Title: "Maintenance tonight"})
+	if err != nil {
+		t.Fatalf("broadcast: %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("broadcast reached %d users, want 2", sent)
+	}
+	for name, stream := range map[string]*EventSubscription{"alice": aliceStream, "bob": bobStream} {
+		if event := nextEvent(t, stream); event.Type != EventNotificationAnnounced {
+			t.Errorf("%s's event = %s, want %s", name, event.Type, EventNotificationAnnounced)
+		}
+	}
+	list, total, unread, err := notifications.List(bob.ID, false, 50, 0)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if total != 1 || unread != 1 || len(list) != 1 || list[0].Title != "Maintenance tonight" {
+		t.Errorf("bob's inbox = %+v (%d total, %d unread), want the announcement", list, total, unread)
+	}
+
+	// Reading a notification keeps its first read time and only notifies once.
+	read, err := notifications.MarkRead(alice.ID, first.ID)
+	if err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+	nextEvent(t, aliceStream)
+	again, err := notifications.MarkRead(alice.ID, first.ID)
+	if err != nil {
+		t.Fatalf("mark read again: %v", err)
+	}
+	if read.ReadAt == nil || again.ReadAt == nil || !again.ReadAt.Equal(*read.ReadAt) {
+		t.Errorf("read_at = %v then %v, want it kept", read.ReadAt, again.ReadAt)
+	}
+	if _, err := notifications.MarkRead(bob.ID, first.ID); err == nil {
+		t.Error("bob marked alice's notification as read")
+	}
+
+	if list, total, _, _ = notifications.List(alice.ID, true, 50, 0); total != 1 || list[0].Kind != models.NotificationAnnouncement {
+		t.Errorf("alice's unread = %+v, want only the announcement", list)
+	}
+	if updated, err := notifications.MarkAllRead(alice.ID); err != nil || updated != 1 {
+		t.Errorf("mark all read = %d, %v, want 1", updated, err)
+	}
+	if event := nextEvent(t, aliceStream); event.Type != EventNotificationsReadAll {
+		t.Errorf("alice's event = %s, want %s", event.Type, EventNotificationsReadAll)
+	}
+	if updated, _ := notifications.MarkAllRead(alice.ID); updated != 0 {
+		t.Errorf("mark all read again = %d, want 0", updated)
+	}
+	select {
+	case event := <-bobStream.Events():
+		t.Errorf("bob received alice's %s event", event.Type)
+	default:
+	}
+}
+
+func TestPruneNotifications(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	notifications := NewNotificationService(db, NewEventBroker())
+
+	now := time.Now()
+	readAt := now.Add(-40 * 24 * time.Hour)
+	rows := map[string]models.Notification{
+		"recent read":  {CreatedAt: now.Add(-10 * 24 * time.Hour), ReadAt: &now},
+		"old read":     {CreatedAt: now.Add(-40 * 24 * time.Hour), ReadAt: &readAt},
+		"old unread":   {CreatedAt: now.Add(-40 * 24 * time.Hour)},
+		"stale unread": {CreatedAt: now.Add(-100 * 24 * time.Hour)},
+	}
+	for title, row := range rows {
+		row.UserID, row.Kind, row.Title = user.ID, models.NotificationReminder, title
+		if err := db.Create(&row).Error; err != nil {
+			t.Fatalf("create notification: %v", err)
+		}
+	}
+
+	if err := notifications.PruneNotifications(); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	var kept []string
+	db.Model(&models.Notification{}).Order("title ASC").Pluck("title", &kept)
+	if len(kept) != 2 || kept[0] != "old unread" || kept[1] != "recent read" {
+		t.Errorf("kept %v, want the recent read and old unread notifications", kept)
+	}
+}
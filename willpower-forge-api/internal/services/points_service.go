@@ -0,0 +1,294 @@
+package services
+
+import (
+	"errors"
+	"math"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+const (
+	// xpPerLevel scales the level curve: reaching level L takes
+	// xpPerLevel * (L-1)^2 XP in total.
+	xpPerLevel = 100
+	// xpStreakCap is the streak length at which the streak bonus stops
+	// growing. At the cap a check-in earns twice its base XP.
+	xpStreakCap = 30
+	// freezeEveryDays is how many consecutive completed days earn a freeze.
+	freezeEveryDays = 7
+	// maxFreezes is the most freezes a user can hold at once.
+	maxFreezes = 3
+)
+
+var (
+	ErrNoFreezes        = errors.New("no streak freezes left")
+	ErrFreezeNotAllowed = errors.New("date cannot be frozen")
+	ErrAlreadyFrozen    = errors.New("date already frozen")
+)
+
+// xpBase is the XP a check-in status earns on a medium goal.
+var xpBase = map[string]float64{
+	"completed": 10,
+	"partial":   5,
+}
+
+// xpDifficulty weights XP by the goal's difficulty.
+var xpDifficulty = map[string]float64{
+	models.DifficultyEasy:   0.5,
+	models.DifficultyMedium: 1,
+	models.DifficultyHard:   2,
+}
+
+// PointsBalance is a user's XP, level and streak freezes. LevelXP and
+// NextLevelXP are the total XP at which the current and next level start.
+type PointsBalance struct {
+	XP          int `json:"xp"`
+	Level       int `json:"level"`
+	LevelXP     int `json:"level_xp"`
+	NextLevelXP int `json:"next_level_xp"`
+	Freezes     int `json:"freezes"`
+	MaxFreezes  int `json:"max_freezes"`
+}
+
+// PointsService keeps the points ledger. Check-ins award XP and freezes
+// through AwardCheckIn; spending a freeze protects a missed day.
+type PointsService struct {
+	db *gorm.DB
+}
+
+func NewPointsService(db *gorm.DB) *PointsService {
+	return &PointsService{db: db}
+}
+
+// WithTx returns a PointsService that writes the ledger inside tx.
+func (s *PointsService) WithTx(tx *gorm.DB) *PointsService {
+	return &PointsService{db: tx}
+}
+
+// CheckInXP returns the XP a check-in earns: the status's base XP, weighted
+// by difficulty, plus a bonus growing with the streak up to the base again.
+func CheckInXP(status, difficulty string, streak int) int {
+	weight, ok := xpDifficulty[difficulty]
+	if !ok {
+		weight = 1
+	}
+	if streak > xpStreakCap {
+		streak = xpStreakCap
+	}
+	return int(math.Round(xpBase[status] * weight * (1 + float64(streak)/xpStreakCap)))
+}
+
+// LevelFor returns the level reached with the given XP, starting at 1.
+func LevelFor(xp int) int {
+	if xp <= 0 {
+		return 1
+	}
+	return int(math.Sqrt(float64(xp)/xpPerLevel)) + 1
+}
+
+func levelStart(level int) int {
+	return xpPerLevel * (level - 1) * (level - 1)
+}
+
+// AwardCheckIn records the XP for a newly stored check-in. Only the latest
+// check-in of a day counts, so a later check-in for the same day records
+// the difference to what the day already earned. Completing a multiple of
+// freezeEveryDays in a row also earns a freeze, up to maxFreezes.
+func (s *PointsService) AwardCheckIn(goal models.Goal, checkIn models.CheckIn) error {
+	streak, err := s.streakOn(goal, checkIn.Date)
+	if err != nil {
+		return err
+	}
+
+	var awarded int
+	if err := s.db.Model(&models.PointsEntry{}).
+		Where("goal_id = ? AND date = ? AND currency = ? AND reason = ?",
+			goal.ID, checkIn.Date, models.CurrencyXP, models.PointsReasonCheckIn).
+		Select("COALESCE(SUM(amount), 0)").Scan(&awarded).Error; err != nil {
+		return err
+	}
+
+	if delta := CheckInXP(checkIn.Status, goal.Difficulty, streak) - awarded; delta != 0 {
+		if err := s.db.Create(&models.PointsEntry{
+			UserID:    goal.UserID,
+			Currency:  models.CurrencyXP,
+			Amount:    delta,
+			Reason:    models.PointsReasonCheckIn,
+			GoalID:    &goal.ID,
+			CheckInID: &checkIn.ID,
+			Date:      checkIn.Date,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	if checkIn.Status != "completed" || streak == 0 || streak%freezeEveryDays != 0 {
+		return nil
+	}
+
+	var earned int64
+	if err := s.db.Model(&models.PointsEntry{}).
+		Where("goal_id = ? AND date = ? AND reason = ?", goal.ID, checkIn.Date, models.PointsReasonFreezeEarned).
+		Count(&earned).Error; err != nil {
+		return err
+	}
+	freezes, err := s.sum(goal.UserID, models.CurrencyFreeze)
+	if err != nil {
+		return err
+	}
+	if earned > 0 || freezes >= maxFreezes {
+		return nil
+	}
+
+	return s.db.Create(&models.PointsEntry{
+		UserID:    goal.UserID,
+		Currency:  models.CurrencyFreeze,
+		Amount:    1,
+		Reason:    models.PointsReasonFreezeEarned,
+		GoalID:    &goal.ID,
+		CheckInID: &checkIn.ID,
+		Date:      checkIn.Date,
+	}).Error
+}
+
+// streakOn returns the goal's current streak as of date, counting the
+// check-ins stored so far.
+func (s *PointsService) streakOn(goal models.Goal, date string) (int, error) {
+	if err := s.db.Where("goal_id = ?", goal.ID).Find(&goal.Pauses).Error; err != nil {
+		return 0, err
+	}
+	if err := s.db.Where("goal_id = ?", goal.ID).Find(&goal.StreakFreezes).Error; err != nil {
+		return 0, err
+	}
+	var vacations []models.VacationPeriod
+	if err := s.db.Where("user_id = ?", goal.UserID).Find(&vacations).Error; err != nil {
+		return 0, err
+	}
+	var checkIns []models.CheckIn
+	if err := s.db.Where("goal_id = ? AND date <= ?", goal.ID, date).Find(&checkIns).Error; err != nil {
+		return 0, err
+	}
+
+	return ComputeStreaks(LatestStatusByDate(checkIns), goal.EffectiveStartDate(), date,
+		NeutralDates(goal, vacations)).CurrentStreak, nil
+}
+
+// Balance returns the user's XP, level and freezes.
+func (s *PointsService) Balance(userID uint) (PointsBalance, error) {
+	xp, err := s.sum(userID, models.CurrencyXP)
+	if err != nil {
+		return PointsBalance{}, err
+	}
+	freezes, err := s.sum(userID, models.CurrencyFreeze)
+	if err != nil {
+		return PointsBalance{}, err
+	}
+
+	level := LevelFor(xp)
+	return PointsBalance{
+		XP:          xp,
+		Level:       level,
+		LevelXP:     levelStart(level),
+		NextLevelXP: levelStart(level + 1),
+		Freezes:     freezes,
+		MaxFreezes:  maxFreezes,
+	}, nil
+}
+
+// History returns a page of the user's ledger, newest first.
+func (s *PointsService) History(userID uint, limit, offset int) ([]models.PointsEntry, int64, error) {
+	query := s.db.Model(&models.PointsEntry{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.PointsEntry
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// SpendFreeze spends one of the user's freezes to protect the goal's streak
+// on a past day it was due and has no check-in other than a missed one.
+func (s *PointsService) SpendFreeze(goal models.Goal, date string) (models.StreakFreeze, error) {
+	today, err := UserToday(s.db, goal.UserID)
+	if err != nil {
+		return models.StreakFreeze{}, err
+	}
+	if date >= today || date < goal.EffectiveStartDate() || (goal.EndDate != "" && date > goal.EndDate) {
+		return models.StreakFreeze{}, ErrFreezeNotAllowed
+	}
+
+	freeze := models.StreakFreeze{GoalID: goal.ID, UserID: goal.UserID, Date: date}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.StreakFreeze{}).Where("goal_id = ? AND date = ?", goal.ID, date).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrAlreadyFrozen
+		}
+
+		if err := tx.Where("goal_id = ?", goal.ID).Find(&goal.Pauses).Error; err != nil {
+			return err
+		}
+		var vacations []models.VacationPeriod
+		if err := tx.Where("user_id = ?", goal.UserID).Find(&vacations).Error; err != nil {
+			return err
+		}
+		if NeutralDates(goal, vacations)(date) {
+			return ErrFreezeNotAllowed
+		}
+
+		var checkIns int64
+		if err := tx.Model(&models.CheckIn{}).Where("goal_id = ? AND date = ? AND status <> ?", goal.ID, date, models.CheckInStatusMissed).
+			Count(&checkIns).Error; err != nil {
+			return err
+		}
+		if checkIns > 0 {
+			return ErrFreezeNotAllowed
+		}
+		// The frozen day no longer counts as missed.
+		if err := tx.Where("goal_id = ? AND date = ? AND status = ?", goal.ID, date, models.CheckInStatusMissed).
+			Delete(&models.CheckIn{}).Error; err != nil {
+			return err
+		}
+
+		balance, err := s.WithTx(tx).sum(goal.UserID, models.CurrencyFreeze)
+		if err != nil {
+			return err
+		}
+		if balance < 1 {
+			return ErrNoFreezes
+		}
+
+		if err := tx.Create(&freeze).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PointsEntry{
+			UserID:   goal.UserID,
+			Currency: models.CurrencyFreeze,
+			Amount:   -1,
+			Reason:   models.PointsReasonFreezeSpent,
+			GoalID:   &goal.ID,
+			Date:     date,
+		}).Error
+	})
+	if err != nil {
+		return models.StreakFreeze{}, err
+	}
+	return freeze, nil
+}
+
+func (s *PointsService) sum(userID uint, currency string) (int, error) {
+	var total int
+	err := s.db.Model(&models.PointsEntry{}).Where("user_id = ? AND currency = ?", userID, currency).
+		Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+	return total, err
+}
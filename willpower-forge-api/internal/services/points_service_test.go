@@ -0,0 +1,138 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+func TestCheckInXP(t *testing.T) {
+	tests := []struct {
+		status, difficulty string
+		streak, want       int
+	}{
+		{"completed", models.DifficultyMedium, 0, 10},
+		{"completed", models.DifficultyHard, xpStreakCap, 40},
+		{"completed", models.DifficultyEasy, 2 * xpStreakCap, 10},
+		{"partial", models.DifficultyMedium, 15, 8},
+		{"completed", "", 3, 11},
+		{"failed", models.DifficultyHard, 10, 0},
+		{"excused", models.DifficultyMedium, 10, 0},
+	}
+	for _, tt := range tests {
+		if got := CheckInXP(tt.status, tt.difficulty, tt.streak); got != tt.want {
+			t.Errorf("CheckInXP(%s, %q, %d) = %d, want %d", tt.status, tt.difficulty, tt.streak, got, tt.want)
+		}
+	}
+}
+
+func TestLevelFor(t *testing.T) {
+	for xp, want := range map[int]int{-5: 1, 0: 1, 99: 1, 100: 2, 399: 2, 400: 3, 900: 4} {
+		if got := LevelFor(xp); got != want {
+			t.Errorf("LevelFor(%d) = %d, want %d", xp, got, want)
+		}
+	}
+}
+
+// recordCompletedDays checks the goal in as completed on days start to end
+// before today.
+func recordCompletedDays(t *testing.T, checkIns *CheckInService, goal models.Goal, today string, start, end int) {
+	t.Helper()
+	for offset := start; offset >= end; offset-- {
+		if _, err := checkIns.RecordCheckIn(goal, CheckInInput{Date: addDays(today, -offset), Status: "completed"}); err != nil {
+			t.Fatalf("record check-in %d days ago: %v", offset, err)
+		}
+	}
+}
+
+func TestAwardCheckInLedger(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{Timezone: "UTC"})
+	today := TodayIn("UTC")
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: addDays(today, -10), Difficulty: models.DifficultyMedium})
+	points := NewPointsService(db)
+	checkIns := NewCheckInService(db, points)
+
+	// Streaks of 1 to 7 earn 10, 11, 11, 11, 12, 12 and 12 XP.
+	recordCompletedDays(t, checkIns, goal, today, 10, 4)
+	balance, err := points.Balance(user.ID)
+	if err != nil {
+		t.Fatalf("balance: %v", err)
+	}
+	if balance.XP != 79 || balance.Level != 1 || balance.NextLevelXP != 100 {
+		t.Errorf("balance = %+v, want 79 XP at level 1", balance)
+	}
+	if balance.Freezes != 1 {
+		t.Errorf("freezes = %d, want one for the 7-day streak", balance.Freezes)
+	}
+
+	// Changing a day's check-in records only the difference.
+	if _, err := checkIns.RecordCheckIn(goal, CheckInInput{Date: addDays(today, -4), Status: "failed"}); err != nil {
+		t.Fatalf("record check-in: %v", err)
+	}
+	if balance, _ = points.Balance(user.ID); balance.XP != 67 {
+		t.Errorf("XP after failing the last day = %d, want 67", balance.XP)
+	}
+	if balance.Freezes != 1 {
+		t.Errorf("freezes = %d, want the earned freeze kept", balance.Freezes)
+	}
+
+	entries, total, err := points.History(user.ID, 2, 0)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if total != 9 || len(entries) != 2 || entries[0].Amount != -12 || entries[1].Currency != models.CurrencyFreeze {
+		t.Errorf("history = %d entries, newest %+v, want 9 with the -12 correction first", total, entries)
+	}
+}
+
+func TestSpendFreeze(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{Timezone: "UTC"})
+	today := TodayIn("UTC")
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: addDays(today, -10), Difficulty: models.DifficultyMedium})
+	points := NewPointsService(db)
+	checkIns := NewCheckInService(db, points)
+	recordCompletedDays(t, checkIns, goal, today, 10, 4)
+
+	gap := addDays(today, -3)
+	if err := db.Create(&models.CheckIn{GoalID: goal.ID, UserID: user.ID, Date: gap, Status: models.CheckInStatusMissed}).Error; err != nil {
+		t.Fatalf("create missed check-in: %v", err)
+	}
+
+	for _, date := range []string{today, addDays(today, -5), addDays(today, -11)} {
+		if _, err := points.SpendFreeze(goal, date); !errors.Is(err, ErrFreezeNotAllowed) {
+			t.Errorf("freeze %s: got %v, want ErrFreezeNotAllowed", date, err)
+		}
+	}
+
+	if _, err := points.SpendFreeze(goal, gap); err != nil {
+		t.Fatalf("spend freeze: %v", err)
+	}
+	var missed int64
+	db.Model(&models.CheckIn{}).Where("goal_id = ? AND date = ?", goal.ID, gap).Count(&missed)
+	if missed != 0 {
+		t.Error("the frozen day still has its missed check-in")
+	}
+	if _, err := points.SpendFreeze(goal, gap); !errors.Is(err, ErrAlreadyFrozen) {
+		t.Errorf("freeze the same day again: got %v, want ErrAlreadyFrozen", err)
+	}
+	if _, err := points.SpendFreeze(goal, addDays(today, -2)); !errors.Is(err, ErrNoFreezes) {
+		t.Errorf("freeze without freezes left: got %v, want ErrNoFreezes", err)
+	}
+
+	// The frozen day bridges the streak: the next day is the 8th in a row.
+	checkIn, err := checkIns.RecordCheckIn(goal, CheckInInput{Date: addDays(today, -2), Status: "completed"})
+	if err != nil {
+		t.Fatalf("record check-in: %v", err)
+	}
+	var entry models.PointsEntry
+	if err := db.Where("check_in_id = ? AND currency = ?", checkIn.ID, models.CurrencyXP).First(&entry).Error; err != nil {
+		t.Fatalf("load XP entry: %v", err)
+	}
+	if want := CheckInXP("completed", models.DifficultyMedium, 8); entry.Amount != want {
+		t.Errorf("XP after the frozen day = %d, want %d for an 8-day streak", entry.Amount, want)
+	}
+}
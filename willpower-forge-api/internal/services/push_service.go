@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/notify"
+)
+
+// defaultVAPIDSubject is the contact sent to push services when
+// VAPID_SUBJECT is not set.
+const defaultVAPIDSubject = "mailto:willpower-forge@localhost"
+
+var (
+	ErrNoPushSubscriptions = errors.New("no push subscriptions")
+	ErrInvalidSubscription = errors.New("invalid push subscription")
+)
+
+// pushPayload is the JSON the service worker receives and shows as a
+// notification.
+type pushPayload struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url"`
+	Tag   string `json:"tag,omitempty"`
+}
+
+// PushService keeps users' Web Push subscriptions and sends to them.
+type PushService struct {
+	db     *gorm.DB
+	sender *notify.WebPushSender
+}
+
+// NewPushService loads the VAPID key from VAPID_PRIVATE_KEY, or from the
+// database, generating and storing one on first start. VAPID_SUBJECT sets
+// the contact URI push services see.
+func NewPushService(db *gorm.DB) (*PushService, error) {
+	key, err := loadVAPIDKey(db)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := os.Getenv("VAPID_SUBJECT")
+	if subject == "" {
+		subject = defaultVAPIDSubject
+	}
+	return &PushService{db: db, sender: notify.NewWebPushSender(key, subject)}, nil
+}
+
+func loadVAPIDKey(db *gorm.DB) (*ecdsa.PrivateKey, error) {
+	if encoded := os.Getenv("VAPID_PRIVATE_KEY"); encoded != "" {
+		return notify.DecodeVAPIDPrivateKey(encoded)
+	}
+
+	var stored models.VapidKey
+	err := db.Order("id ASC").First(&stored).Error
+	if err == nil {
+		return notify.DecodeVAPIDPrivateKey(stored.PrivateKey)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	key, err := notify.GenerateVAPIDKey()
+	if err != nil {
+		return nil, err
+	}
+	stored = models.VapidKey{
+		PublicKey:  notify.EncodeVAPIDPublicKey(key),
+		PrivateKey: notify.EncodeVAPIDPrivateKey(key),
+	}
+	if err := db.Create(&stored).Error; err != nil {
+		return nil, err
+	}
+	log.Println("Generated a new VAPID key for Web Push")
+	return key, nil
+}
+
+// PublicKey returns the VAPID public key browsers subscribe with.
+func (s *PushService) PublicKey() string {
+	return notify.EncodeVAPIDPublicKey(s.sender.Key)
+}
+
+// Subscribe stores a browser's subscription for the user. Subscribing an
+// endpoint again refreshes its keys and moves it to the current user, since
+// an endpoint belongs to one browser profile. Push services are only
+// reached over HTTPS, and never at internal addresses.
+func (s *PushService) Subscribe(userID uint, endpoint, p256dh, auth, device string) (models.PushSubscription, error) {
+	sub := notify.PushSubscription{Endpoint: endpoint, P256dh: p256dh, Auth: auth}
+	if !notify.ValidURL(endpoint) || !strings.HasPrefix(endpoint, "https://") || notify.ValidateSubscriptionKeys(sub) != nil {
+		return models.PushSubscription{}, ErrInvalidSubscription
+	}
+
+	var subscription models.PushSubscription
+	err := s.db.Where("endpoint = ?", endpoint).First(&subscription).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.PushSubscription{}, err
+	}
+
+	subscription.UserID = userID
+	subscription.Endpoint = endpoint
+	subscription.P256dh = p256dh
+	subscription.Auth = auth
+	subscription.Device = device
+	if err := s.db.Save(&subscription).Error; err != nil {
+		return models.PushSubscription{}, err
+	}
+	return subscription, nil
+}
+
+// List returns the user's subscribed devices.
+func (s *PushService) List(userID uint) ([]models.PushSubscription, error) {
+	var subscriptions []models.PushSubscription
+	err := s.db.Where("user_id = ?", userID).Order("id ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// Unsubscribe removes one of the user's subscriptions.
+func (s *PushService) Unsubscribe(userID, id uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.PushSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SendToUser pushes msg to every device the user subscribed and returns how
+// many received it. Subscriptions the push service reports as gone are
+// removed. It fails when no device received the message.
+func (s *PushService) SendToUser(ctx context.Context, userID uint, msg notify.Message, tag string) (int, error) {
+	subscriptions, err := s.List(userID)
+	if err != nil {
+		return 0, err
+	}
+	if len(subscriptions) == 0 {
+		return 0, ErrNoPushSubscriptions
+	}
+
+	link := msg.Link
+	if link == "" {
+		link = "/"
+	}
+	payload, err := json.Marshal(pushPayload{Title: msg.Title, Body: msg.Body, URL: link, Tag: tag})
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var lastErr error
+	for _, subscription := range subscriptions {
+		err := s.sender.Send(ctx, notify.PushSubscription{
+			Endpoint: subscription.Endpoint,
+			P256dh:   subscription.P256dh,
+			Auth:     subscription.Auth,
+		}, payload)
+		switch {
+		case err == nil:
+			sent++
+			now := time.Now()
+			if err := s.db.Model(&subscription).Update("last_used_at", &now).Error; err != nil {
+				log.Printf("Error updating push subscription %d: %v", subscription.ID, err)
+			}
+		case errors.Is(err, notify.ErrSubscriptionGone):
+			lastErr = err
+			if err := s.db.Delete(&subscription).Error; err != nil {
+				log.Printf("Error removing expired push subscription %d: %v", subscription.ID, err)
+			}
+		default:
+			lastErr = err
+		}
+	}
+
+	if sent == 0 {
+		return 0, lastErr
+	}
+	return sent, nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/notify"
+)
+
+// A user agent key pair and auth secret from RFC 8291, Appendix A.
+const (
+	testPushP256dh = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	testPushAuth   = "BTBZMqHH6r4Tts7J_aSIgg"
+)
+
+func TestSubscribeValidatesEndpoint(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	push, err := NewPushService(db)
+	if err != nil {
+		t.Fatalf("new push service: %v", err)
+	}
+
+	for _, endpoint := range []string{"http://push.example.com/abc", "ftp://push.example.com/abc", "push.example.com/abc"} {
+		if _, err := push.Subscribe(user.ID, endpoint, testPushP256dh, testPushAuth, ""); !errors.Is(err, ErrInvalidSubscription) {
+			t.Errorf("Subscribe(%q) = %v, want ErrInvalidSubscription", endpoint, err)
+		}
+	}
+	if _, err := push.Subscribe(user.ID, "https://push.example.com/abc", testPushP256dh, "short", ""); !errors.Is(err, ErrInvalidSubscription) {
+		t.Errorf("Subscribe with a bad auth secret = %v, want ErrInvalidSubscription", err)
+	}
+	if _, err := push.Subscribe(user.ID, "https://push.example.com/abc", testPushP256dh, testPushAuth, "Laptop"); err != nil {
+		t.Errorf("Subscribe with a valid subscription: %v", err)
+	}
+}
+
+func TestSendToUserRemovesGoneSubscriptions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/gone"):
+			w.WriteHeader(http.StatusGone)
+		case strings.HasSuffix(r.URL.Path, "/unknown"):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	push, err := NewPushService(db)
+	if err != nil {
+		t.Fatalf("new push service: %v", err)
+	}
+	push.sender.Client = server.Client()
+
+	for _, path := range []string{"/active", "/gone", "/unknown"} {
+		if _, err := push.Subscribe(user.ID, server.URL+path, testPushP256dh, testPushAuth, ""); err != nil {
+			t.Fatalf("subscribe %s: %v", path, err)
+		}
+	}
+
+	sent, err := push.SendToUser(context.Background(), user.ID, notify.Message{Title: "Hi", Body: "Test"}, "test")
+	if err != nil || sent != 1 {
+		t.Fatalf("SendToUser = %d, %v; want 1 sent", sent, err)
+	}
+
+	remaining, err := push.List(user.ID)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(remaining) != 1 || !strings.HasSuffix(remaining[0].Endpoint, "/active") || remaining[0].LastUsedAt == nil {
+		t.Errorf("remaining subscriptions = %+v, want only the active one, marked used", remaining)
+	}
+
+	// With only gone subscriptions left, sending fails.
+	if _, err := push.Subscribe(user.ID, server.URL+"/gone", testPushP256dh, testPushAuth, ""); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	push.Unsubscribe(user.ID, remaining[0].ID)
+	if _, err := push.SendToUser(context.Background(), user.ID, notify.Message{Title: "Hi"}, ""); !errors.Is(err, notify.ErrSubscriptionGone) {
+		t.Errorf("sending to gone subscriptions: got %v, want ErrSubscriptionGone", err)
+	}
+}
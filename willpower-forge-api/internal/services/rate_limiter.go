@@ -0,0 +1,54 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter allows up to limit events per key in fixed windows. State is
+// kept in memory, so limits reset when the server restarts.
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, windows: make(map[string]*rateWindow)}
+}
+
+// Allow counts an event for key and reports whether it is within the limit.
+func (l *rateLimiter) Allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current, ok := l.windows[key]
+	if !ok || now.Sub(current.start) >= l.window {
+		if !ok && len(l.windows) >= 10000 {
+			l.prune(now)
+		}
+		current = &rateWindow{start: now}
+		l.windows[key] = current
+	}
+	if current.count >= l.limit {
+		return false
+	}
+	current.count++
+	return true
+}
+
+// prune drops expired windows so the map does not grow with every key seen.
+func (l *rateLimiter) prune(now time.Time) {
+	for key, current := range l.windows {
+		if now.Sub(current.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"math"
+	"sort"
+
+	"willpower-forge-api/internal/models"
+)
+
+// ratingOutcome scores a check-in status for correlation: completed days
+// count as 1, partial days as half and failed days as 0. Other statuses, such
+// as excused, are left out of rating analytics.
+func ratingOutcome(status string) (float64, bool) {
+	switch status {
+	case "completed":
+		return 1, true
+	case "partial":
+		return 0.5, true
+	case "failed":
+		return 0, true
+	}
+	return 0, false
+}
+
+// RatingBucket aggregates the judged days on which a rating had one value.
+type RatingBucket struct {
+	Value          int     `json:"value"`
+	Days           int     `json:"days"`
+	Completed      int     `json:"completed"`
+	Partial        int     `json:"partial"`
+	Failed         int     `json:"failed"`
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+// RatingAnalytics relates one rating dimension to the goal's outcomes.
+// Correlation is the Pearson coefficient between the rating and the day's
+// outcome score; it is nil when there are fewer than two rated days or either
+// side never varies. A negative correlation for stress, for instance, means
+// that stressful days tend to end in failure.
+type RatingAnalytics struct {
+	Dimension        string         `json:"dimension"`
+	RatedDays        int            `json:"rated_days"`
+	AverageCompleted *float64       `json:"average_when_completed"`
+	AverageFailed    *float64       `json:"average_when_failed"`
+	Correlation      *float64       `json:"correlation"`
+	ByValue          []RatingBucket `json:"by_value"`
+}
+
+// GoalRatingAnalytics holds the rating analytics of one goal.
+type GoalRatingAnalytics struct {
+	GoalID  uint              `json:"goal_id"`
+	Title   string            `json:"title"`
+	Ratings []RatingAnalytics `json:"ratings"`
+}
+
+// AnalyzeRatings relates the ratings of a goal's check-ins to its outcomes.
+// Only the last check-in of each day is considered.
+func AnalyzeRatings(checkIns []models.CheckIn) []RatingAnalytics {
+	latest := LatestByDate(checkIns)
+	dates := make([]string, 0, len(latest))
+	for date := range latest {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	results := make([]RatingAnalytics, 0, len(models.RatingDimensions))
+	for _, dimension := range models.RatingDimensions {
+		analytics := RatingAnalytics{
+			Dimension: dimension,
+			ByValue:   make([]RatingBucket, models.RatingMax-models.RatingMin+1),
+		}
+		for idx := range analytics.ByValue {
+			analytics.ByValue[idx].Value = models.RatingMin + idx
+		}
+
+		var ratings, outcomes []float64
+		var completedSum, failedSum float64
+		var completedDays, failedDays int
+		for _, date := range dates {
+			checkIn := latest[date]
+			rating := checkIn.Rating(dimension)
+			if rating == nil {
+				continue
+			}
+			outcome, judged := ratingOutcome(checkIn.Status)
+			if !judged {
+				continue
+			}
+
+			bucket := &analytics.ByValue[*rating-models.RatingMin]
+			bucket.Days++
+			switch checkIn.Status {
+			case "completed":
+				bucket.Completed++
+				completedSum += float64(*rating)
+				completedDays++
+			case "partial":
+				bucket.Partial++
+			case "failed":
+				bucket.Failed++
+				failedSum += float64(*rating)
+				failedDays++
+			}
+
+			ratings = append(ratings, float64(*rating))
+			outcomes = append(outcomes, outcome)
+		}
+
+		for idx := range analytics.ByValue {
+			if bucket := &analytics.ByValue[idx]; bucket.Days > 0 {
+				bucket.CompletionRate = float64(bucket.Completed) / float64(bucket.Days)
+			}
+		}
+		if completedDays > 0 {
+			average := completedSum / float64(completedDays)
+			analytics.AverageCompleted = &average
+		}
+		if failedDays > 0 {
+			average := failedSum / float64(failedDays)
+			analytics.AverageFailed = &average
+		}
+		analytics.RatedDays = len(ratings)
+		analytics.Correlation = pearson(ratings, outcomes)
+
+		results = append(results, analytics)
+	}
+	return results
+}
+
+// pearson returns the correlation coefficient of two equally long samples,
+// or nil if it is undefined.
+func pearson(xs, ys []float64) *float64 {
+	n := float64(len(xs))
+	if len(xs) < 2 {
+		return nil
+	}
+
+	var sumX, sumY float64
+	for idx := range xs {
+		sumX += xs[idx]
+		sumY += ys[idx]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX, varY float64
+	for idx := range xs {
+		dx, dy := xs[idx]-meanX, ys[idx]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return nil
+	}
+
+	r := cov / math.Sqrt(varX*varY)
+	return &r
+}
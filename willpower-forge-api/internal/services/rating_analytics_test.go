@@ -0,0 +1,66 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"willpower-forge-api/internal/models"
+)
+
+func TestAnalyzeRatings(t *testing.T) {
+	rating := func(value int) *int { return &value }
+	checkIns := []models.CheckIn{
+		{ID: 1, Date: "2026-03-01", Status: "completed", Stress: rating(1), Mood: rating(5)},
+		{ID: 2, Date: "2026-03-02", Status: "completed", Stress: rating(2)},
+		{ID: 3, Date: "2026-03-03", Status: "partial", Stress: rating(3)},
+		{ID: 4, Date: "2026-03-04", Status: "failed", Stress: rating(5)},
+		// Only the day's last check-in counts.
+		{ID: 5, Date: "2026-03-05", Status: "failed", Stress: rating(5)},
+		{ID: 6, Date: "2026-03-05", Status: "completed", Stress: rating(1)},
+		// Excused days and unrated days are left out.
+		{ID: 7, Date: "2026-03-06", Status: "excused", Stress: rating(5)},
+		{ID: 8, Date: "2026-03-07", Status: "completed"},
+	}
+
+	results := AnalyzeRatings(checkIns)
+	if len(results) != len(models.RatingDimensions) {
+		t.Fatalf("got %d dimensions, want %d", len(results), len(models.RatingDimensions))
+	}
+	byDimension := make(map[string]RatingAnalytics)
+	for _, result := range results {
+		byDimension[result.Dimension] = result
+	}
+
+	stress := byDimension["stress"]
+	if stress.RatedDays != 5 {
+		t.Errorf("stress rated days = %d, want 5", stress.RatedDays)
+	}
+	if stress.AverageCompleted == nil || math.Abs(*stress.AverageCompleted-4.0/3) > 1e-9 {
+		t.Errorf("stress average when completed = %v, want 4/3", stress.AverageCompleted)
+	}
+	if stress.AverageFailed == nil || *stress.AverageFailed != 5 {
+		t.Errorf("stress average when failed = %v, want 5", stress.AverageFailed)
+	}
+	if stress.Correlation == nil || math.Abs(*stress.Correlation+0.9688) > 1e-3 {
+		t.Errorf("stress correlation = %v, want about -0.9688", stress.Correlation)
+	}
+	wantBuckets := []RatingBucket{
+		{Value: 1, Days: 2, Completed: 2, CompletionRate: 1},
+		{Value: 2, Days: 1, Completed: 1, CompletionRate: 1},
+		{Value: 3, Days: 1, Partial: 1},
+		{Value: 4},
+		{Value: 5, Days: 1, Failed: 1},
+	}
+	for idx, want := range wantBuckets {
+		if got := stress.ByValue[idx]; got != want {
+			t.Errorf("stress bucket %d = %+v, want %+v", want.Value, got, want)
+		}
+	}
+
+	if mood := byDimension["mood"]; mood.RatedDays != 1 || mood.Correlation != nil || mood.AverageFailed != nil {
+		t.Errorf("mood = %+v, want one rated day without a correlation", mood)
+	}
+	if energy := byDimension["energy"]; energy.RatedDays != 0 || energy.AverageCompleted != nil || len(energy.ByValue) != models.RatingMax-models.RatingMin+1 {
+		t.Errorf("energy = %+v, want no rated days and empty buckets", energy)
+	}
+}
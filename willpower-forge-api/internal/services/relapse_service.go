@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+// relapseTrendWeeks is the number of weeks reported in the relapse frequency
+// trend. The trend compares the most recent half with the older half.
+const relapseTrendWeeks = 8
+
+var ErrRelapseNotFound = errors.New("relapse not found")
+
+// WeeklyRelapses counts the relapse occurrences in the week starting on
+// WeekStart (a Monday).
+type WeeklyRelapses struct {
+	WeekStart string `json:"week_start"`
+	Count     int    `json:"count"`
+}
+
+// AbstinenceSavings reports what staying clean has saved, in the goal's
+// cost unit.
+type AbstinenceSavings struct {
+	Unit     string  `json:"unit"`
+	Expected float64 `json:"expected"`
+	Spent    float64 `json:"spent"`
+	Saved    float64 `json:"saved"`
+}
+
+// AbstinenceStats is the abstinence timer of an I_WONT goal.
+type AbstinenceStats struct {
+	TrackingSince           time.Time          `json:"tracking_since"`
+	LastRelapseAt           *time.Time         `json:"last_relapse_at"`
+	SecondsSinceLastRelapse int64              `json:"seconds_since_last_relapse"`
+	DaysSinceLastRelapse    int                `json:"days_since_last_relapse"`
+	LongestCleanSeconds     int64              `json:"longest_clean_seconds"`
+	LongestCleanDays        int                `json:"longest_clean_days"`
+	RelapseCount            int                `json:"relapse_count"`
+	WeeklyRelapses          []WeeklyRelapses   `json:"weekly_relapses"`
+	Trend                   string             `json:"trend"`
+	Savings                 *AbstinenceSavings `json:"savings,omitempty"`
+}
+
+type RelapseService struct {
+	db *gorm.DB
+}
+
+func NewRelapseService(db *gorm.DB) *RelapseService {
+	return &RelapseService{db: db}
+}
+
+// LogRelapse records a relapse for the goal. A zero occurredAt means now and
+// a quantity below one counts as a single occurrence.
+func (s *RelapseService) LogRelapse(goal models.Goal, occurredAt time.Time, quantity int, note string) (models.Relapse, error) {
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+	if quantity < 1 {
+		quantity = 1
+	}
+
+	relapse := models.Relapse{
+		GoalID:     goal.ID,
+		UserID:     goal.UserID,
+		OccurredAt: occurredAt,
+		Quantity:   quantity,
+		Note:       note,
+	}
+
+	if err := s.db.Create(&relapse).Error; err != nil {
+		return models.Relapse{}, err
+	}
+	return relapse, nil
+}
+
+// ListRelapses returns the goal's relapses, newest first.
+func (s *RelapseService) ListRelapses(goalID uint) ([]models.Relapse, error) {
+	var relapses []models.Relapse
+	if err := s.db.Where("goal_id = ?", goalID).Order("occurred_at DESC").Find(&relapses).Error; err != nil {
+		return nil, err
+	}
+	return relapses, nil
+}
+
+// DeleteRelapse removes one of the goal's relapses.
+func (s *RelapseService) DeleteRelapse(goalID, relapseID uint) error {
+	result := s.db.Where("id = ? AND goal_id = ?", relapseID, goalID).Delete(&models.Relapse{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRelapseNotFound
+	}
+	return nil
+}
+
+// Abstinence loads the goal's relapses and computes its abstinence timer.
+func (s *RelapseService) Abstinence(goal models.Goal) (AbstinenceStats, error) {
+	relapses, err := s.ListRelapses(goal.ID)
+	if err != nil {
+		return AbstinenceStats{}, err
+	}
+	loc, err := UserLocationOf(s.db, goal.UserID)
+	if err != nil {
+		return AbstinenceStats{}, err
+	}
+	return ComputeAbstinence(goal, relapses, time.Now().In(loc)), nil
+}
+
+// ComputeAbstinence derives the time since the last relapse, the longest
+// clean period, the weekly relapse trend and, if the goal has cost tracking,
+// the money or time saved as of now.
+func ComputeAbstinence(goal models.Goal, relapses []models.Relapse, now time.Time) AbstinenceStats {
+	sorted := make([]models.Relapse, len(relapses))
+	copy(sorted, relapses)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].OccurredAt.Before(sorted[j].OccurredAt)
+	})
+
+	trackingSince, err := time.ParseInLocation(dateLayout, goal.EffectiveStartDate(), now.Location())
+	if err != nil || (len(sorted) > 0 && sorted[0].OccurredAt.Before(trackingSince)) {
+		trackingSince = goal.CreatedAt
+		if len(sorted) > 0 && sorted[0].OccurredAt.Before(trackingSince) {
+			trackingSince = sorted[0].OccurredAt
+		}
+	}
+
+	stats := AbstinenceStats{TrackingSince: trackingSince}
+
+	cleanStart := trackingSince
+	var longest time.Duration
+	for _, relapse := range sorted {
+		if gap := relapse.OccurredAt.Sub(cleanStart); gap > longest {
+			longest = gap
+		}
+		cleanStart = relapse.OccurredAt
+		stats.RelapseCount += relapse.Quantity
+	}
+
+	current := now.Sub(cleanStart)
+	if current < 0 {
+		current = 0
+	}
+	if current > longest {
+		longest = current
+	}
+
+	if len(sorted) > 0 {
+		last := sorted[len(sorted)-1].OccurredAt
+		stats.LastRelapseAt = &last
+	}
+	stats.SecondsSinceLastRelapse = int64(current / time.Second)
+	stats.DaysSinceLastRelapse = int(current / (24 * time.Hour))
+	stats.LongestCleanSeconds = int64(longest / time.Second)
+	stats.LongestCleanDays = int(longest / (24 * time.Hour))
+
+	stats.WeeklyRelapses, stats.Trend = weeklyRelapseTrend(sorted, now)
+
+	if goal.CostPerOccurrence > 0 {
+		trackedDays := now.Sub(trackingSince).Hours() / 24
+		if trackedDays < 0 {
+			trackedDays = 0
+		}
+		savings := AbstinenceSavings{
+			Unit:     goal.CostUnit,
+			Expected: goal.BaselinePerDay * trackedDays * goal.CostPerOccurrence,
+			Spent:    float64(stats.RelapseCount) * goal.CostPerOccurrence,
+		}
+		savings.Saved = savings.Expected - savings.Spent
+		stats.Savings = &savings
+	}
+
+	return stats
+}
+
+// weeklyRelapseTrend buckets relapse occurrences into the last
+// relapseTrendWeeks Monday-based weeks, oldest first, and reports whether the
+// recent half has fewer ("improving"), more ("worsening") or the same
+// ("steady") occurrences than the older half.
+func weeklyRelapseTrend(relapses []models.Relapse, now time.Time) ([]WeeklyRelapses, string) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	offset := (int(today.Weekday()) + 6) % 7
+	currentWeek := today.AddDate(0, 0, -offset)
+	firstWeek := currentWeek.AddDate(0, 0, -7*(relapseTrendWeeks-1))
+
+	weeks := make([]WeeklyRelapses, relapseTrendWeeks)
+	for idx := range weeks {
+		weeks[idx].WeekStart = firstWeek.AddDate(0, 0, 7*idx).Format(dateLayout)
+	}
+
+	for _, relapse := range relapses {
+		occurred := relapse.OccurredAt.In(now.Location())
+		if occurred.Before(firstWeek) {
+			continue
+		}
+		idx := int(occurred.Sub(firstWeek).Hours() / (24 * 7))
+		if idx >= 0 && idx < relapseTrendWeeks {
+			weeks[idx].Count += relapse.Quantity
+		}
+	}
+
+	older, recent := 0, 0
+	for idx, week := range weeks {
+		if idx < relapseTrendWeeks/2 {
+			older += week.Count
+		} else {
+			recent += week.Count
+		}
+	}
+
+	switch {
+	case recent < older:
+		return weeks, "improving"
+	case recent > older:
+		return weeks, "worsening"
+	default:
+		return weeks, "steady"
+	}
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+func TestComputeAbstinence(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	goal := models.Goal{Type: "I_WONT", StartDate: "2026-03-02", CostPerOccurrence: 5, CostUnit: "USD", BaselinePerDay: 2}
+	relapses := []models.Relapse{
+		// 08:30 on Monday March 16th in Tokyo, still Sunday in UTC.
+		{OccurredAt: time.Date(2026, 3, 15, 23, 30, 0, 0, time.UTC), Quantity: 1},
+		{OccurredAt: time.Date(2026, 3, 5, 12, 0, 0, 0, tokyo), Quantity: 2},
+	}
+	now := time.Date(2026, 3, 30, 12, 0, 0, 0, tokyo)
+
+	stats := ComputeAbstinence(goal, relapses, now)
+	if want := time.Date(2026, 3, 2, 0, 0, 0, 0, tokyo); !stats.TrackingSince.Equal(want) {
+		t.Errorf("tracking since %v, want %v", stats.TrackingSince, want)
+	}
+	if stats.LastRelapseAt == nil || !stats.LastRelapseAt.Equal(relapses[0].OccurredAt) {
+		t.Errorf("last relapse at %v, want %v", stats.LastRelapseAt, relapses[0].OccurredAt)
+	}
+	current := 14*24*time.Hour + 3*time.Hour + 30*time.Minute
+	if stats.SecondsSinceLastRelapse != int64(current/time.Second) || stats.DaysSinceLastRelapse != 14 {
+		t.Errorf("since last relapse: %ds, %d days, want %v", stats.SecondsSinceLastRelapse, stats.DaysSinceLastRelapse, current)
+	}
+	if stats.LongestCleanSeconds != int64(current/time.Second) || stats.LongestCleanDays != 14 {
+		t.Errorf("longest clean: %ds, %d days, want the current run", stats.LongestCleanSeconds, stats.LongestCleanDays)
+	}
+	if stats.RelapseCount != 3 {
+		t.Errorf("relapse count = %d, want the quantities summed to 3", stats.RelapseCount)
+	}
+
+	if len(stats.WeeklyRelapses) != relapseTrendWeeks || stats.WeeklyRelapses[0].WeekStart != "2026-02-09" {
+		t.Fatalf("weeks = %+v, want %d weeks from 2026-02-09", stats.WeeklyRelapses, relapseTrendWeeks)
+	}
+	if week := stats.WeeklyRelapses[3]; week.WeekStart != "2026-03-02" || week.Count != 2 {
+		t.Errorf("week %s has %d relapses, want 2 in the week of 2026-03-02", week.WeekStart, week.Count)
+	}
+	if week := stats.WeeklyRelapses[5]; week.WeekStart != "2026-03-16" || week.Count != 1 {
+		t.Errorf("week %s has %d relapses, want the Monday relapse in the week of 2026-03-16", week.WeekStart, week.Count)
+	}
+	if stats.Trend != "improving" {
+		t.Errorf("trend = %s, want improving", stats.Trend)
+	}
+
+	if stats.Savings == nil {
+		t.Fatal("no savings for a goal with cost tracking")
+	}
+	// 28.5 days at 2 a day and 5 each, less the 3 relapses.
+	if stats.Savings.Expected != 285 || stats.Savings.Spent != 15 || stats.Savings.Saved != 270 {
+		t.Errorf("savings = %+v, want 285 expected, 15 spent, 270 saved", *stats.Savings)
+	}
+}
+
+func TestComputeAbstinenceWithoutRelapses(t *testing.T) {
+	goal := models.Goal{Type: "I_WONT", StartDate: "2026-03-01"}
+	now := time.Date(2026, 3, 11, 6, 0, 0, 0, time.UTC)
+
+	stats := ComputeAbstinence(goal, nil, now)
+	if stats.LastRelapseAt != nil || stats.RelapseCount != 0 || stats.DaysSinceLastRelapse != 10 || stats.LongestCleanDays != 10 {
+		t.Errorf("stats = %+v, want 10 clean days since the start", stats)
+	}
+	if stats.Trend != "steady" || stats.Savings != nil {
+		t.Errorf("trend %s, savings %v, want steady without savings", stats.Trend, stats.Savings)
+	}
+}
+
+func TestRelapseService(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{Timezone: "Asia/Tokyo"})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, Type: "I_WONT", StartDate: "2026-03-01"})
+	other := createTestGoal(t, db, models.Goal{UserID: user.ID, Type: "I_WONT", StartDate: "2026-03-01"})
+	relapses := NewRelapseService(db)
+
+	before := time.Now()
+	relapse, err := relapses.LogRelapse(goal, time.Time{}, 0, "party")
+	if err != nil {
+		t.Fatalf("log relapse: %v", err)
+	}
+	if relapse.Quantity != 1 || relapse.OccurredAt.Before(before) {
+		t.Errorf("relapse = %+v, want one occurrence now", relapse)
+	}
+
+	stats, err := relapses.Abstinence(goal)
+	if err != nil {
+		t.Fatalf("abstinence: %v", err)
+	}
+	if stats.RelapseCount != 1 || stats.TrackingSince.Location().String() != "Asia/Tokyo" {
+		t.Errorf("stats = %+v, want one relapse tracked in the user's timezone", stats)
+	}
+
+	if err := relapses.DeleteRelapse(other.ID, relapse.ID); !errors.Is(err, ErrRelapseNotFound) {
+		t.Errorf("delete through another goal: got %v, want ErrRelapseNotFound", err)
+	}
+	if err := relapses.DeleteRelapse(goal.ID, relapse.ID); err != nil {
+		t.Fatalf("delete relapse: %v", err)
+	}
+	if stats, _ := relapses.Abstinence(goal); stats.RelapseCount != 0 {
+		t.Errorf("relapse count = %d after deleting, want 0", stats.RelapseCount)
+	}
+}
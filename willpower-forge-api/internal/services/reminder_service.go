@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/notify"
+)
+
+const (
+	// reminderWindow is how late a reminder may still go out after its time,
+	// so a slot missed while the server was down or busy is not lost.
+	reminderWindow = 30 * time.Minute
+	// reminderSendTimeout bounds a single delivery attempt.
+	reminderSendTimeout = 30 * time.Second
+	slotLayout          = "2006-01-02 15:04"
+)
+
+var (
+	ErrChannelUnavailable = errors.New("notification channel not available")
+	ErrInvalidTarget      = errors.New("invalid notification target")
+	ErrInvalidTimes       = errors.New("invalid reminder times")
+)
+
+// ReminderService sends goal reminders through the notification dispatcher,
+// or over Web Push to all of the user's devices, and records every delivery
+// attempt. Scheduled reminders also land in the user's inbox.
+type ReminderService struct {
+	db            *gorm.DB
+	dispatcher    *notify.Dispatcher
+	push          *PushService
+	notifications *NotificationService
+}
+
+func NewReminderService(db *gorm.DB, dispatcher *notify.Dispatcher, push *PushService, notifications *NotificationService) *ReminderService {
+	return &ReminderService{db: db, dispatcher: dispatcher, push: push, notifications: notifications}
+}
+
+// NormalizeReminderTimes validates a comma separated list of "HH:MM" times
+// and returns it sorted without duplicates.
+func NormalizeReminderTimes(times string) (string, error) {
+	seen := make(map[string]bool)
+	var list []string
+	for _, part := range strings.Split(times, ",") {
+		part = strings.TrimSpace(part)
+		parsed, err := time.Parse("15:04", part)
+		if err != nil {
+			return "", ErrInvalidTimes
+		}
+		part = parsed.Format("15:04")
+		if !seen[part] {
+			seen[part] = true
+			list = append(list, part)
+		}
+	}
+	sort.Strings(list)
+	return strings.Join(list, ","), nil
+}
+
+// ValidateChannel checks that the channel is configured on this server and
+// that target is a valid address for it. Web Push takes no target.
+func (s *ReminderService) ValidateChannel(channel, target string) error {
+	if channel == notify.ChannelWebPush {
+		if s.push == nil {
+			return ErrChannelUnavailable
+		}
+		if target != "" {
+			return ErrInvalidTarget
+		}
+		return nil
+	}
+	if !s.dispatcher.Supports(channel) {
+		return ErrChannelUnavailable
+	}
+	if channel == notify.ChannelEmail {
+		if _, err := mail.ParseAddress(target); err != nil {
+			return ErrInvalidTarget
+		}
+		return nil
+	}
+	if !notify.ValidURL(target) {
+		return ErrInvalidTarget
+	}
+	return nil
+}
+
+// RunDueReminders sends every enabled reminder with a time that has come in
+// its user's timezone within the last reminderWindow. Reminders are skipped
+// on days the goal is not active, not due, paused, frozen or on vacation,
+// and, when asked, once the day has a check-in. Each slot is claimed before
+// sending so it goes out at most once even if runs overlap. Failed sends
+// are recorded as deliveries; the returned error only reports reminders that
+// could not be processed.
+func (s *ReminderService) RunDueReminders(now time.Time) error {
+	var reminders []models.Reminder
+	if err := s.db.Where("enabled = ?", true).Order("user_id, id").Find(&reminders).Error; err != nil {
+		return fmt.Errorf("loading reminders: %w", err)
+	}
+
+	users := make(map[uint]*models.User)
+	vacations := make(map[uint][]models.VacationPeriod)
+	failed := 0
+	for _, reminder := range reminders {
+		user, ok := users[reminder.UserID]
+		if !ok {
+			var loaded models.User
+			if err := s.db.First(&loaded, reminder.UserID).Error; err != nil {
+				log.Printf("Error loading user %d for reminders: %v", reminder.UserID, err)
+				failed++
+				continue
+			}
+			var periods []models.VacationPeriod
+			if err := s.db.Where("user_id = ?", reminder.UserID).Find(&periods).Error; err != nil {
+				log.Printf("Error loading vacations of user %d: %v", reminder.UserID, err)
+				failed++
+				continue
+			}
+			user = &loaded
+			users[reminder.UserID] = user
+			vacations[reminder.UserID] = periods
+		}
+
+		local := now.In(UserLocation(user.Timezone))
+		slot := dueSlot(reminder, local)
+		if slot == "" || slot <= reminder.LastSlot {
+			continue
+		}
+		date := local.Format(dateLayout)
+
+		var goal models.Goal
+		if err := s.db.Preload("Pauses").Preload("StreakFreezes").
+			Where("id = ? AND user_id = ?", reminder.GoalID, reminder.UserID).First(&goal).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Error loading goal %d for reminder %d: %v", reminder.GoalID, reminder.ID, err)
+				failed++
+			}
+			continue
+		}
+		if goal.Status != models.GoalStatusActive || !reminder.IsScheduledOn(date) ||
+			!judgeable(goal, date, NeutralDates(goal, vacations[reminder.UserID])) {
+			continue
+		}
+
+		if reminder.OnlyIfNotCheckedIn {
+			var checkIns int64
+			if err := s.db.Model(&models.CheckIn{}).Where("goal_id = ? AND date = ?", goal.ID, date).
+				Count(&checkIns).Error; err != nil {
+				log.Printf("Error checking check-ins for reminder %d: %v", reminder.ID, err)
+				failed++
+				continue
+			}
+			if checkIns > 0 {
+				continue
+			}
+		}
+
+		claim := s.db.Model(&models.Reminder{}).Where("id = ? AND last_slot < ?", reminder.ID, slot).
+			Update("last_slot", slot)
+		if claim.Error != nil {
+			log.Printf("Error claiming reminder %d: %v", reminder.ID, claim.Error)
+			failed++
+			continue
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		if _, err := s.deliver(reminder, goal, slot, false); err != nil {
+			log.Printf("Error recording delivery of reminder %d: %v", reminder.ID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("processing %d reminders failed", failed)
+	}
+	return nil
+}
+
+// SendTest sends the reminder right away, regardless of its schedule, and
+// returns the recorded delivery.
+func (s *ReminderService) SendTest(reminder models.Reminder) (models.ReminderDelivery, error) {
+	var goal models.Goal
+	if err := s.db.First(&goal, reminder.GoalID).Error; err != nil {
+		return models.ReminderDelivery{}, err
+	}
+	return s.deliver(reminder, goal, "", true)
+}
+
+// Deliveries returns the user's latest delivery attempts, newest first,
+// optionally limited to one goal.
+func (s *ReminderService) Deliveries(userID, goalID uint, limit, offset int) ([]models.ReminderDelivery, int64, error) {
+	query := s.db.Model(&models.ReminderDelivery{}).Where("user_id = ?", userID)
+	if goalID != 0 {
+		query = query.Where("goal_id = ?", goalID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []models.ReminderDelivery
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// deliver sends the reminder and records the attempt. A failed send is not
+// an error: it is recorded on the delivery for the user to see.
+func (s *ReminderService) deliver(reminder models.Reminder, goal models.Goal, slot string, test bool) (models.ReminderDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), reminderSendTimeout)
+	defer cancel()
+
+	delivery := models.ReminderDelivery{
+		ReminderID: reminder.ID,
+		UserID:     reminder.UserID,
+		GoalID:     reminder.GoalID,
+		Channel:    reminder.Channel,
+		Target:     reminder.Target,
+		Slot:       slot,
+		Status:     models.DeliveryStatusSent,
+		Test:       test,
+	}
+	msg := reminderMessage(reminder, goal)
+	if !test {
+		if _, err := s.notifications.Notify(reminder.UserID, NewNotification{
+			Kind:  models.NotificationReminder,
+			Title: msg.Title,
+			Body:  msg.Body,
+			Link:  fmt.Sprintf("/goals/%d", goal.ID),
+		}); err != nil {
+			log.Printf("Error adding reminder %d to user %d's inbox: %v", reminder.ID, reminder.UserID, err)
+		}
+	}
+	var err error
+	if reminder.Channel == notify.ChannelWebPush {
+		// Push notifications open the app, so they can link to the goal.
+		msg.Link = fmt.Sprintf("/goals/%d", goal.ID)
+		_, err = s.push.SendToUser(ctx, reminder.UserID, msg, fmt.Sprintf("reminder-%d", reminder.ID))
+	} else {
+		err = s.dispatcher.Send(ctx, reminder.Channel, msg)
+	}
+	if err != nil {
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.Error = err.Error()
+	}
+
+	if err := s.db.Create(&delivery).Error; err != nil {
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+func reminderMessage(reminder models.Reminder, goal models.Goal) notify.Message {
+	body := fmt.Sprintf("Time to check in on %q.", goal.Title)
+	if goal.Motivation != "" {
+		body += "\n\n" + goal.Motivation
+	}
+	return notify.Message{
+		Target: reminder.Target,
+		Title:  "Reminder: " + goal.Title,
+		Body:   body,
+	}
+}
+
+// dueSlot returns the "YYYY-MM-DD HH:MM" slot of the latest reminder time
+// that has passed on local's day within reminderWindow, or "" if none has.
+func dueSlot(reminder models.Reminder, local time.Time) string {
+	slot := ""
+	for _, timeOfDay := range reminder.TimeList() {
+		clock, err := time.Parse("15:04", timeOfDay)
+		if err != nil {
+			continue
+		}
+		at := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, local.Location())
+		if elapsed := local.Sub(at); elapsed >= 0 && elapsed < reminderWindow {
+			slot = at.Format(slotLayout)
+		}
+	}
+	return slot
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/notify"
+)
+
+// recordingNotifier stands in for a notification channel, keeping every
+// message it is asked to send and failing while err is set.
+type recordingNotifier struct {
+	sent []notify.Message
+	err  error
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, msg notify.Message) error {
+	n.sent = append(n.sent, msg)
+	return n.err
+}
+
+func TestRunDueReminders(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{Timezone: "Asia/Tokyo"})
+	read := createTestGoal(t, db, models.Goal{UserID: user.ID, Title: "Read", StartDate: "2026-03-01"})
+	walk := createTestGoal(t, db, models.Goal{UserID: user.ID, Title: "Walk", StartDate: "2026-03-01"})
+	// Walk is already checked in on Tuesday March 3rd.
+	db.Create(&models.CheckIn{GoalID: walk.ID, UserID: user.ID, Date: "2026-03-03", Status: "completed"})
+
+	notifier := &recordingNotifier{}
+	dispatcher := notify.NewDispatcher()
+	dispatcher.Register(notify.ChannelNtfy, notifier)
+	reminders := NewReminderService(db, dispatcher, nil, NewNotificationService(db, NewEventBroker()))
+
+	daily := models.Reminder{GoalID: read.ID, UserID: user.ID, Times: "08:00,21:00", Channel: notify.ChannelNtfy,
+		Target: "https://ntfy.example.com/read", Enabled: true}
+	mondays := models.Reminder{GoalID: read.ID, UserID: user.ID, Times: "08:00", Days: "mon", Channel: notify.ChannelNtfy,
+		Target: "https://ntfy.example.com/mondays", Enabled: true}
+	unlessDone := models.Reminder{GoalID: walk.ID, UserID: user.ID, Times: "08:00", OnlyIfNotCheckedIn: true,
+		Channel: notify.ChannelNtfy, Target: "https://ntfy.example.com/walk", Enabled: true}
+	disabled := models.Reminder{GoalID: read.ID, UserID: user.ID, Times: "08:00", Channel: notify.ChannelNtfy,
+		Target: "https://ntfy.example.com/off"}
+	for _, reminder := range []*models.Reminder{&daily, &mondays, &unlessDone, &disabled} {
+		if err := db.Create(reminder).Error; err != nil {
+			t.Fatalf("create reminder: %v", err)
+		}
+	}
+
+	run := func(at time.Time) {
+		t.Helper()
+		if err := reminders.RunDueReminders(at); err != nil {
+			t.Fatalf("run reminders at %v: %v", at, err)
+		}
+	}
+
+	// 23:10 UTC on Monday is 08:10 on Tuesday in Tokyo.
+	run(time.Date(2026, 3, 2, 23, 10, 0, 0, time.UTC))
+	if len(notifier.sent) != 1 || notifier.sent[0].Target != daily.Target {
+		t.Fatalf("sent %+v, want only the daily reminder", notifier.sent)
+	}
+	var delivery models.ReminderDelivery
+	if err := db.Where("reminder_id = ?", daily.ID).First(&delivery).Error; err != nil {
+		t.Fatalf("load delivery: %v", err)
+	}
+	if delivery.Slot != "2026-03-03 08:00" || delivery.Status != models.DeliveryStatusSent || delivery.Test {
+		t.Errorf("delivery = %+v, want a sent delivery for 2026-03-03 08:00", delivery)
+	}
+	var inbox int64
+	db.Model(&models.Notification{}).Where("user_id = ? AND kind = ?", user.ID, models.NotificationReminder).Count(&inbox)
+	if inbox != 1 {
+		t.Errorf("%d reminders in the inbox, want 1", inbox)
+	}
+
+	// The slot goes out once, and not at all once the window has passed.
+	run(time.Date(2026, 3, 3, 8, 20, 0, 0, tokyo))
+	run(time.Date(2026, 3, 3, 8, 45, 0, 0, tokyo))
+	if len(notifier.sent) != 1 {
+		t.Fatalf("sent %d reminders, want the 08:00 slot sent once", len(notifier.sent))
+	}
+
+	// A failed send is recorded and not retried.
+	notifier.err = errors.New("connection refused")
+	run(time.Date(2026, 3, 3, 21, 0, 0, 0, tokyo))
+	run(time.Date(2026, 3, 3, 21, 5, 0, 0, tokyo))
+	if len(notifier.sent) != 2 {
+		t.Fatalf("sent %d reminders, want the 21:00 slot tried once", len(notifier.sent))
+	}
+	var failed models.ReminderDelivery
+	if err := db.Where("reminder_id = ? AND slot = ?", daily.ID, "2026-03-03 21:00").First(&failed).Error; err != nil {
+		t.Fatalf("load delivery: %v", err)
+	}
+	if failed.Status != models.DeliveryStatusFailed || failed.Error != "connection refused" {
+		t.Errorf("delivery = %+v, want a failed delivery with its error", failed)
+	}
+}
+
+func TestDueSlot(t *testing.T) {
+	reminder := models.Reminder{Times: "07:30,08:00"}
+	tests := map[string]string{
+		"07:29": "",
+		"07:30": "2026-03-03 07:30",
+		"07:59": "2026-03-03 07:30",
+		"08:10": "2026-03-03 08:00",
+		"08:29": "2026-03-03 08:00",
+		"08:30": "",
+	}
+	for clock, want := range tests {
+		local, err := time.Parse("2006-01-02 15:04", "2026-03-03 "+clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := dueSlot(reminder, local); got != want {
+			t.Errorf("dueSlot at %s = %q, want %q", clock, got, want)
+		}
+	}
+}
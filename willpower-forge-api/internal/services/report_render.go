@@ -0,0 +1,128 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// formatRate renders an optional completion rate as a percentage.
+func formatRate(rate *float64) string {
+	if rate == nil {
+		return "–"
+	}
+	return fmt.Sprintf("%.0f%%", *rate*100)
+}
+
+// formatChange renders the difference between two optional rates in
+// percentage points.
+func formatChange(current, previous *float64) string {
+	if current == nil || previous == nil {
+		return "–"
+	}
+	return fmt.Sprintf("%+.0f pts", (*current-*previous)*100)
+}
+
+func reportTitle(report ReportData) string {
+	if report.Period == ReportPeriodMonth {
+		return fmt.Sprintf("Monthly review: %s", report.StartDate[:7])
+	}
+	return fmt.Sprintf("Weekly review: %s – %s", report.StartDate, report.EndDate)
+}
+
+// RenderReportMarkdown renders a review as Markdown.
+func RenderReportMarkdown(report ReportData) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", reportTitle(report))
+	fmt.Fprintf(&b, "Overall completion: **%s** (previous %s: %s, %s)\n",
+		formatRate(report.CompletionRate), report.Period, formatRate(report.PreviousCompletionRate),
+		formatChange(report.CompletionRate, report.PreviousCompletionRate))
+
+	if len(report.Goals) > 0 {
+		b.WriteString("\n## Goals\n\n")
+		b.WriteString("| Goal | Completed | Partial | Failed | Missed | Excused | Rate | Change | Streak |\n")
+		b.WriteString("|---|---|---|---|---|---|---|---|---|\n")
+		for _, goal := range report.Goals {
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %d | %d | %s | %s | %d → %d |\n",
+				escapeMarkdownCell(goal.Title), goal.Completed, goal.Partial, goal.Failed, goal.Missed, goal.Excused,
+				formatRate(goal.CompletionRate), formatChange(goal.CompletionRate, goal.PreviousCompletionRate),
+				goal.StreakBefore, goal.StreakAfter)
+		}
+	}
+
+	if report.BestDay != nil {
+		b.WriteString("\n## Days\n\n")
+		fmt.Fprintf(&b, "- Best day: %s (%d of %d done)\n", report.BestDay.Date, report.BestDay.Completed, report.BestDay.Due)
+		fmt.Fprintf(&b, "- Hardest day: %s (%d of %d done)\n", report.WorstDay.Date, report.WorstDay.Completed, report.WorstDay.Due)
+	}
+
+	if len(report.Notes) > 0 {
+		b.WriteString("\n## Notes\n")
+		for _, note := range report.Notes {
+			fmt.Fprintf(&b, "\n> %s\n>\n> — %s, %s\n", strings.ReplaceAll(note.Text, "\n", "\n> "), note.GoalTitle, note.Date)
+		}
+	}
+
+	return b.String()
+}
+
+func escapeMarkdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
+
+var reportHTMLTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"rate":   formatRate,
+	"change": formatChange,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 48rem; margin: 2rem auto; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: .4rem; text-align: left; }
+blockquote { border-left: 3px solid #ccc; margin: 1rem 0; padding-left: 1rem; color: #555; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{with .Report}}
+<p>Overall completion: <strong>{{rate .CompletionRate}}</strong> (previous {{.Period}}: {{rate .PreviousCompletionRate}}, {{change .CompletionRate .PreviousCompletionRate}})</p>
+{{if .Goals}}
+<h2>Goals</h2>
+<table>
+<tr><th>Goal</th><th>Completed</th><th>Partial</th><th>Failed</th><th>Missed</th><th>Excused</th><th>Rate</th><th>Change</th><th>Streak</th></tr>
+{{range .Goals}}<tr><td>{{.Title}}</td><td>{{.Completed}}</td><td>{{.Partial}}</td><td>{{.Failed}}</td><td>{{.Missed}}</td><td>{{.Excused}}</td><td>{{rate .CompletionRate}}</td><td>{{change .CompletionRate .PreviousCompletionRate}}</td><td>{{.StreakBefore}} → {{.StreakAfter}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .BestDay}}
+<h2>Days</h2>
+<ul>
+<li>Best day: {{.BestDay.Date}} ({{.BestDay.Completed}} of {{.BestDay.Due}} done)</li>
+<li>Hardest day: {{.WorstDay.Date}} ({{.WorstDay.Completed}} of {{.WorstDay.Due}} done)</li>
+</ul>
+{{end}}
+{{if .Notes}}
+<h2>Notes</h2>
+{{range .Notes}}<blockquote><p>{{.Text}}</p><footer>— {{.GoalTitle}}, {{.Date}}</footer></blockquote>
+{{end}}{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// RenderReportHTML renders a review as a standalone HTML page.
+func RenderReportHTML(report ReportData) (string, error) {
+	var buf bytes.Buffer
+	err := reportHTMLTemplate.Execute(&buf, struct {
+		Title  string
+		Report ReportData
+	}{Title: reportTitle(report), Report: report})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,391 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"willpower-forge-api/internal/models"
+)
+
+// Report periods.
+const (
+	ReportPeriodWeek  = "week"
+	ReportPeriodMonth = "month"
+)
+
+// reportNotableNotes is the number of review notes quoted in a report.
+const reportNotableNotes = 5
+
+var (
+	ErrInvalidReportPeriod = errors.New("invalid report period")
+	ErrReportNotFound      = errors.New("report not found")
+)
+
+// GoalReport is one goal's part of a review. Missed counts due days without
+// any check-in. Streaks are the current streak just before the period and at
+// its end.
+type GoalReport struct {
+	GoalID                 uint     `json:"goal_id"`
+	Title                  string   `json:"title"`
+	Type                   string   `json:"type"`
+	DueDays                int      `json:"due_days"`
+	Completed              int      `json:"completed"`
+	Partial                int      `json:"partial"`
+	Failed                 int      `json:"failed"`
+	Excused                int      `json:"excused"`
+	Missed                 int      `json:"missed"`
+	CompletionRate         *float64 `json:"completion_rate"`
+	PreviousCompletionRate *float64 `json:"previous_completion_rate"`
+	StreakBefore           int      `json:"streak_before"`
+	StreakAfter            int      `json:"streak_after"`
+}
+
+// ReportDay is the share of due goals completed on one day.
+type ReportDay struct {
+	Date           string  `json:"date"`
+	Due            int     `json:"due"`
+	Completed      int     `json:"completed"`
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+// ReportNote quotes a review note written during the period.
+type ReportNote struct {
+	Date      string `json:"date"`
+	GoalTitle string `json:"goal_title"`
+	Text      string `json:"text"`
+}
+
+// ReportData is a weekly or monthly review. Periods still in progress only
+// cover the days up to today.
+type ReportData struct {
+	Period                 string       `json:"period"`
+	StartDate              string       `json:"start_date"`
+	EndDate                string       `json:"end_date"`
+	PreviousStartDate      string       `json:"previous_start_date"`
+	PreviousEndDate        string       `json:"previous_end_date"`
+	CompletionRate         *float64     `json:"completion_rate"`
+	PreviousCompletionRate *float64     `json:"previous_completion_rate"`
+	Goals                  []GoalReport `json:"goals"`
+	BestDay                *ReportDay   `json:"best_day"`
+	WorstDay               *ReportDay   `json:"worst_day"`
+	Notes                  []ReportNote `json:"notes"`
+	GeneratedAt            time.Time    `json:"generated_at"`
+}
+
+type ReportService struct {
+	db *gorm.DB
+}
+
+func NewReportService(db *gorm.DB) *ReportService {
+	return &ReportService{db: db}
+}
+
+// PeriodBounds returns the first and last date of the week (Monday to
+// Sunday) or month containing date.
+func PeriodBounds(period, date string) (string, string, error) {
+	day, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch period {
+	case ReportPeriodWeek:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start.Format(dateLayout), start.AddDate(0, 0, 6).Format(dateLayout), nil
+	case ReportPeriodMonth:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start.Format(dateLayout), start.AddDate(0, 1, -1).Format(dateLayout), nil
+	}
+	return "", "", ErrInvalidReportPeriod
+}
+
+// Generate builds the user's review for the period containing date. Days
+// after the user's today are left out.
+func (s *ReportService) Generate(userID uint, period, date string) (ReportData, error) {
+	today, err := UserToday(s.db, userID)
+	if err != nil {
+		return ReportData{}, err
+	}
+	start, end, err := PeriodBounds(period, date)
+	if err != nil {
+		return ReportData{}, err
+	}
+	previousStart, previousEnd, err := PeriodBounds(period, addDays(start, -1))
+	if err != nil {
+		return ReportData{}, err
+	}
+
+	report := ReportData{
+		Period:            period,
+		StartDate:         start,
+		EndDate:           end,
+		PreviousStartDate: previousStart,
+		PreviousEndDate:   previousEnd,
+		Goals:             []GoalReport{},
+		Notes:             []ReportNote{},
+		GeneratedAt:       time.Now(),
+	}
+
+	if end > today {
+		end = today
+	}
+
+	var goals []models.Goal
+	if err := s.db.Preload("Pauses").Preload("StreakFreezes").Where("user_id = ? AND start_date <= ?", userID, end).
+		Order("created_at ASC").Find(&goals).Error; err != nil {
+		return ReportData{}, err
+	}
+	var vacations []models.VacationPeriod
+	if err := s.db.Where("user_id = ?", userID).Find(&vacations).Error; err != nil {
+		return ReportData{}, err
+	}
+	var checkIns []models.CheckIn
+	if err := s.db.Where("user_id = ? AND date <= ?", userID, end).Find(&checkIns).Error; err != nil {
+		return ReportData{}, err
+	}
+
+	checkInsByGoal := make(map[uint][]models.CheckIn)
+	for _, checkIn := range checkIns {
+		checkInsByGoal[checkIn.GoalID] = append(checkInsByGoal[checkIn.GoalID], checkIn)
+	}
+
+	days := make(map[string]*ReportDay)
+	var judged, completed, previousJudged, previousCompleted int
+	for _, goal := range goals {
+		latest := LatestByDate(checkInsByGoal[goal.ID])
+		statuses := LatestStatusByDate(checkInsByGoal[goal.ID])
+		neutral := NeutralDates(goal, vacations)
+
+		goalReport := GoalReport{GoalID: goal.ID, Title: goal.Title, Type: goal.Type}
+		ForEachDate(start, end, func(date string) {
+			checkIn, checkedIn := latest[date]
+			if !judgeable(goal, date, neutral) || (!checkedIn && date == today) {
+				return
+			}
+
+			goalReport.DueDays++
+			day, ok := days[date]
+			if !ok {
+				day = &ReportDay{Date: date}
+				days[date] = day
+			}
+			if checkIn.Status != "excused" {
+				day.Due++
+			}
+
+			switch {
+			case !checkedIn, checkIn.Status == models.CheckInStatusMissed:
+				goalReport.Missed++
+			case checkIn.Status == "completed":
+				goalReport.Completed++
+				day.Completed++
+			case checkIn.Status == "partial":
+				goalReport.Partial++
+			case checkIn.Status == "excused":
+				goalReport.Excused++
+			default:
+				goalReport.Failed++
+			}
+
+			if checkedIn && checkIn.ReviewNotes != "" {
+				report.Notes = append(report.Notes, ReportNote{Date: date, GoalTitle: goal.Title, Text: checkIn.ReviewNotes})
+			}
+		})
+
+		previousDue, previousDone := judgedDays(goal, statuses, previousStart, previousEnd, today, neutral)
+		if goalReport.DueDays == 0 && previousDue == 0 {
+			continue
+		}
+
+		goalJudged := goalReport.Completed + goalReport.Partial + goalReport.Failed + goalReport.Missed
+		goalReport.CompletionRate = ratio(goalReport.Completed, goalJudged)
+		goalReport.PreviousCompletionRate = ratio(previousDone, previousDue)
+		goalReport.StreakBefore = ComputeStreaks(statuses, goal.EffectiveStartDate(), addDays(start, -1), neutral).CurrentStreak
+		goalReport.StreakAfter = ComputeStreaks(statuses, goal.EffectiveStartDate(), end, neutral).CurrentStreak
+
+		judged += goalJudged
+		completed += goalReport.Completed
+		previousJudged += previousDue
+		previousCompleted += previousDone
+		report.Goals = append(report.Goals, goalReport)
+	}
+
+	report.CompletionRate = ratio(completed, judged)
+	report.PreviousCompletionRate = ratio(previousCompleted, previousJudged)
+	report.BestDay, report.WorstDay = bestAndWorstDays(days)
+
+	// The longest notes tend to be the most reflective ones.
+	sort.SliceStable(report.Notes, func(i, j int) bool {
+		return len(report.Notes[i].Text) > len(report.Notes[j].Text)
+	})
+	if len(report.Notes) > reportNotableNotes {
+		report.Notes = report.Notes[:reportNotableNotes]
+	}
+	sort.SliceStable(report.Notes, func(i, j int) bool {
+		return report.Notes[i].Date < report.Notes[j].Date
+	})
+
+	return report, nil
+}
+
+// Save generates the user's review for the period containing date and
+// stores it, replacing an earlier version of the same period.
+func (s *ReportService) Save(userID uint, period, date string) (models.Report, ReportData, error) {
+	data, err := s.Generate(userID, period, date)
+	if err != nil {
+		return models.Report{}, ReportData{}, err
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return models.Report{}, ReportData{}, err
+	}
+
+	report := models.Report{
+		UserID:    userID,
+		Period:    period,
+		StartDate: data.StartDate,
+		EndDate:   data.EndDate,
+		Data:      string(encoded),
+	}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "period"}, {Name: "start_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"end_date", "data", "updated_at"}),
+	}).Create(&report).Error; err != nil {
+		return models.Report{}, ReportData{}, err
+	}
+
+	if err := s.db.Where("user_id = ? AND period = ? AND start_date = ?", userID, period, data.StartDate).
+		First(&report).Error; err != nil {
+		return models.Report{}, ReportData{}, err
+	}
+	return report, data, nil
+}
+
+// ListSaved returns the user's stored reports, newest period first.
+func (s *ReportService) ListSaved(userID uint) ([]models.Report, error) {
+	var reports []models.Report
+	if err := s.db.Where("user_id = ?", userID).Order("start_date DESC, period ASC").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// GetSaved loads one of the user's stored reports.
+func (s *ReportService) GetSaved(userID, reportID uint) (ReportData, error) {
+	var report models.Report
+	if err := s.db.Where("id = ? AND user_id = ?", reportID, userID).First(&report).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ReportData{}, ErrReportNotFound
+		}
+		return ReportData{}, err
+	}
+
+	var data ReportData
+	if err := json.Unmarshal([]byte(report.Data), &data); err != nil {
+		return ReportData{}, err
+	}
+	return data, nil
+}
+
+// RunScheduledReports stores the reports of the last complete week and month
+// for users who don't have them yet. Periods end in the user's timezone, as
+// digests do. It is safe to run repeatedly.
+func (s *ReportService) RunScheduledReports() error {
+	return s.runScheduledReports(time.Now())
+}
+
+func (s *ReportService) runScheduledReports(now time.Time) error {
+	var users []models.User
+	if err := s.db.Select("id", "timezone").Find(&users).Error; err != nil {
+		return fmt.Errorf("loading users: %w", err)
+	}
+
+	failed := 0
+	for _, user := range users {
+		today := now.In(UserLocation(user.Timezone)).Format(dateLayout)
+		for _, period := range []string{ReportPeriodWeek, ReportPeriodMonth} {
+			start, _, err := PeriodBounds(period, today)
+			if err != nil {
+				continue
+			}
+			lastDay := addDays(start, -1)
+			previousStart, _, err := PeriodBounds(period, lastDay)
+			if err != nil {
+				continue
+			}
+
+			var count int64
+			if err := s.db.Model(&models.Report{}).
+				Where("user_id = ? AND period = ? AND start_date = ?", user.ID, period, previousStart).
+				Count(&count).Error; err != nil || count > 0 {
+				continue
+			}
+			if _, _, err := s.Save(user.ID, period, lastDay); err != nil {
+				log.Printf("Error storing %s report for user %d: %v", period, user.ID, err)
+				failed++
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("storing %d reports failed", failed)
+	}
+	return nil
+}
+
+// judgedDays counts the goal's judged days between start and end and how
+// many of them were completed. Excused days and days after today are left
+// out.
+func judgedDays(goal models.Goal, statuses map[string]string, start, end, today string, neutral func(string) bool) (int, int) {
+	due, completed := 0, 0
+	ForEachDate(start, end, func(date string) {
+		status, checkedIn := statuses[date]
+		if date > today || !judgeable(goal, date, neutral) || status == "excused" || (!checkedIn && date == today) {
+			return
+		}
+		due++
+		if status == "completed" {
+			completed++
+		}
+	})
+	return due, completed
+}
+
+func bestAndWorstDays(days map[string]*ReportDay) (*ReportDay, *ReportDay) {
+	dates := make([]string, 0, len(days))
+	for date, day := range days {
+		if day.Due > 0 {
+			day.CompletionRate = float64(day.Completed) / float64(day.Due)
+			dates = append(dates, date)
+		}
+	}
+	sort.Strings(dates)
+
+	var best, worst *ReportDay
+	for _, date := range dates {
+		day := days[date]
+		if best == nil || day.CompletionRate > best.CompletionRate {
+			best = day
+		}
+		if worst == nil || day.CompletionRate < worst.CompletionRate {
+			worst = day
+		}
+	}
+	return best, worst
+}
+
+func ratio(part, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	value := float64(part) / float64(total)
+	return &value
+}
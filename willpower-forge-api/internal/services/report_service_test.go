@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+func TestPeriodBounds(t *testing.T) {
+	tests := []struct {
+		period, date, start, end string
+	}{
+		{ReportPeriodWeek, "2026-03-02", "2026-03-02", "2026-03-08"}, // Monday
+		{ReportPeriodWeek, "2026-03-08", "2026-03-02", "2026-03-08"}, // Sunday
+		{ReportPeriodWeek, "2026-01-01", "2025-12-29", "2026-01-04"}, // across the year
+		{ReportPeriodMonth, "2026-03-31", "2026-03-01", "2026-03-31"},
+		{ReportPeriodMonth, "2028-02-10", "2028-02-01", "2028-02-29"}, // leap year
+		{ReportPeriodMonth, "2026-12-01", "2026-12-01", "2026-12-31"},
+	}
+	for _, tt := range tests {
+		start, end, err := PeriodBounds(tt.period, tt.date)
+		if err != nil || start != tt.start || end != tt.end {
+			t.Errorf("PeriodBounds(%s, %s) = %s, %s, %v; want %s, %s", tt.period, tt.date, start, end, err, tt.start, tt.end)
+		}
+	}
+
+	if _, _, err := PeriodBounds("year", "2026-03-02"); !errors.Is(err, ErrInvalidReportPeriod) {
+		t.Errorf("unknown period: got %v, want ErrInvalidReportPeriod", err)
+	}
+}
+
+func TestScheduledReportsFollowUserTimezone(t *testing.T) {
+	db := testdb.Open(t)
+	// 20:00 UTC on Sunday March 8th is already Monday in Tokyo, but still
+	// Sunday in Los Angeles, whose week is not over yet.
+	now := time.Date(2026, 3, 8, 20, 0, 0, 0, time.UTC)
+	tokyo := createTestUser(t, db, models.User{Username: "tokyo", Timezone: "Asia/Tokyo"})
+	losAngeles := createTestUser(t, db, models.User{Username: "la", Timezone: "America/Los_Angeles"})
+	reports := NewReportService(db)
+
+	for run := 0; run < 2; run++ {
+		if err := reports.runScheduledReports(now); err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+	}
+
+	tests := []struct {
+		user      models.User
+		wantWeeks []string
+	}{
+		{tokyo, []string{"2026-03-02"}},
+		{losAngeles, []string{"2026-02-23"}},
+	}
+	for _, tt := range tests {
+		var weeks, months []string
+		db.Model(&models.Report{}).Where("user_id = ? AND period = ?", tt.user.ID, ReportPeriodWeek).Pluck("start_date", &weeks)
+		db.Model(&models.Report{}).Where("user_id = ? AND period = ?", tt.user.ID, ReportPeriodMonth).Pluck("start_date", &months)
+		if len(weeks) != 1 || weeks[0] != tt.wantWeeks[0] {
+			t.Errorf("%s: weekly reports %v, want %v", tt.user.Timezone, weeks, tt.wantWeeks)
+		}
+		if len(months) != 1 || months[0] != "2026-02-01" {
+			t.Errorf("%s: monthly reports %v, want [2026-02-01]", tt.user.Timezone, months)
+		}
+	}
+}
+
+func TestGenerateStopsAtUserToday(t *testing.T) {
+	db := testdb.Open(t)
+	zone := zoneAheadOfServer(t)
+	user := createTestUser(t, db, models.User{Timezone: zone})
+	today := TodayIn(zone)
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: today})
+	createCheckIns(t, db, goal, "completed", today, today)
+
+	report, err := NewReportService(db).Generate(user.ID, ReportPeriodMonth, today)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	var completed int
+	for _, goalReport := range report.Goals {
+		completed += goalReport.Completed
+	}
+	if completed != 1 {
+		t.Errorf("report counts %d completed days, want the user's today %s", completed, today)
+	}
+}
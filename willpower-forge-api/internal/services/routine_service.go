@@ -0,0 +1,470 @@
+package services
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+// routineStatsDays is the default window of routine statistics.
+const routineStatsDays = 30
+
+var (
+	ErrRoutineNotFound    = errors.New("routine not found")
+	ErrRoutineGoalInvalid = errors.New("goal does not belong to user")
+	ErrRoutineEmpty       = errors.New("routine has no goals to check in")
+)
+
+// RoutineCheckInInput carries a check-in for a whole routine. Status applies
+// to every step unless Statuses overrides it for a goal.
+type RoutineCheckInInput struct {
+	Date        string
+	Status      string
+	Statuses    map[uint]string
+	ReviewNotes string
+}
+
+// SkippedStep explains why a step got no check-in.
+type SkippedStep struct {
+	GoalID uint   `json:"goal_id"`
+	Reason string `json:"reason"`
+}
+
+// RoutineCheckInResult lists the check-ins created for a routine and the
+// steps that were skipped because their goal could not be checked in that day.
+type RoutineCheckInResult struct {
+	CheckIns []models.CheckIn `json:"check_ins"`
+	Skipped  []SkippedStep    `json:"skipped"`
+}
+
+// RoutineStepStats reports how often one step was completed on the days it
+// was due.
+type RoutineStepStats struct {
+	GoalID         uint    `json:"goal_id"`
+	Title          string  `json:"title"`
+	DueDays        int     `json:"due_days"`
+	Completed      int     `json:"completed"`
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+// RoutineStats reports how often the whole routine was done. A day counts
+// as completed when every step due that day was completed, as partial when
+// only some were, and as missed otherwise. Days without any due step are
+// ignored.
+type RoutineStats struct {
+	From           string             `json:"from"`
+	To             string             `json:"to"`
+	CompletedDays  int                `json:"completed_days"`
+	PartialDays    int                `json:"partial_days"`
+	MissedDays     int                `json:"missed_days"`
+	CompletionRate float64            `json:"completion_rate"`
+	CurrentStreak  int                `json:"current_streak"`
+	LongestStreak  int                `json:"longest_streak"`
+	Steps          []RoutineStepStats `json:"steps"`
+}
+
+type RoutineService struct {
+	db             *gorm.DB
+	checkInService *CheckInService
+}
+
+func NewRoutineService(db *gorm.DB, checkInService *CheckInService) *RoutineService {
+	return &RoutineService{db: db, checkInService: checkInService}
+}
+
+// ListRoutines returns the user's routines ordered by time of day.
+func (s *RoutineService) ListRoutines(userID uint) ([]models.Routine, error) {
+	var routines []models.Routine
+	if err := s.db.Where("user_id = ?", userID).Order("time_of_day ASC, id ASC").Find(&routines).Error; err != nil {
+		return nil, err
+	}
+
+	for idx := range routines {
+		steps, err := s.loadSteps(s.db, routines[idx].ID)
+		if err != nil {
+			return nil, err
+		}
+		routines[idx].Steps = steps
+	}
+	return routines, nil
+}
+
+// GetRoutine returns one of the user's routines with its steps in order.
+func (s *RoutineService) GetRoutine(userID, routineID uint) (models.Routine, error) {
+	var routine models.Routine
+	if err := s.db.Where("id = ? AND user_id = ?", routineID, userID).First(&routine).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Routine{}, ErrRoutineNotFound
+		}
+		return models.Routine{}, err
+	}
+
+	steps, err := s.loadSteps(s.db, routine.ID)
+	if err != nil {
+		return models.Routine{}, err
+	}
+	routine.Steps = steps
+	return routine, nil
+}
+
+// CreateRoutine stores a routine whose steps follow the order of goalIDs.
+func (s *RoutineService) CreateRoutine(userID uint, name, timeOfDay string, goalIDs []uint) (models.Routine, error) {
+	routine := models.Routine{
+		UserID:    userID,
+		Name:      name,
+		TimeOfDay: timeOfDay,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&routine).Error; err != nil {
+			return err
+		}
+		return replaceSteps(tx, userID, routine.ID, goalIDs)
+	})
+	if err != nil {
+		return models.Routine{}, err
+	}
+
+	return s.GetRoutine(userID, routine.ID)
+}
+
+// UpdateRoutine changes the given fields; a nil goalIDs keeps the steps,
+// otherwise they are replaced in the new order.
+func (s *RoutineService) UpdateRoutine(userID, routineID uint, name, timeOfDay *string, goalIDs []uint) (models.Routine, error) {
+	routine, err := s.GetRoutine(userID, routineID)
+	if err != nil {
+		return models.Routine{}, err
+	}
+
+	updates := make(map[string]interface{})
+	if name != nil {
+		updates["name"] = *name
+	}
+	if timeOfDay != nil {
+		updates["time_of_day"] = *timeOfDay
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&routine).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if goalIDs == nil {
+			return nil
+		}
+		return replaceSteps(tx, userID, routine.ID, goalIDs)
+	})
+	if err != nil {
+		return models.Routine{}, err
+	}
+
+	return s.GetRoutine(userID, routineID)
+}
+
+// DeleteRoutine removes the routine and its steps. The goals are kept.
+func (s *RoutineService) DeleteRoutine(userID, routineID uint) error {
+	routine, err := s.GetRoutine(userID, routineID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("routine_id = ?", routine.ID).Delete(&models.RoutineStep{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&routine).Error
+	})
+}
+
+// RemoveStep takes a goal out of the routine. The remaining steps keep
+// their order.
+func (s *RoutineService) RemoveStep(userID, routineID, goalID uint) (models.Routine, error) {
+	routine, err := s.GetRoutine(userID, routineID)
+	if err != nil {
+		return models.Routine{}, err
+	}
+
+	result := s.db.Where("routine_id = ? AND goal_id = ?", routine.ID, goalID).Delete(&models.RoutineStep{})
+	if result.Error != nil {
+		return models.Routine{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.Routine{}, ErrRoutineGoalInvalid
+	}
+	return s.GetRoutine(userID, routineID)
+}
+
+// CheckInRoutine records one check-in per step in a single transaction.
+// Steps whose goal is not due, not started or not active are skipped; any
+// other failure rolls back every check-in of the routine.
+func (s *RoutineService) CheckInRoutine(routine models.Routine, input RoutineCheckInInput) (RoutineCheckInResult, error) {
+	today, err := UserToday(s.db, routine.UserID)
+	if err != nil {
+		return RoutineCheckInResult{}, err
+	}
+	date := input.Date
+	if date == "" {
+		date = today
+	}
+	if date > today {
+		return RoutineCheckInResult{}, ErrCheckInDateInFuture
+	}
+
+	result := RoutineCheckInResult{
+		CheckIns: []models.CheckIn{},
+		Skipped:  []SkippedStep{},
+	}
+
+	err = s.checkInService.Batch(func(checkIns *CheckInService) error {
+		for _, step := range routine.Steps {
+			goal := *step.Goal
+			if reason := routineSkipReason(goal, date); reason != "" {
+				result.Skipped = append(result.Skipped, SkippedStep{GoalID: goal.ID, Reason: reason})
+				continue
+			}
+
+			status := input.Status
+			if override, ok := input.Statuses[goal.ID]; ok {
+				status = override
+			}
+
+			checkIn, err := checkIns.RecordCheckIn(goal, CheckInInput{
+				Date:        date,
+				Status:      status,
+				ReviewNotes: input.ReviewNotes,
+			})
+			if err != nil {
+				return err
+			}
+			result.CheckIns = append(result.CheckIns, checkIn)
+		}
+
+		if len(result.CheckIns) == 0 {
+			return ErrRoutineEmpty
+		}
+		return nil
+	})
+	if err != nil {
+		return RoutineCheckInResult{}, err
+	}
+	return result, nil
+}
+
+// Stats computes the routine's completion between from and to, inclusive.
+// Empty bounds default to the last routineStatsDays days.
+func (s *RoutineService) Stats(routine models.Routine, from, to string) (RoutineStats, error) {
+	today, err := UserToday(s.db, routine.UserID)
+	if err != nil {
+		return RoutineStats{}, err
+	}
+	if to == "" || to > today {
+		to = today
+	}
+	if from == "" {
+		from = addDays(to, -(routineStatsDays - 1))
+	}
+
+	stats := RoutineStats{From: from, To: to, Steps: make([]RoutineStepStats, 0, len(routine.Steps))}
+	if len(routine.Steps) == 0 {
+		return stats, nil
+	}
+
+	goalIDs := make([]uint, 0, len(routine.Steps))
+	for _, step := range routine.Steps {
+		goalIDs = append(goalIDs, step.GoalID)
+	}
+
+	var pauses []models.GoalPause
+	if err := s.db.Where("goal_id IN ?", goalIDs).Find(&pauses).Error; err != nil {
+		return RoutineStats{}, err
+	}
+	var freezes []models.StreakFreeze
+	if err := s.db.Where("goal_id IN ?", goalIDs).Find(&freezes).Error; err != nil {
+		return RoutineStats{}, err
+	}
+	var checkIns []models.CheckIn
+	if err := s.db.Where("goal_id IN ? AND date >= ? AND date <= ?", goalIDs, from, to).Find(&checkIns).Error; err != nil {
+		return RoutineStats{}, err
+	}
+	var vacations []models.VacationPeriod
+	if err := s.db.Where("user_id = ?", routine.UserID).Find(&vacations).Error; err != nil {
+		return RoutineStats{}, err
+	}
+
+	pausesByGoal := make(map[uint][]models.GoalPause)
+	for _, pause := range pauses {
+		pausesByGoal[pause.GoalID] = append(pausesByGoal[pause.GoalID], pause)
+	}
+	freezesByGoal := make(map[uint][]models.StreakFreeze)
+	for _, freeze := range freezes {
+		freezesByGoal[freeze.GoalID] = append(freezesByGoal[freeze.GoalID], freeze)
+	}
+	checkInsByGoal := make(map[uint][]models.CheckIn)
+	for _, checkIn := range checkIns {
+		checkInsByGoal[checkIn.GoalID] = append(checkInsByGoal[checkIn.GoalID], checkIn)
+	}
+
+	type stepState struct {
+		statuses map[string]string
+		neutral  func(string) bool
+		goal     models.Goal
+	}
+	steps := make([]stepState, 0, len(routine.Steps))
+	for _, step := range routine.Steps {
+		goal := *step.Goal
+		goal.Pauses = pausesByGoal[goal.ID]
+		goal.StreakFreezes = freezesByGoal[goal.ID]
+		steps = append(steps, stepState{
+			statuses: LatestStatusByDate(checkInsByGoal[goal.ID]),
+			neutral:  NeutralDates(goal, vacations),
+			goal:     goal,
+		})
+		stats.Steps = append(stats.Steps, RoutineStepStats{GoalID: goal.ID, Title: goal.Title})
+	}
+
+	// dayStatuses records the whole routine's outcome per day in check-in
+	// terms so the usual streak rules apply to it.
+	dayStatuses := make(map[string]string)
+	ForEachDate(from, to, func(date string) {
+		due, completed, attempted := 0, 0, 0
+		for idx, step := range steps {
+			if date < step.goal.EffectiveStartDate() || (step.goal.EndDate != "" && date > step.goal.EndDate) || step.neutral(date) {
+				continue
+			}
+			status, checkedIn := step.statuses[date]
+			if status == "excused" {
+				continue
+			}
+			if !checkedIn && date == to {
+				continue
+			}
+
+			due++
+			stats.Steps[idx].DueDays++
+			if checkedIn && status != models.CheckInStatusMissed {
+				attempted++
+			}
+			if status == "completed" {
+				completed++
+				stats.Steps[idx].Completed++
+			}
+		}
+
+		switch {
+		case due == 0:
+		case completed == due:
+			stats.CompletedDays++
+			dayStatuses[date] = "completed"
+		case completed > 0 || attempted > 0:
+			stats.PartialDays++
+			dayStatuses[date] = "partial"
+		default:
+			stats.MissedDays++
+			dayStatuses[date] = "failed"
+		}
+	})
+
+	for idx := range stats.Steps {
+		if stats.Steps[idx].DueDays > 0 {
+			stats.Steps[idx].CompletionRate = float64(stats.Steps[idx].Completed) / float64(stats.Steps[idx].DueDays)
+		}
+	}
+	if judged := stats.CompletedDays + stats.PartialDays + stats.MissedDays; judged > 0 {
+		stats.CompletionRate = float64(stats.CompletedDays) / float64(judged)
+	}
+
+	streaks := ComputeStreaks(dayStatuses, from, to, func(date string) bool {
+		_, judged := dayStatuses[date]
+		return !judged
+	})
+	stats.CurrentStreak = streaks.CurrentStreak
+	stats.LongestStreak = streaks.LongestStreak
+
+	return stats, nil
+}
+
+// loadSteps returns the routine's steps in order with their goals. Steps of
+// goals in the recycle bin are left out until the goal is restored, and
+// positions are reported without the gaps they leave.
+func (s *RoutineService) loadSteps(db *gorm.DB, routineID uint) ([]models.RoutineStep, error) {
+	var steps []models.RoutineStep
+	if err := db.Joins("Goal").Where("routine_steps.routine_id = ?", routineID).
+		Order("routine_steps.position ASC").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+
+	visible := make([]models.RoutineStep, 0, len(steps))
+	for _, step := range steps {
+		if step.Goal != nil && step.Goal.ID != 0 {
+			step.Position = len(visible)
+			visible = append(visible, step)
+		}
+	}
+	return visible, nil
+}
+
+// replaceSteps sets the routine's steps to goalIDs in order, checking that
+// every goal belongs to the user. Steps of goals in the recycle bin are not
+// visible to the caller, so they are kept, after the new steps, for when the
+// goal is restored.
+func replaceSteps(tx *gorm.DB, userID, routineID uint, goalIDs []uint) error {
+	seen := make(map[uint]bool, len(goalIDs))
+	for _, goalID := range goalIDs {
+		if seen[goalID] {
+			return ErrRoutineGoalInvalid
+		}
+		seen[goalID] = true
+	}
+
+	if len(goalIDs) > 0 {
+		var count int64
+		if err := tx.Model(&models.Goal{}).Where("user_id = ? AND id IN ?", userID, goalIDs).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(goalIDs) {
+			return ErrRoutineGoalInvalid
+		}
+	}
+
+	var hidden []models.RoutineStep
+	deletedGoals := tx.Unscoped().Model(&models.Goal{}).Select("id").Where("deleted_at IS NOT NULL")
+	if err := tx.Where("routine_id = ? AND goal_id IN (?)", routineID, deletedGoals).
+		Order("position ASC").Find(&hidden).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("routine_id = ?", routineID).Delete(&models.RoutineStep{}).Error; err != nil {
+		return err
+	}
+	order := append([]uint(nil), goalIDs...)
+	for _, step := range hidden {
+		order = append(order, step.GoalID)
+	}
+	for position, goalID := range order {
+		step := models.RoutineStep{RoutineID: routineID, GoalID: goalID, Position: position}
+		if err := tx.Create(&step).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// routineSkipReason explains why a routine step cannot be checked in on
+// date, or returns an empty string if it can.
+func routineSkipReason(goal models.Goal, date string) string {
+	if reason := uncheckableReason(goal.Status); reason != "" {
+		return reason
+	}
+	switch {
+	case date < goal.EffectiveStartDate():
+		return "not started"
+	case goal.EndDate != "" && date > goal.EndDate:
+		return "ended"
+	case !goal.IsDueOn(date):
+		return "not due"
+	}
+	return ""
+}
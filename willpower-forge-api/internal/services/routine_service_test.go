@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+func TestUpdateRoutineKeepsStepsOfDeletedGoals(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	meditate := createTestGoal(t, db, models.Goal{UserID: user.ID, Title: "Meditate", StartDate: "2026-03-01"})
+	stretch := createTestGoal(t, db, models.Goal{UserID: user.ID, Title: "Stretch", StartDate: "2026-03-01"})
+	journal := createTestGoal(t, db, models.Goal{UserID: user.ID, Title: "Journal", StartDate: "2026-03-01"})
+	routines := NewRoutineService(db, NewCheckInService(db, NewPointsService(db)))
+
+	routine, err := routines.CreateRoutine(user.ID, "Morning", "morning", []uint{meditate.ID, stretch.ID, journal.ID})
+	if err != nil {
+		t.Fatalf("create routine: %v", err)
+	}
+
+	if err := db.Delete(&stretch).Error; err != nil {
+		t.Fatalf("delete goal: %v", err)
+	}
+	routine, err = routines.UpdateRoutine(user.ID, routine.ID, nil, nil, []uint{journal.ID, meditate.ID})
+	if err != nil {
+		t.Fatalf("update routine: %v", err)
+	}
+	if got := stepGoalIDs(routine); !equalIDs(got, []uint{journal.ID, meditate.ID}) {
+		t.Fatalf("steps while the goal is deleted = %v", got)
+	}
+
+	if err := db.Unscoped().Model(&stretch).Update("deleted_at", nil).Error; err != nil {
+		t.Fatalf("restore goal: %v", err)
+	}
+	routine, err = routines.GetRoutine(user.ID, routine.ID)
+	if err != nil {
+		t.Fatalf("get routine: %v", err)
+	}
+	if got := stepGoalIDs(routine); !equalIDs(got, []uint{journal.ID, meditate.ID, stretch.ID}) {
+		t.Errorf("steps after restoring the goal = %v, want the restored goal last", got)
+	}
+}
+
+func stepGoalIDs(routine models.Routine) []uint {
+	ids := make([]uint, 0, len(routine.Steps))
+	for _, step := range routine.Steps {
+		ids = append(ids, step.GoalID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+const dateLayout = "2006-01-02"
+
+// StreakStats holds the streak figures derived from a goal's check-in history.
+type StreakStats struct {
+	CurrentStreak int `json:"current_streak"`
+	LongestStreak int `json:"longest_streak"`
+}
+
+// UserLocation resolves a user's timezone, falling back to the server's
+// local time when it is unset or unknown.
+func UserLocation(timezone string) *time.Location {
+	if timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// TodayIn returns the current date in the given user timezone.
+func TodayIn(timezone string) string {
+	return time.Now().In(UserLocation(timezone)).Format(dateLayout)
+}
+
+// UserLocationOf loads the user's timezone, see UserLocation.
+func UserLocationOf(db *gorm.DB, userID uint) (*time.Location, error) {
+	var user models.User
+	if err := db.Select("timezone").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return UserLocation(user.Timezone), nil
+}
+
+// UserToday returns the current date in the user's timezone, the day a
+// check-in made now belongs to.
+func UserToday(db *gorm.DB, userID uint) (string, error) {
+	loc, err := UserLocationOf(db, userID)
+	if err != nil {
+		return "", err
+	}
+	return time.Now().In(loc).Format(dateLayout), nil
+}
+
+// LatestByDate maps each date to the last check-in made on that day, since a
+// goal may be checked in more than once per day.
+func LatestByDate(checkIns []models.CheckIn) map[string]models.CheckIn {
+	latest := make(map[string]models.CheckIn, len(checkIns))
+	for _, checkIn := range checkIns {
+		current, exists := latest[checkIn.Date]
+		if !exists || checkIn.CreatedAt.After(current.CreatedAt) ||
+			(checkIn.CreatedAt.Equal(current.CreatedAt) && checkIn.ID > current.ID) {
+			latest[checkIn.Date] = checkIn
+		}
+	}
+	return latest
+}
+
+// LatestStatusByDate maps each date to the status of the last check-in made
+// on that day.
+func LatestStatusByDate(checkIns []models.CheckIn) map[string]string {
+	latest := LatestByDate(checkIns)
+	statuses := make(map[string]string, len(latest))
+	for date, checkIn := range latest {
+		statuses[date] = checkIn.Status
+	}
+	return statuses
+}
+
+// PausedDates returns a predicate reporting whether a date is covered by any
+// of the given pauses.
+func PausedDates(pauses []models.GoalPause) func(string) bool {
+	return func(date string) bool {
+		for _, pause := range pauses {
+			if pause.Covers(date) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// VacationDates returns a predicate reporting whether a date is covered by any
+// of the user's vacation periods.
+func VacationDates(vacations []models.VacationPeriod) func(string) bool {
+	return func(date string) bool {
+		for _, vacation := range vacations {
+			if vacation.Covers(date) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FrozenDates returns a predicate reporting whether a streak freeze was spent
+// on a date.
+func FrozenDates(freezes []models.StreakFreeze) func(string) bool {
+	frozen := make(map[string]bool, len(freezes))
+	for _, freeze := range freezes {
+		frozen[freeze.Date] = true
+	}
+	return func(date string) bool {
+		return frozen[date]
+	}
+}
+
+// NeutralDates returns a predicate reporting whether a date should be ignored
+// when judging the goal: days it was paused or not scheduled, days protected
+// by a streak freeze, and days covered by one of the user's vacations. Goals
+// must be loaded with their pauses and streak freezes.
+func NeutralDates(goal models.Goal, vacations []models.VacationPeriod) func(string) bool {
+	paused := PausedDates(goal.Pauses)
+	frozen := FrozenDates(goal.StreakFreezes)
+	onVacation := VacationDates(vacations)
+	return func(date string) bool {
+		return !goal.IsDueOn(date) || paused(date) || frozen(date) || onVacation(date)
+	}
+}
+
+// addDays shifts a YYYY-MM-DD date by the given number of days. Invalid dates
+// are returned unchanged.
+func addDays(date string, days int) string {
+	day, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return date
+	}
+	return day.AddDate(0, 0, days).Format(dateLayout)
+}
+
+// ForEachDate calls fn for every date from start to end inclusive. Invalid
+// bounds result in no calls.
+func ForEachDate(start, end string, fn func(date string)) {
+	from, err := time.Parse(dateLayout, start)
+	if err != nil {
+		return
+	}
+	to, err := time.Parse(dateLayout, end)
+	if err != nil {
+		return
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		fn(day.Format(dateLayout))
+	}
+}
+
+// ComputeStreaks walks every day from start to end and counts consecutive
+// completed days. Excused days and days for which neutral returns true
+// neither extend nor break a streak. Any other day without a completed
+// check-in breaks it, except end itself, which may simply not have been
+// checked in yet.
+func ComputeStreaks(statuses map[string]string, start, end string, neutral func(string) bool) StreakStats {
+	var stats StreakStats
+	run := 0
+
+	ForEachDate(start, end, func(date string) {
+		status, checkedIn := statuses[date]
+		switch {
+		case status == "completed":
+			run++
+			if run > stats.LongestStreak {
+				stats.LongestStreak = run
+			}
+		case status == "excused", neutral != nil && neutral(date):
+		case !checkedIn && date == end:
+		default:
+			run = 0
+		}
+	})
+
+	stats.CurrentStreak = run
+	return stats
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+// TemplateView is the API representation shared by built-in and user-defined
+// templates. Built-in templates use their string key as ID, user templates
+// their numeric database ID.
+type TemplateView struct {
+	ID          string                     `json:"id"`
+	BuiltIn     bool                       `json:"built_in"`
+	Name        string                     `json:"name"`
+	Type        string                     `json:"type"`
+	Title       string                     `json:"title"`
+	Schedule    string                     `json:"schedule"`
+	Description string                     `json:"description"`
+	Motivation  string                     `json:"motivation"`
+	Intentions  []models.TemplateIntention `json:"intentions"`
+}
+
+type TemplateService struct {
+	db *gorm.DB
+}
+
+func NewTemplateService(db *gorm.DB) *TemplateService {
+	return &TemplateService{db: db}
+}
+
+// NormalizeLocale maps a language tag such as "zh-CN" to a supported locale,
+// falling back to English.
+func NormalizeLocale(tag string) string {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(tag)), LocaleChinese) {
+		return LocaleChinese
+	}
+	return LocaleEnglish
+}
+
+// ListTemplates returns the built-in templates in the requested locale
+// followed by the user's own templates.
+func (s *TemplateService) ListTemplates(userID uint, locale string) ([]TemplateView, error) {
+	views := make([]TemplateView, 0, len(builtinTemplates))
+	for _, template := range builtinTemplates {
+		views = append(views, template.localize(locale))
+	}
+
+	var templates []models.GoalTemplate
+	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+
+	for _, template := range templates {
+		views = append(views, userTemplateView(template))
+	}
+	return views, nil
+}
+
+// FindTemplate looks up a built-in template by key or one of the user's
+// templates by numeric ID.
+func (s *TemplateService) FindTemplate(userID uint, id string, locale string) (TemplateView, error) {
+	for _, template := range builtinTemplates {
+		if template.Key == id {
+			return template.localize(locale), nil
+		}
+	}
+
+	templateID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return TemplateView{}, ErrTemplateNotFound
+	}
+
+	var template models.GoalTemplate
+	if err := s.db.Where("id = ? AND user_id = ?", uint(templateID), userID).First(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return TemplateView{}, ErrTemplateNotFound
+		}
+		return TemplateView{}, err
+	}
+	return userTemplateView(template), nil
+}
+
+// SaveGoalAsTemplate stores the goal's type, schedule, texts and
+// implementation intentions as a new user template.
+func (s *TemplateService) SaveGoalAsTemplate(goal models.Goal, name string) (TemplateView, error) {
+	var intentions []models.ImplementationIntention
+	if err := s.db.Where("goal_id = ?", goal.ID).Order("position ASC, id ASC").Find(&intentions).Error; err != nil {
+		return TemplateView{}, err
+	}
+
+	if name == "" {
+		name = goal.Title
+	}
+
+	template := models.GoalTemplate{
+		UserID:      goal.UserID,
+		Name:        name,
+		Type:        goal.Type,
+		Title:       goal.Title,
+		Schedule:    goal.Schedule,
+		Description: goal.Description,
+		Motivation:  goal.Motivation,
+		Intentions:  make([]models.TemplateIntention, 0, len(intentions)),
+	}
+	for _, intention := range intentions {
+		template.Intentions = append(template.Intentions, models.TemplateIntention{
+			Cue:    intention.Cue,
+			Action: intention.Action,
+		})
+	}
+
+	if err := s.db.Create(&template).Error; err != nil {
+		return TemplateView{}, err
+	}
+	return userTemplateView(template), nil
+}
+
+// DeleteTemplate removes one of the user's own templates.
+func (s *TemplateService) DeleteTemplate(userID uint, templateID uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", templateID, userID).Delete(&models.GoalTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// InstantiateTemplate creates a goal and its implementation intentions from
+// a template. Dates must already be validated; a future start date creates
+// the goal as scheduled.
+func (s *TemplateService) InstantiateTemplate(userID uint, template TemplateView, title, startDate, endDate string) (models.Goal, error) {
+	if title == "" {
+		title = template.Title
+	}
+
+	today, err := UserToday(s.db, userID)
+	if err != nil {
+		return models.Goal{}, err
+	}
+	status := models.GoalStatusActive
+	if startDate > today {
+		status = models.GoalStatusScheduled
+	}
+
+	goal := models.Goal{
+		UserID:      userID,
+		Type:        template.Type,
+		Title:       title,
+		Description: template.Description,
+		Motivation:  template.Motivation,
+		Status:      status,
+		Schedule:    template.Schedule,
+		StartDate:   startDate,
+		EndDate:     endDate,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&goal).Error; err != nil {
+			return err
+		}
+
+		for idx, suggested := range template.Intentions {
+			intention := models.ImplementationIntention{
+				GoalID:   goal.ID,
+				UserID:   userID,
+				Cue:      suggested.Cue,
+				Action:   suggested.Action,
+				Position: idx,
+			}
+			if err := tx.Create(&intention).Error; err != nil {
+				return err
+			}
+			goal.Intentions = append(goal.Intentions, intention)
+		}
+		return nil
+	})
+
+	return goal, err
+}
+
+func userTemplateView(template models.GoalTemplate) TemplateView {
+	intentions := template.Intentions
+	if intentions == nil {
+		intentions = []models.TemplateIntention{}
+	}
+
+	return TemplateView{
+		ID:          strconv.FormatUint(uint64(template.ID), 10),
+		Name:        template.Name,
+		Type:        template.Type,
+		Title:       template.Title,
+		Schedule:    template.Schedule,
+		Description: template.Description,
+		Motivation:  template.Motivation,
+		Intentions:  intentions,
+	}
+}
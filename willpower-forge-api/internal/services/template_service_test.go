@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+func TestInstantiateBuiltinTemplate(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{Timezone: "UTC"})
+	templates := NewTemplateService(db)
+
+	template, err := templates.FindTemplate(user.ID, "daily-meditation", NormalizeLocale("zh-CN"))
+	if err != nil {
+		t.Fatalf("find template: %v", err)
+	}
+	if template.Title != "我要冥想 10 分钟" || !template.BuiltIn {
+		t.Fatalf("template = %+v, want the built-in template in Chinese", template)
+	}
+
+	goal, err := templates.InstantiateTemplate(user.ID, template, "", "2026-03-01", "2026-03-31")
+	if err != nil {
+		t.Fatalf("instantiate template: %v", err)
+	}
+	if goal.Title != template.Title || goal.Type != template.Type || goal.Description != template.Description ||
+		goal.Status != models.GoalStatusActive || goal.StartDate != "2026-03-01" || goal.EndDate != "2026-03-31" {
+		t.Errorf("goal = %+v, want the template's texts from 2026-03-01 to 2026-03-31", goal)
+	}
+
+	var intentions []models.ImplementationIntention
+	db.Where("goal_id = ?", goal.ID).Order("position ASC").Find(&intentions)
+	if len(intentions) != len(template.Intentions) {
+		t.Fatalf("created %d intentions, want %d", len(intentions), len(template.Intentions))
+	}
+	for idx, intention := range intentions {
+		want := template.Intentions[idx]
+		if intention.Cue != want.Cue || intention.Action != want.Action || intention.UserID != user.ID {
+			t.Errorf("intention %d = %+v, want %+v", idx, intention, want)
+		}
+	}
+
+	scheduled, err := templates.InstantiateTemplate(user.ID, template, "Sit", "2999-01-01", "")
+	if err != nil {
+		t.Fatalf("instantiate template: %v", err)
+	}
+	if scheduled.Title != "Sit" || scheduled.Status != models.GoalStatusScheduled {
+		t.Errorf("goal = %s %s, want a scheduled goal titled Sit", scheduled.Title, scheduled.Status)
+	}
+}
+
+func TestSaveGoalAsTemplate(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{Timezone: "UTC"})
+	other := createTestUser(t, db, models.User{Username: "bob"})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, Title: "Run", Schedule: "mon,wed,fri",
+		Motivation: "Stay fit", StartDate: "2026-03-01"})
+	for idx, cue := range []string{"If it rains", "If I am tired"} {
+		db.Create(&models.ImplementationIntention{GoalID: goal.ID, UserID: user.ID, Cue: cue, Action: "then I will jog inside", Position: idx})
+	}
+	templates := NewTemplateService(db)
+
+	saved, err := templates.SaveGoalAsTemplate(goal, "")
+	if err != nil {
+		t.Fatalf("save template: %v", err)
+	}
+	if saved.Name != "Run" || saved.Schedule != "mon,wed,fri" || len(saved.Intentions) != 2 || saved.Intentions[1].Cue != "If I am tired" {
+		t.Fatalf("template = %+v, want the goal's schedule and intentions in order", saved)
+	}
+
+	if _, err := templates.FindTemplate(other.ID, saved.ID, LocaleEnglish); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("other user's lookup: got %v, want ErrTemplateNotFound", err)
+	}
+	found, err := templates.FindTemplate(user.ID, saved.ID, LocaleEnglish)
+	if err != nil {
+		t.Fatalf("find template: %v", err)
+	}
+	copied, err := templates.InstantiateTemplate(user.ID, found, "", "2026-04-01", "")
+	if err != nil {
+		t.Fatalf("instantiate template: %v", err)
+	}
+	if copied.Schedule != goal.Schedule || copied.Motivation != goal.Motivation || len(copied.Intentions) != 2 {
+		t.Errorf("goal = %+v, want a copy of the saved goal", copied)
+	}
+
+	var templateID uint
+	db.Model(&models.GoalTemplate{}).Select("id").Where("user_id = ?", user.ID).Scan(&templateID)
+	if err := templates.DeleteTemplate(other.ID, templateID); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("other user's delete: got %v, want ErrTemplateNotFound", err)
+	}
+	if err := templates.DeleteTemplate(user.ID, templateID); err != nil {
+		t.Errorf("delete template: %v", err)
+	}
+}
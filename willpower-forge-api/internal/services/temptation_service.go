@@ -0,0 +1,229 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+)
+
+// ErrNoTemptationEvents is returned when deriving a check-in for a day
+// without logged temptation events.
+var ErrNoTemptationEvents = errors.New("no temptation events logged for the day")
+
+// TemptationInput carries the fields of a new temptation event. A zero
+// OccurredAt records the event as happening now.
+type TemptationInput struct {
+	OccurredAt time.Time
+	Intensity  int
+	Trigger    string
+	Resisted   bool
+	Note       string
+}
+
+// TemptationBucket aggregates the temptation events sharing one hour of the
+// day, weekday or trigger.
+type TemptationBucket struct {
+	Key              string  `json:"key"`
+	Total            int     `json:"total"`
+	Resisted         int     `json:"resisted"`
+	GaveIn           int     `json:"gave_in"`
+	AverageIntensity float64 `json:"average_intensity"`
+	intensitySum     int
+}
+
+func (b *TemptationBucket) add(event models.TemptationEvent) {
+	b.Total++
+	if event.Resisted {
+		b.Resisted++
+	} else {
+		b.GaveIn++
+	}
+	b.intensitySum += event.Intensity
+	b.AverageIntensity = float64(b.intensitySum) / float64(b.Total)
+}
+
+// TemptationAnalytics reports when and why urges appear for a goal.
+type TemptationAnalytics struct {
+	TemptationBucket
+	ResistanceRate float64            `json:"resistance_rate"`
+	ByHour         []TemptationBucket `json:"by_hour"`
+	ByWeekday      []TemptationBucket `json:"by_weekday"`
+	ByTrigger      []TemptationBucket `json:"by_trigger"`
+}
+
+type TemptationService struct {
+	db             *gorm.DB
+	checkInService *CheckInService
+}
+
+func NewTemptationService(db *gorm.DB, checkInService *CheckInService) *TemptationService {
+	return &TemptationService{db: db, checkInService: checkInService}
+}
+
+// LogTemptation stores a temptation event for the goal. Giving in also
+// records a relapse linked to the event, so the abstinence timer restarts.
+func (s *TemptationService) LogTemptation(goal models.Goal, input TemptationInput) (models.TemptationEvent, error) {
+	occurredAt := input.OccurredAt
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+	loc, err := UserLocationOf(s.db, goal.UserID)
+	if err != nil {
+		return models.TemptationEvent{}, err
+	}
+
+	event := models.TemptationEvent{
+		GoalID:     goal.ID,
+		UserID:     goal.UserID,
+		OccurredAt: occurredAt,
+		Date:       occurredAt.In(loc).Format(dateLayout),
+		Intensity:  input.Intensity,
+		Trigger:    input.Trigger,
+		Resisted:   input.Resisted,
+		Note:       input.Note,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		if event.Resisted {
+			return nil
+		}
+		relapse := models.Relapse{
+			GoalID:            goal.ID,
+			UserID:            goal.UserID,
+			OccurredAt:        occurredAt,
+			Quantity:          1,
+			Note:              input.Note,
+			TemptationEventID: &event.ID,
+		}
+		return tx.Create(&relapse).Error
+	})
+	if err != nil {
+		return models.TemptationEvent{}, err
+	}
+	return event, nil
+}
+
+// ListTemptations returns the goal's events between the given dates, both
+// optional and inclusive, newest first.
+func (s *TemptationService) ListTemptations(goalID uint, from, to string) ([]models.TemptationEvent, error) {
+	var events []models.TemptationEvent
+	if err := temptationRange(s.db, goalID, from, to).Order("occurred_at DESC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Analyze aggregates the goal's events between the given dates by hour of
+// day, weekday and trigger, in the user's timezone like the event dates.
+func (s *TemptationService) Analyze(goal models.Goal, from, to string) (TemptationAnalytics, error) {
+	loc, err := UserLocationOf(s.db, goal.UserID)
+	if err != nil {
+		return TemptationAnalytics{}, err
+	}
+	var events []models.TemptationEvent
+	if err := temptationRange(s.db, goal.ID, from, to).Find(&events).Error; err != nil {
+		return TemptationAnalytics{}, err
+	}
+
+	analytics := TemptationAnalytics{
+		TemptationBucket: TemptationBucket{Key: "all"},
+		ByHour:           make([]TemptationBucket, 24),
+		ByWeekday:        make([]TemptationBucket, 7),
+		ByTrigger:        make([]TemptationBucket, len(models.TemptationTriggers)),
+	}
+	for hour := range analytics.ByHour {
+		analytics.ByHour[hour].Key = strconv.Itoa(hour)
+	}
+	for day := range analytics.ByWeekday {
+		analytics.ByWeekday[day].Key = time.Weekday(day).String()
+	}
+	triggerIndex := make(map[string]int, len(models.TemptationTriggers))
+	for idx, trigger := range models.TemptationTriggers {
+		analytics.ByTrigger[idx].Key = trigger
+		triggerIndex[trigger] = idx
+	}
+
+	for _, event := range events {
+		local := event.OccurredAt.In(loc)
+		analytics.add(event)
+		analytics.ByHour[local.Hour()].add(event)
+		analytics.ByWeekday[local.Weekday()].add(event)
+		if idx, ok := triggerIndex[event.Trigger]; ok {
+			analytics.ByTrigger[idx].add(event)
+		}
+	}
+
+	if analytics.Total > 0 {
+		analytics.ResistanceRate = float64(analytics.Resisted) / float64(analytics.Total)
+	}
+	return analytics, nil
+}
+
+// DeriveCheckIn records the goal's check-in for date, today in the user's
+// timezone when empty, from that day's events: the day is failed if the
+// user gave in at least once and completed otherwise. A day without events
+// returns ErrNoTemptationEvents rather than counting as resisted. Without
+// explicit notes, a short tally is used as review notes.
+func (s *TemptationService) DeriveCheckIn(goal models.Goal, date string, reviewNotes string) (models.CheckIn, error) {
+	if date == "" {
+		today, err := UserToday(s.db, goal.UserID)
+		if err != nil {
+			return models.CheckIn{}, err
+		}
+		date = today
+	}
+
+	var events []models.TemptationEvent
+	if err := s.db.Where("goal_id = ? AND date = ?", goal.ID, date).Find(&events).Error; err != nil {
+		return models.CheckIn{}, err
+	}
+	if len(events) == 0 {
+		return models.CheckIn{}, ErrNoTemptationEvents
+	}
+
+	resisted, gaveIn := 0, 0
+	for _, event := range events {
+		if event.Resisted {
+			resisted++
+		} else {
+			gaveIn++
+		}
+	}
+
+	status := "completed"
+	if gaveIn > 0 {
+		status = "failed"
+	}
+	if reviewNotes == "" {
+		urges := "urges"
+		if len(events) == 1 {
+			urges = "urge"
+		}
+		reviewNotes = fmt.Sprintf("%d %s: %d resisted, %d gave in", len(events), urges, resisted, gaveIn)
+	}
+
+	return s.checkInService.RecordCheckIn(goal, CheckInInput{
+		Date:        date,
+		Status:      status,
+		ReviewNotes: reviewNotes,
+	})
+}
+
+func temptationRange(db *gorm.DB, goalID uint, from, to string) *gorm.DB {
+	query := db.Where("goal_id = ?", goalID)
+	if from != "" {
+		query = query.Where("date >= ?", from)
+	}
+	if to != "" {
+		query = query.Where("date <= ?", to)
+	}
+	return query
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+func TestDeriveCheckIn(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, Type: "I_WONT", StartDate: "2026-03-01"})
+	temptations := NewTemptationService(db, NewCheckInService(db, NewPointsService(db)))
+
+	if _, err := temptations.DeriveCheckIn(goal, "2026-03-02", ""); !errors.Is(err, ErrNoTemptationEvents) {
+		t.Fatalf("day without events: got %v, want ErrNoTemptationEvents", err)
+	}
+	var count int64
+	db.Model(&models.CheckIn{}).Where("goal_id = ?", goal.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("day without events recorded %d check-ins", count)
+	}
+
+	for _, resisted := range []bool{true, true, false} {
+		if _, err := temptations.LogTemptation(goal, TemptationInput{
+			OccurredAt: time.Date(2026, 3, 2, 12, 0, 0, 0, time.Local),
+			Intensity:  3,
+			Trigger:    models.TemptationTriggers[0],
+			Resisted:   resisted,
+		}); err != nil {
+			t.Fatalf("log temptation: %v", err)
+		}
+	}
+	if _, err := temptations.LogTemptation(goal, TemptationInput{
+		OccurredAt: time.Date(2026, 3, 3, 12, 0, 0, 0, time.Local),
+		Intensity:  2,
+		Trigger:    models.TemptationTriggers[0],
+		Resisted:   true,
+	}); err != nil {
+		t.Fatalf("log temptation: %v", err)
+	}
+
+	tests := []struct {
+		date, status, notes string
+	}{
+		{"2026-03-02", "failed", "3 urges: 2 resisted, 1 gave in"},
+		{"2026-03-03", "completed", "1 urge: 1 resisted, 0 gave in"},
+	}
+	for _, tt := range tests {
+		checkIn, err := temptations.DeriveCheckIn(goal, tt.date, "")
+		if err != nil {
+			t.Fatalf("derive check-in for %s: %v", tt.date, err)
+		}
+		if checkIn.Status != tt.status || checkIn.ReviewNotes != tt.notes {
+			t.Errorf("%s: got %s %q, want %s %q", tt.date, checkIn.Status, checkIn.ReviewNotes, tt.status, tt.notes)
+		}
+	}
+}
+
+func TestAnalyzeBucketsInUserTimezone(t *testing.T) {
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{Timezone: "Asia/Tokyo"})
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, Type: "I_WONT", StartDate: "2026-03-01"})
+	temptations := NewTemptationService(db, NewCheckInService(db, NewPointsService(db)))
+
+	// 23:30 UTC on Sunday March 1st is 08:30 on Monday March 2nd in Tokyo.
+	if _, err := temptations.LogTemptation(goal, TemptationInput{
+		OccurredAt: time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC),
+		Intensity:  3,
+		Trigger:    models.TemptationTriggers[0],
+		Resisted:   true,
+	}); err != nil {
+		t.Fatalf("log temptation: %v", err)
+	}
+
+	analytics, err := temptations.Analyze(goal, "2026-03-02", "2026-03-02")
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if analytics.Total != 1 {
+		t.Fatalf("total = %d, want the event in the March 2nd range", analytics.Total)
+	}
+	if analytics.ByHour[8].Total != 1 {
+		t.Errorf("event not in the 8 o'clock bucket: %+v", analytics.ByHour)
+	}
+	if analytics.ByWeekday[time.Monday].Total != 1 {
+		t.Errorf("event not in the Monday bucket: %+v", analytics.ByWeekday)
+	}
+}
@@ -0,0 +1,479 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/models"
+	"willpower-forge-api/internal/notify"
+)
+
+const (
+	// webhookMaxAttempts is how often an event is tried before it is given
+	// up as failed.
+	webhookMaxAttempts = 6
+	// webhookRetryBase is the delay before the first retry; each further
+	// retry waits twice as long.
+	webhookRetryBase = 30 * time.Second
+	// webhookDisableAfter is how many failed attempts in a row disable a
+	// webhook.
+	webhookDisableAfter = 10
+	webhookTimeout      = 10 * time.Second
+	webhookPollInterval = 15 * time.Second
+	webhookBatchSize    = 100
+	// webhookLease is how long a claimed delivery is left to its worker.
+	// It outlasts any attempt, so a delivery whose lease runs out was
+	// abandoned, such as by a crash, and is claimed again.
+	webhookLease = 5 * time.Minute
+	// webhookDeliveryRetention is how long finished deliveries are kept in
+	// a webhook's delivery log.
+	webhookDeliveryRetention = 30 * 24 * time.Hour
+
+	// WebhookSignatureHeader carries "t=<unix time>,v1=<hex HMAC-SHA256>",
+	// where the HMAC covers "<unix time>.<body>" keyed with the secret.
+	WebhookSignatureHeader = "X-Willpower-Signature"
+	WebhookEventHeader     = "X-Willpower-Event"
+	WebhookDeliveryHeader  = "X-Willpower-Delivery"
+)
+
+// webhookEnvelope is the JSON body of every webhook request.
+type webhookEnvelope struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// checkInEventData is the data of checkin.recorded events.
+type checkInEventData struct {
+	GoalID    uint           `json:"goal_id"`
+	GoalTitle string         `json:"goal_title"`
+	CheckIn   models.CheckIn `json:"check_in"`
+}
+
+// streakBrokenEventData is the data of streak.broken events.
+type streakBrokenEventData struct {
+	GoalID         uint   `json:"goal_id"`
+	GoalTitle      string `json:"goal_title"`
+	Date           string `json:"date"`
+	Status         string `json:"status"`
+	PreviousStreak int    `json:"previous_streak"`
+}
+
+// WebhookService queues user events for their webhooks and delivers them in
+// the background, retrying failures with exponential backoff.
+type WebhookService struct {
+	db     *gorm.DB
+	client *http.Client
+	wake   chan struct{}
+}
+
+func NewWebhookService(db *gorm.DB) *WebhookService {
+	return &WebhookService{
+		db:     db,
+		client: notify.NewHTTPClient(webhookTimeout),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// NewWebhookSecret returns a random signing secret.
+func NewWebhookSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(raw), nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of "<timestamp>.<body>".
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GoalEvent emits a goal.* event carrying the goal.
+func (s *WebhookService) GoalEvent(event string, goal models.Goal) {
+	s.Emit(goal.UserID, event, goal)
+}
+
+// CheckInRecorded emits checkin.recorded for a new check-in, and
+// streak.broken when it ended a running streak.
+func (s *WebhookService) CheckInRecorded(goal models.Goal, checkIn models.CheckIn) {
+	s.Emit(goal.UserID, models.EventCheckInRecorded, checkInEventData{
+		GoalID:    goal.ID,
+		GoalTitle: goal.Title,
+		CheckIn:   checkIn,
+	})
+
+	webhooks, err := s.subscribers(goal.UserID, models.EventStreakBroken)
+	if err != nil || len(webhooks) == 0 {
+		return
+	}
+	previous, err := s.brokenStreak(goal, checkIn)
+	if err != nil {
+		log.Printf("Error checking streak of goal %d for webhooks: %v", goal.ID, err)
+		return
+	}
+	if previous > 0 {
+		s.emitTo(webhooks, models.EventStreakBroken, streakBrokenEventData{
+			GoalID:         goal.ID,
+			GoalTitle:      goal.Title,
+			Date:           checkIn.Date,
+			Status:         checkIn.Status,
+			PreviousStreak: previous,
+		})
+	}
+}
+
+// brokenStreak returns the streak the check-in ended, or 0 if it did not
+// end one: the goal's current streak on the check-in's date without the
+// check-in, when the streak is 0 with it.
+func (s *WebhookService) brokenStreak(goal models.Goal, checkIn models.CheckIn) (int, error) {
+	if err := s.db.Where("goal_id = ?", goal.ID).Find(&goal.Pauses).Error; err != nil {
+		return 0, err
+	}
+	if err := s.db.Where("goal_id = ?", goal.ID).Find(&goal.StreakFreezes).Error; err != nil {
+		return 0, err
+	}
+	var vacations []models.VacationPeriod
+	if err := s.db.Where("user_id = ?", goal.UserID).Find(&vacations).Error; err != nil {
+		return 0, err
+	}
+	var checkIns []models.CheckIn
+	if err := s.db.Where("goal_id = ? AND date <= ?", goal.ID, checkIn.Date).Find(&checkIns).Error; err != nil {
+		return 0, err
+	}
+
+	var before []models.CheckIn
+	for _, existing := range checkIns {
+		if existing.ID != checkIn.ID {
+			before = append(before, existing)
+		}
+	}
+
+	neutral := NeutralDates(goal, vacations)
+	start := goal.EffectiveStartDate()
+	after := ComputeStreaks(LatestStatusByDate(checkIns), start, checkIn.Date, neutral).CurrentStreak
+	if after > 0 {
+		return 0, nil
+	}
+	return ComputeStreaks(LatestStatusByDate(before), start, checkIn.Date, neutral).CurrentStreak, nil
+}
+
+// Emit queues the event for every enabled webhook of the user subscribed
+// to it. Failures are logged: events must never fail the request that
+// caused them.
+func (s *WebhookService) Emit(userID uint, event string, data interface{}) {
+	webhooks, err := s.subscribers(userID, event)
+	if err != nil {
+		log.Printf("Error loading webhooks of user %d: %v", userID, err)
+		return
+	}
+	s.emitTo(webhooks, event, data)
+}
+
+func (s *WebhookService) emitTo(webhooks []models.Webhook, event string, data interface{}) {
+	if len(webhooks) == 0 {
+		return
+	}
+	if _, err := s.enqueue(webhooks, event, data, true); err != nil {
+		log.Printf("Error queueing %s webhooks: %v", event, err)
+		return
+	}
+	s.Wake()
+}
+
+func (s *WebhookService) subscribers(userID uint, event string) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	if err := s.db.Where("user_id = ? AND enabled = ?", userID, true).Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+
+	subscribed := webhooks[:0]
+	for _, webhook := range webhooks {
+		if webhook.Subscribes(event) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+	return subscribed, nil
+}
+
+// enqueue stores one pending delivery of the event per webhook. Scheduled
+// deliveries are picked up by the worker; unscheduled ones are left for the
+// caller to attempt.
+func (s *WebhookService) enqueue(webhooks []models.Webhook, event string, data interface{}, schedule bool) ([]models.WebhookDelivery, error) {
+	eventID, err := newEventID()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(webhookEnvelope{ID: eventID, Type: event, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		return nil, err
+	}
+
+	var next *time.Time
+	if schedule {
+		now := time.Now()
+		next = &now
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			UserID:        webhook.UserID,
+			EventID:       eventID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: next,
+		})
+	}
+	if err := s.db.Create(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// SendTest sends a ping event to the webhook right away, even when it is
+// disabled, and returns the delivery. Pings are not retried.
+func (s *WebhookService) SendTest(webhook models.Webhook) (models.WebhookDelivery, error) {
+	deliveries, err := s.enqueue([]models.Webhook{webhook}, models.EventPing, map[string]interface{}{
+		"webhook_id": webhook.ID,
+		"message":    "This is a test event from Willpower Forge.",
+	}, false)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	delivery := deliveries[0]
+	s.attempt(webhook, &delivery)
+	return delivery, nil
+}
+
+// Wake makes the worker look for due deliveries now instead of at its next
+// poll.
+func (s *WebhookService) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// StartWebhookWorker starts the background goroutine delivering queued
+// events until ctx is done. The returned channel is closed once the worker
+// has finished its current run and stopped.
+func (s *WebhookService) StartWebhookWorker(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+
+		for {
+			s.RunPendingDeliveries()
+			select {
+			case <-ticker.C:
+			case <-s.wake:
+			case <-ctx.Done():
+				log.Println("Webhook delivery worker stopped")
+				return
+			}
+		}
+	}()
+	log.Println("Webhook delivery worker started")
+	return done
+}
+
+// PruneDeliveries deletes succeeded and failed deliveries older than 30
+// days. Pending deliveries are kept until they finish.
+func (s *WebhookService) PruneDeliveries() error {
+	result := s.db.Where("status <> ? AND created_at < ?",
+		models.WebhookDeliveryPending, time.Now().Add(-webhookDeliveryRetention)).
+		Delete(&models.WebhookDelivery{})
+	if result.Error != nil {
+		return fmt.Errorf("deleting old webhook deliveries: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Pruned %d old webhook deliveries", result.RowsAffected)
+	}
+	return nil
+}
+
+// RunPendingDeliveries attempts every pending delivery that is due. Each is
+// claimed with a lease, so it is attempted once even if runs overlap, and
+// attempted again if the worker holding it dies.
+func (s *WebhookService) RunPendingDeliveries() {
+	for {
+		now := time.Now()
+		var due []models.WebhookDelivery
+		if err := s.db.Where("status = ? AND next_attempt_at <= ? AND (locked_until IS NULL OR locked_until <= ?)",
+			models.WebhookDeliveryPending, now, now).
+			Order("id ASC").Limit(webhookBatchSize).Find(&due).Error; err != nil {
+			log.Printf("Error loading webhook deliveries: %v", err)
+			return
+		}
+		if len(due) == 0 {
+			return
+		}
+
+		for i := range due {
+			delivery := &due[i]
+			lease := time.Now().Add(webhookLease)
+			claim := s.db.Model(&models.WebhookDelivery{}).
+				Where("id = ? AND status = ? AND (locked_until IS NULL OR locked_until <= ?)",
+					delivery.ID, models.WebhookDeliveryPending, now).
+				Update("locked_until", lease)
+			if claim.Error != nil || claim.RowsAffected == 0 {
+				continue
+			}
+			delivery.NextAttemptAt = nil
+
+			var webhook models.Webhook
+			if err := s.db.First(&webhook, delivery.WebhookID).Error; err != nil {
+				s.finish(delivery, models.WebhookDeliveryFailed, 0, "webhook no longer exists")
+				continue
+			}
+			if !webhook.Enabled {
+				s.finish(delivery, models.WebhookDeliveryFailed, 0, "webhook is disabled")
+				continue
+			}
+			s.attempt(webhook, delivery)
+		}
+
+		if len(due) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// attempt posts the delivery once and records the outcome: success, a
+// retry after an exponentially growing delay, or final failure. Failed
+// attempts count towards disabling the webhook.
+func (s *WebhookService) attempt(webhook models.Webhook, delivery *models.WebhookDelivery) {
+	status, err := s.post(webhook, delivery)
+	delivery.Attempts++
+
+	if err == nil {
+		now := time.Now()
+		delivery.DeliveredAt = &now
+		s.finish(delivery, models.WebhookDeliverySucceeded, status, "")
+		if webhook.Failures > 0 {
+			if err := s.db.Model(&webhook).Update("failures", 0).Error; err != nil {
+				log.Printf("Error resetting failures of webhook %d: %v", webhook.ID, err)
+			}
+		}
+		return
+	}
+
+	if delivery.Event != models.EventPing && delivery.Attempts < webhookMaxAttempts {
+		next := time.Now().Add(webhookRetryBase << (delivery.Attempts - 1))
+		delivery.NextAttemptAt = &next
+		s.finish(delivery, models.WebhookDeliveryPending, status, err.Error())
+	} else {
+		s.finish(delivery, models.WebhookDeliveryFailed, status, err.Error())
+	}
+	s.recordFailure(webhook, err)
+}
+
+func (s *WebhookService) post(webhook models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "willpower-forge-webhooks")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.EventID)
+	req.Header.Set(WebhookSignatureHeader,
+		fmt.Sprintf("t=%d,v1=%s", timestamp, SignWebhookPayload(webhook.Secret, timestamp, body)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *WebhookService) finish(delivery *models.WebhookDelivery, status string, responseStatus int, message string) {
+	delivery.Status = status
+	delivery.ResponseStatus = responseStatus
+	delivery.Error = message
+	delivery.LockedUntil = nil
+	if err := s.db.Model(delivery).Select("status", "attempts", "response_status", "error", "next_attempt_at", "locked_until", "delivered_at").
+		Updates(delivery).Error; err != nil {
+		log.Printf("Error saving webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// recordFailure counts a failed attempt and disables the webhook once
+// webhookDisableAfter attempts in a row have failed.
+func (s *WebhookService) recordFailure(webhook models.Webhook, cause error) {
+	if err := s.db.Model(&models.Webhook{}).Where("id = ?", webhook.ID).
+		Update("failures", gorm.Expr("failures + 1")).Error; err != nil {
+		log.Printf("Error counting failure of webhook %d: %v", webhook.ID, err)
+		return
+	}
+
+	result := s.db.Model(&models.Webhook{}).
+		Where("id = ? AND enabled = ? AND failures >= ?", webhook.ID, true, webhookDisableAfter).
+		Updates(map[string]interface{}{
+			"enabled":         false,
+			"disabled_reason": fmt.Sprintf("Disabled after %d failed attempts in a row; last error: %v", webhookDisableAfter, cause),
+		})
+	if result.Error != nil {
+		log.Printf("Error disabling webhook %d: %v", webhook.ID, result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("Webhook %d disabled after repeated failures", webhook.ID)
+	}
+}
+
+// Deliveries returns a page of a webhook's delivery log, newest first.
+func (s *WebhookService) Deliveries(webhookID uint, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	query := s.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+func newEventID() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return "evt_" + hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	got := SignWebhookPayload("whsec_test", 1700000000, []byte(`{"id":"evt_1"}`))
+	want := "c89214b5b5da833daed6f0b8c5bb6bd58cea9022bd80ccc78230f3942d632925"
+	if got != want {
+		t.Errorf("signature = %s, want %s", got, want)
+	}
+	if other := SignWebhookPayload("whsec_test", 1700000001, []byte(`{"id":"evt_1"}`)); other == want {
+		t.Error("signature does not cover the timestamp")
+	}
+}
+
+// webhookFixture is a webhook of a new user pointing at a stand-in
+// receiver answering status.
+type webhookFixture struct {
+	db       *gorm.DB
+	service  *WebhookService
+	webhook  models.Webhook
+	requests *int32
+}
+
+func newWebhookFixture(t *testing.T, status int, failures int) webhookFixture {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	db := testdb.Open(t)
+	user := createTestUser(t, db, models.User{Timezone: "UTC"})
+	webhook := models.Webhook{
+		UserID:   user.ID,
+		URL:      server.URL,
+		Events:   models.EventCheckInRecorded + "," + models.EventStreakBroken,
+		Secret:   "whsec_test",
+		Enabled:  true,
+		Failures: failures,
+	}
+	if err := db.Create(&webhook).Error; err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	service := NewWebhookService(db)
+	service.client = server.Client()
+	return webhookFixture{db: db, service: service, webhook: webhook, requests: &requests}
+}
+
+func (f webhookFixture) delivery(t *testing.T) models.WebhookDelivery {
+	t.Helper()
+	var delivery models.WebhookDelivery
+	if err := f.db.Where("webhook_id = ?", f.webhook.ID).First(&delivery).Error; err != nil {
+		t.Fatalf("load delivery: %v", err)
+	}
+	return delivery
+}
+
+// makeDue moves the delivery's next attempt into the past.
+func (f webhookFixture) makeDue(t *testing.T) {
+	t.Helper()
+	if err := f.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ? AND status = ?", f.webhook.ID, models.WebhookDeliveryPending).
+		Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatalf("make delivery due: %v", err)
+	}
+}
+
+func TestWebhookDeliverySucceeds(t *testing.T) {
+	f := newWebhookFixture(t, http.StatusNoContent, 3)
+	f.service.Emit(f.webhook.UserID, models.EventCheckInRecorded, map[string]int{"n": 1})
+	f.service.RunPendingDeliveries()
+
+	delivery := f.delivery(t)
+	if delivery.Status != models.WebhookDeliverySucceeded || delivery.Attempts != 1 || delivery.DeliveredAt == nil {
+		t.Errorf("delivery = %+v, want one successful attempt", delivery)
+	}
+	if delivery.NextAttemptAt != nil || delivery.LockedUntil != nil {
+		t.Errorf("finished delivery keeps next attempt %v and lease %v", delivery.NextAttemptAt, delivery.LockedUntil)
+	}
+	var webhook models.Webhook
+	f.db.First(&webhook, f.webhook.ID)
+	if webhook.Failures != 0 {
+		t.Errorf("failures = %d after a success, want 0", webhook.Failures)
+	}
+}
+
+func TestWebhookRetriesWithBackoff(t *testing.T) {
+	f := newWebhookFixture(t, http.StatusInternalServerError, 0)
+	f.service.Emit(f.webhook.UserID, models.EventCheckInRecorded, map[string]int{"n": 1})
+
+	for attempt := 1; attempt < webhookMaxAttempts; attempt++ {
+		before := time.Now()
+		f.service.RunPendingDeliveries()
+
+		delivery := f.delivery(t)
+		if delivery.Status != models.WebhookDeliveryPending || delivery.Attempts != attempt {
+			t.Fatalf("after attempt %d: status %s with %d attempts", attempt, delivery.Status, delivery.Attempts)
+		}
+		if delivery.ResponseStatus != http.StatusInternalServerError {
+			t.Errorf("response status = %d", delivery.ResponseStatus)
+		}
+		wait := webhookRetryBase << (attempt - 1)
+		if delivery.NextAttemptAt == nil || delivery.NextAttemptAt.Before(before.Add(wait)) || delivery.NextAttemptAt.After(time.Now().Add(wait)) {
+			t.Fatalf("after attempt %d: next attempt %v, want %v from now", attempt, delivery.NextAttemptAt, wait)
+		}
+
+		// Not due yet: nothing is sent.
+		f.service.RunPendingDeliveries()
+		if got := atomic.LoadInt32(f.requests); got != int32(attempt) {
+			t.Fatalf("sent %d requests after attempt %d", got, attempt)
+		}
+		f.makeDue(t)
+	}
+
+	f.service.RunPendingDeliveries()
+	delivery := f.delivery(t)
+	if delivery.Status != models.WebhookDeliveryFailed || delivery.Attempts != webhookMaxAttempts || delivery.NextAttemptAt != nil {
+		t.Errorf("final delivery = %+v, want failed after %d attempts", delivery, webhookMaxAttempts)
+	}
+}
+
+func TestWebhookDisabledAfterRepeatedFailures(t *testing.T) {
+	f := newWebhookFixture(t, http.StatusGone, webhookDisableAfter-2)
+	f.service.Emit(f.webhook.UserID, models.EventCheckInRecorded, map[string]int{"n": 1})
+
+	var webhook models.Webhook
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			f.makeDue(t)
+		}
+		f.service.RunPendingDeliveries()
+		f.db.First(&webhook, f.webhook.ID)
+		if want := i == 1; webhook.Enabled == want {
+			t.Fatalf("after %d failures: enabled = %v", webhook.Failures, webhook.Enabled)
+		}
+	}
+	if webhook.Failures != webhookDisableAfter || webhook.DisabledReason == "" {
+		t.Errorf("webhook = %+v, want disabled with a reason", webhook)
+	}
+
+	// Queued retries of a disabled webhook are given up without a request.
+	f.makeDue(t)
+	f.service.RunPendingDeliveries()
+	if got := atomic.LoadInt32(f.requests); got != 2 {
+		t.Errorf("sent %d requests, want 2", got)
+	}
+	if delivery := f.delivery(t); delivery.Status != models.WebhookDeliveryFailed {
+		t.Errorf("delivery status = %s, want failed", delivery.Status)
+	}
+}
+
+func TestRunPendingDeliveriesHonoursLeases(t *testing.T) {
+	f := newWebhookFixture(t, http.StatusOK, 0)
+	f.service.Emit(f.webhook.UserID, models.EventCheckInRecorded, map[string]int{"n": 1})
+
+	// Leased by a worker that is still running.
+	held := time.Now().Add(time.Minute)
+	f.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", f.webhook.ID).Update("locked_until", held)
+	f.service.RunPendingDeliveries()
+	if got := atomic.LoadInt32(f.requests); got != 0 {
+		t.Fatalf("sent %d requests for a leased delivery", got)
+	}
+
+	// Leased by a worker that died.
+	expired := time.Now().Add(-time.Second)
+	f.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", f.webhook.ID).Update("locked_until", expired)
+	f.service.RunPendingDeliveries()
+	if got := atomic.LoadInt32(f.requests); got != 1 {
+		t.Fatalf("sent %d requests for an abandoned delivery, want 1", got)
+	}
+	if delivery := f.delivery(t); delivery.Status != models.WebhookDeliverySucceeded {
+		t.Errorf("delivery status = %s, want succeeded", delivery.Status)
+	}
+}
+
+func TestWebhookWorkerStopsWithContext(t *testing.T) {
+	f := newWebhookFixture(t, http.StatusOK, 0)
+	f.service.Emit(f.webhook.UserID, models.EventCheckInRecorded, map[string]int{"n": 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := f.service.StartWebhookWorker(ctx)
+	deadline := time.Now().Add(5 * time.Second)
+	for f.delivery(t).Status != models.WebhookDeliverySucceeded {
+		if time.Now().After(deadline) {
+			t.Fatal("worker did not deliver the queued event")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not stop after its context was cancelled")
+	}
+}
+
+func TestPruneDeliveries(t *testing.T) {
+	f := newWebhookFixture(t, http.StatusOK, 0)
+	old := time.Now().Add(-webhookDeliveryRetention - time.Hour)
+	deliveries := []models.WebhookDelivery{
+		{Status: models.WebhookDeliverySucceeded, CreatedAt: old},
+		{Status: models.WebhookDeliveryFailed, CreatedAt: old},
+		{Status: models.WebhookDeliveryPending, CreatedAt: old},
+		{Status: models.WebhookDeliverySucceeded},
+	}
+	for idx := range deliveries {
+		deliveries[idx].WebhookID = f.webhook.ID
+		deliveries[idx].UserID = f.webhook.UserID
+		deliveries[idx].EventID = "evt_test"
+		deliveries[idx].Event = models.EventCheckInRecorded
+		deliveries[idx].Payload = "{}"
+	}
+	if err := f.db.Create(&deliveries).Error; err != nil {
+		t.Fatalf("create deliveries: %v", err)
+	}
+
+	if err := f.service.PruneDeliveries(); err != nil {
+		t.Fatalf("prune deliveries: %v", err)
+	}
+	var kept []models.WebhookDelivery
+	f.db.Order("id ASC").Find(&kept)
+	if len(kept) != 2 || kept[0].ID != deliveries[2].ID || kept[1].ID != deliveries[3].ID {
+		t.Errorf("kept %+v, want the old pending delivery and the recent one", kept)
+	}
+}
+
+func TestCheckInsEmitWebhookEvents(t *testing.T) {
+	f := newWebhookFixture(t, http.StatusOK, 0)
+	goal := createTestGoal(t, f.db, models.Goal{UserID: f.webhook.UserID, StartDate: "2026-01-01"})
+	checkIns := NewCheckInService(f.db, NewPointsService(f.db), f.service)
+
+	today := TodayIn("UTC")
+	if _, err := checkIns.RecordCheckIn(goal, CheckInInput{Date: addDays(today, -1), Status: "completed"}); err != nil {
+		t.Fatalf("record check-in: %v", err)
+	}
+	if _, err := checkIns.RecordCheckIn(goal, CheckInInput{Date: today, Status: "failed"}); err != nil {
+		t.Fatalf("record check-in: %v", err)
+	}
+
+	var events []string
+	f.db.Model(&models.WebhookDelivery{}).Order("id ASC").Pluck("event", &events)
+	want := []string{models.EventCheckInRecorded, models.EventCheckInRecorded, models.EventStreakBroken}
+	if len(events) != len(want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("events = %v, want %v", events, want)
+		}
+	}
+}
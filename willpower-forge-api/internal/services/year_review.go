@@ -0,0 +1,259 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"willpower-forge-api/internal/models"
+)
+
+// yearReviewThemes is the number of recurring words listed in a year review.
+const yearReviewThemes = 12
+
+var ErrInvalidYear = errors.New("invalid year")
+
+// YearGoal is one goal's part of a year review. Days maps every date of the
+// year the goal was tracked to its heatmap state: completed, partial,
+// failed, excused, missed or rest for days it was not due.
+type YearGoal struct {
+	GoalID        uint              `json:"goal_id"`
+	Title         string            `json:"title"`
+	Type          string            `json:"type"`
+	Status        string            `json:"status"`
+	CheckIns      int               `json:"check_ins"`
+	Completed     int               `json:"completed"`
+	LongestStreak int               `json:"longest_streak"`
+	Days          map[string]string `json:"days"`
+}
+
+// YearTheme is a word that keeps coming back in the year's review notes.
+type YearTheme struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// YearReview summarizes a user's year.
+type YearReview struct {
+	Year           int         `json:"year"`
+	StartDate      string      `json:"start_date"`
+	EndDate        string      `json:"end_date"`
+	TotalCheckIns  int         `json:"total_check_ins"`
+	CompletedDays  int         `json:"completed_days"`
+	LongestStreak  int         `json:"longest_streak"`
+	LongestGoal    string      `json:"longest_streak_goal"`
+	Goals          []YearGoal  `json:"goals"`
+	Themes         []YearTheme `json:"themes"`
+	GoalsCompleted []string    `json:"goals_completed"`
+	GoalsAbandoned []string    `json:"goals_abandoned"`
+	GeneratedAt    time.Time   `json:"generated_at"`
+}
+
+// YearReview builds the user's review of a calendar year. A year still in
+// progress only covers the days up to the user's today.
+func (s *ReportService) YearReview(userID uint, year int) (YearReview, error) {
+	if year < 1970 || year > 9999 {
+		return YearReview{}, ErrInvalidYear
+	}
+
+	start := fmt.Sprintf("%04d-01-01", year)
+	end := fmt.Sprintf("%04d-12-31", year)
+	review := YearReview{
+		Year:           year,
+		StartDate:      start,
+		EndDate:        end,
+		Goals:          []YearGoal{},
+		Themes:         []YearTheme{},
+		GoalsCompleted: []string{},
+		GoalsAbandoned: []string{},
+		GeneratedAt:    time.Now(),
+	}
+
+	today, err := UserToday(s.db, userID)
+	if err != nil {
+		return YearReview{}, err
+	}
+	if start > today {
+		return review, nil
+	}
+	if end > today {
+		end = today
+	}
+
+	var goals []models.Goal
+	if err := s.db.Preload("Pauses").Preload("StreakFreezes").Preload("Outcome").
+		Where("user_id = ? AND start_date <= ?", userID, end).
+		Order("created_at ASC").Find(&goals).Error; err != nil {
+		return YearReview{}, err
+	}
+	var vacations []models.VacationPeriod
+	if err := s.db.Where("user_id = ?", userID).Find(&vacations).Error; err != nil {
+		return YearReview{}, err
+	}
+	var checkIns []models.CheckIn
+	if err := s.db.Where("user_id = ? AND date >= ? AND date <= ?", userID, start, end).
+		Order("id ASC").Find(&checkIns).Error; err != nil {
+		return YearReview{}, err
+	}
+
+	checkInsByGoal := make(map[uint][]models.CheckIn)
+	var notes []string
+	for _, checkIn := range checkIns {
+		checkInsByGoal[checkIn.GoalID] = append(checkInsByGoal[checkIn.GoalID], checkIn)
+	}
+
+	for _, goal := range goals {
+		goalStart := goal.EffectiveStartDate()
+		goalEnd := end
+		if goal.EndDate != "" && goal.EndDate < goalEnd {
+			goalEnd = goal.EndDate
+		}
+		if goalStart > goalEnd || goalEnd < start {
+			continue
+		}
+		if goalStart < start {
+			goalStart = start
+		}
+
+		latest := LatestByDate(checkInsByGoal[goal.ID])
+		statuses := LatestStatusByDate(checkInsByGoal[goal.ID])
+		neutral := NeutralDates(goal, vacations)
+
+		yearGoal := YearGoal{
+			GoalID:        goal.ID,
+			Title:         goal.Title,
+			Type:          goal.Type,
+			Status:        goal.Status,
+			LongestStreak: ComputeStreaks(statuses, goalStart, goalEnd, neutral).LongestStreak,
+			Days:          make(map[string]string),
+		}
+		for _, checkIn := range latest {
+			if checkIn.Status != models.CheckInStatusMissed {
+				yearGoal.CheckIns++
+			}
+		}
+		ForEachDate(goalStart, goalEnd, func(date string) {
+			checkIn, checkedIn := latest[date]
+			switch {
+			case checkedIn:
+				yearGoal.Days[date] = checkIn.Status
+				if checkIn.Status == "completed" {
+					yearGoal.Completed++
+				}
+				if note := strings.TrimSpace(checkIn.ReviewNotes); note != "" {
+					notes = append(notes, note)
+				}
+			case neutral(date):
+				yearGoal.Days[date] = "rest"
+			case date != today:
+				yearGoal.Days[date] = "missed"
+			}
+		})
+
+		switch {
+		case goal.Outcome != nil && goal.Outcome.EndDate >= start && goal.Outcome.EndDate <= end:
+			review.GoalsCompleted = append(review.GoalsCompleted, goal.Title)
+		case goal.Status == models.GoalStatusArchived && goal.UpdatedAt.Year() == year:
+			review.GoalsAbandoned = append(review.GoalsAbandoned, goal.Title)
+		}
+		if yearGoal.CheckIns == 0 && goal.Status != models.GoalStatusActive {
+			continue
+		}
+
+		review.TotalCheckIns += yearGoal.CheckIns
+		review.CompletedDays += yearGoal.Completed
+		if yearGoal.LongestStreak > review.LongestStreak {
+			review.LongestStreak = yearGoal.LongestStreak
+			review.LongestGoal = goal.Title
+		}
+		review.Goals = append(review.Goals, yearGoal)
+	}
+
+	review.Themes = noteThemes(notes, yearReviewThemes)
+	return review, nil
+}
+
+// themeStopWords are common English words that say nothing about a theme.
+var themeStopWords = map[string]bool{
+	"about": true, "after": true, "again": true, "all": true, "also": true, "and": true,
+	"any": true, "are": true, "because": true, "been": true, "before": true, "but": true,
+	"can": true, "did": true, "didn": true, "don": true, "even": true, "for": true,
+	"from": true, "get": true, "got": true, "had": true, "has": true, "have": true,
+	"just": true, "more": true, "much": true, "not": true, "now": true, "one": true,
+	"only": true, "our": true, "out": true, "really": true, "some": true, "than": true,
+	"that": true, "the": true, "then": true, "there": true, "this": true, "today": true,
+	"too": true, "very": true, "was": true, "were": true, "what": true, "when": true,
+	"which": true, "will": true, "with": true, "would": true, "you": true, "your": true,
+}
+
+// noteThemes counts the words that recur most across notes. Latin words of
+// three letters or more count once per note; runs of Chinese characters,
+// which have no spaces, count by two-character pairs.
+func noteThemes(notes []string, limit int) []YearTheme {
+	counts := make(map[string]int)
+	for _, note := range notes {
+		seen := make(map[string]bool)
+		for _, word := range noteWords(note) {
+			if !seen[word] {
+				seen[word] = true
+				counts[word]++
+			}
+		}
+	}
+
+	themes := make([]YearTheme, 0, len(counts))
+	for word, count := range counts {
+		if count > 1 {
+			themes = append(themes, YearTheme{Word: word, Count: count})
+		}
+	}
+	sort.Slice(themes, func(i, j int) bool {
+		if themes[i].Count != themes[j].Count {
+			return themes[i].Count > themes[j].Count
+		}
+		return themes[i].Word < themes[j].Word
+	})
+	if len(themes) > limit {
+		themes = themes[:limit]
+	}
+	return themes
+}
+
+func noteWords(note string) []string {
+	var words []string
+	var latin, han []rune
+
+	flush := func() {
+		if len(latin) >= 3 {
+			if word := string(latin); !themeStopWords[word] {
+				words = append(words, word)
+			}
+		}
+		for idx := 0; idx+1 < len(han); idx++ {
+			words = append(words, string(han[idx:idx+2]))
+		}
+		latin, han = latin[:0], han[:0]
+	}
+
+	for _, r := range strings.ToLower(note) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if len(latin) > 0 {
+				flush()
+			}
+			han = append(han, r)
+		case unicode.IsLetter(r):
+			if len(han) > 0 {
+				flush()
+			}
+			latin = append(latin, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// yearReviewFonts holds Open Sans (Apache License 2.0, see fonts/LICENSE.txt)
+// in regular and bold, so the printable year review looks the same on
+// every machine.
+//
+//go:embed fonts/*.woff2
+var yearReviewFonts embed.FS
+
+// yearReviewFontFaces declares the embedded fonts as data URIs, keeping the
+// page a single self-contained file.
+var yearReviewFontFaces = template.CSS(
+	mustFontFace("OpenSans-Regular.woff2", 400) + mustFontFace("OpenSans-Bold.woff2", 700))
+
+func mustFontFace(name string, weight int) string {
+	data, err := yearReviewFonts.ReadFile("fonts/" + name)
+	if err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("@font-face { font-family: \"Open Sans\"; font-style: normal; font-weight: %d; src: url(data:font/woff2;base64,%s) format(\"woff2\"); }\n",
+		weight, base64.StdEncoding.EncodeToString(data))
+}
+
+// Heatmap geometry in SVG units: one square per day, one column per week
+// starting on Monday, one row per weekday.
+const (
+	heatmapCell   = 11
+	heatmapStep   = 13
+	heatmapLeft   = 28
+	heatmapTop    = 16
+	heatmapWeeks  = 54
+	heatmapWidth  = heatmapLeft + heatmapWeeks*heatmapStep
+	heatmapHeight = heatmapTop + 7*heatmapStep
+)
+
+type heatmapCellView struct {
+	X, Y  int
+	State string
+	Title string
+}
+
+type heatmapLabel struct {
+	X, Y int
+	Text string
+}
+
+type yearGoalView struct {
+	YearGoal
+	Cells  []heatmapCellView
+	Months []heatmapLabel
+}
+
+// buildHeatmap lays out every day of the year as a cell. Days outside the
+// goal's tracked range are drawn as empty.
+func buildHeatmap(year int, days map[string]string) ([]heatmapCellView, []heatmapLabel) {
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(first.Weekday()) + 6) % 7
+
+	var cells []heatmapCellView
+	var months []heatmapLabel
+	for day := first; day.Year() == year; day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		column := (day.YearDay() - 1 + offset) / 7
+		row := (int(day.Weekday()) + 6) % 7
+
+		state, ok := days[date]
+		if !ok {
+			state = "none"
+		}
+		title := date
+		if ok {
+			title = date + ": " + state
+		}
+		cells = append(cells, heatmapCellView{
+			X:     heatmapLeft + column*heatmapStep,
+			Y:     heatmapTop + row*heatmapStep,
+			State: state,
+			Title: title,
+		})
+
+		if day.Day() == 1 {
+			months = append(months, heatmapLabel{
+				X:    heatmapLeft + column*heatmapStep,
+				Y:    heatmapTop - 5,
+				Text: day.Format("Jan"),
+			})
+		}
+	}
+	return cells, months
+}
+
+var yearReviewTemplate = template.Must(template.New("year").Funcs(template.FuncMap{
+	"join": func(items []string) string { return strings.Join(items, ", ") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Review.Year}} in review</title>
+<style>
+{{.FontFaces}}
+@page { size: A4; margin: 15mm; }
+body { font-family: "Open Sans", sans-serif; max-width: 52rem; margin: 2rem auto; color: #222; }
+h1 { font-size: 2.2rem; margin-bottom: .2rem; }
+.totals { display: flex; gap: 1rem; margin: 1.5rem 0; }
+.total { flex: 1; border: 1px solid #ddd; border-radius: 6px; padding: .8rem; text-align: center; }
+.total strong { display: block; font-size: 1.6rem; }
+.goal { margin: 1.5rem 0; page-break-inside: avoid; break-inside: avoid; }
+.goal h3 { margin-bottom: .3rem; }
+.meta { color: #666; font-size: .9rem; }
+svg text { font-family: "Open Sans", sans-serif; font-size: 9px; fill: #666; }
+rect.none { fill: #fafafa; }
+rect.rest { fill: #ececec; }
+rect.missed { fill: #f6d5d5; }
+rect.failed { fill: #e07070; }
+rect.excused { fill: #c9d8ea; }
+rect.partial { fill: #a6dca8; }
+rect.completed { fill: #2f9e44; }
+.legend span { display: inline-block; width: 10px; height: 10px; margin: 0 .3rem 0 .8rem; vertical-align: middle; }
+.themes span { display: inline-block; border: 1px solid #ccc; border-radius: 1rem; padding: .1rem .6rem; margin: .2rem; }
+@media print { body { margin: 0; max-width: none; } }
+</style>
+</head>
+<body>
+{{with .Review}}
+<h1>{{.Year}} in review</h1>
+<p class="meta">{{.StartDate}} – {{.EndDate}}</p>
+
+<div class="totals">
+<div class="total"><strong>{{.TotalCheckIns}}</strong>check-ins</div>
+<div class="total"><strong>{{.CompletedDays}}</strong>days completed</div>
+<div class="total"><strong>{{.LongestStreak}}</strong>longest streak{{if .LongestGoal}}<br><span class="meta">{{.LongestGoal}}</span>{{end}}</div>
+</div>
+
+{{if .GoalsCompleted}}<p>Goals completed: {{join .GoalsCompleted}}</p>{{end}}
+{{if .GoalsAbandoned}}<p>Goals let go: {{join .GoalsAbandoned}}</p>{{end}}
+
+{{if .Themes}}
+<h2>What you wrote about most</h2>
+<p class="themes">{{range .Themes}}<span>{{.Word}} · {{.Count}}</span>{{end}}</p>
+{{end}}
+{{end}}
+
+{{if .Goals}}
+<h2>Goals</h2>
+<p class="legend meta"><span style="background:#2f9e44"></span>completed<span style="background:#a6dca8"></span>partial<span style="background:#e07070"></span>failed<span style="background:#f6d5d5"></span>missed<span style="background:#c9d8ea"></span>excused<span style="background:#ececec"></span>rest</p>
+{{range .Goals}}
+<div class="goal">
+<h3>{{.Title}}</h3>
+<p class="meta">{{.CheckIns}} check-ins · {{.Completed}} completed · longest streak {{.LongestStreak}}</p>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{$.Width}}" height="{{$.Height}}" viewBox="0 0 {{$.Width}} {{$.Height}}">
+{{range .Months}}<text x="{{.X}}" y="{{.Y}}">{{.Text}}</text>{{end}}
+<text x="0" y="{{$.MondayY}}">Mon</text><text x="0" y="{{$.ThursdayY}}">Thu</text><text x="0" y="{{$.SundayY}}">Sun</text>
+{{range .Cells}}<rect class="{{.State}}" x="{{.X}}" y="{{.Y}}" width="{{$.Cell}}" height="{{$.Cell}}" rx="2"><title>{{.Title}}</title></rect>{{end}}
+</svg>
+</div>
+{{end}}
+{{end}}
+<p class="meta">Generated {{.Generated}}</p>
+</body>
+</html>
+`))
+
+// RenderYearReviewHTML renders a year review as a self-contained printable
+// page. Heatmaps are inline SVG, the font is embedded and nothing is loaded
+// from elsewhere.
+func RenderYearReviewHTML(review YearReview) (string, error) {
+	goals := make([]yearGoalView, 0, len(review.Goals))
+	for _, goal := range review.Goals {
+		cells, months := buildHeatmap(review.Year, goal.Days)
+		goals = append(goals, yearGoalView{YearGoal: goal, Cells: cells, Months: months})
+	}
+
+	labelY := func(row int) int { return heatmapTop + row*heatmapStep + heatmapCell - 2 }
+
+	var buf bytes.Buffer
+	err := yearReviewTemplate.Execute(&buf, map[string]interface{}{
+		"Review":    review,
+		"Goals":     goals,
+		"Width":     heatmapWidth,
+		"Height":    heatmapHeight,
+		"Cell":      heatmapCell,
+		"MondayY":   labelY(0),
+		"ThursdayY": labelY(3),
+		"SundayY":   labelY(6),
+		"Generated": review.GeneratedAt.Format(dateLayout),
+		"FontFaces": yearReviewFontFaces,
+	})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
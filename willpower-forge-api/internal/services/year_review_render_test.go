@@ -0,0 +1,41 @@
+package services
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderYearReviewEmbedsFont(t *testing.T) {
+	page, err := RenderYearReviewHTML(YearReview{
+		Year:        2026,
+		StartDate:   "2026-01-01",
+		EndDate:     "2026-12-31",
+		Goals:       []YearGoal{{Title: "Read <daily>", Days: map[string]string{"2026-03-01": "completed"}}},
+		GeneratedAt: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	regular, err := yearReviewFonts.ReadFile("fonts/OpenSans-Regular.woff2")
+	if err != nil {
+		t.Fatalf("read font: %v", err)
+	}
+	if !strings.Contains(page, "url(data:font/woff2;base64,"+base64.StdEncoding.EncodeToString(regular)+")") {
+		t.Error("page does not inline the regular font")
+	}
+	if strings.Count(page, "@font-face") != 2 {
+		t.Error("page does not declare both font weights")
+	}
+	if strings.Contains(page, "ZgotmplZ") {
+		t.Error("the template rejected the font CSS")
+	}
+	if strings.Contains(page, "Read <daily>") || !strings.Contains(page, "Read &lt;daily&gt;") {
+		t.Error("goal titles are not escaped")
+	}
+	if !strings.Contains(page, `<rect class="completed"`) {
+		t.Error("heatmap is missing the completed day")
+	}
+}
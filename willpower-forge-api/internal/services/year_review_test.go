@@ -0,0 +1,27 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+
+	"willpower-forge-api/internal/database/testdb"
+	"willpower-forge-api/internal/models"
+)
+
+func TestYearReviewCoversUserToday(t *testing.T) {
+	db := testdb.Open(t)
+	zone := zoneAheadOfServer(t)
+	user := createTestUser(t, db, models.User{Timezone: zone})
+	today := TodayIn(zone)
+	goal := createTestGoal(t, db, models.Goal{UserID: user.ID, StartDate: today})
+	createCheckIns(t, db, goal, "completed", today, today)
+
+	year, _ := strconv.Atoi(today[:4])
+	review, err := NewReportService(db).YearReview(user.ID, year)
+	if err != nil {
+		t.Fatalf("year review: %v", err)
+	}
+	if review.CompletedDays != 1 {
+		t.Errorf("review has %d completed days, want the user's today %s counted", review.CompletedDays, today)
+	}
+}
@@ -1,18 +1,28 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"io/fs"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
 	"willpower-forge-api/internal/database"
 	"willpower-forge-api/internal/handlers"
+	"willpower-forge-api/internal/middleware"
+	"willpower-forge-api/internal/notify"
 	"willpower-forge-api/internal/routes"
+	"willpower-forge-api/internal/scheduler"
 	"willpower-forge-api/internal/services"
 )
 
@@ -27,19 +37,143 @@ func main() {
 
 	database.AutoMigrateModels(db)
 
+	events := services.NewEventBroker()
+	eventHandler := handlers.NewEventHandler(events)
 	authService := services.NewAuthService(db)
 	authHandler := handlers.NewAuthHandler(authService)
-	goalHandler := handlers.NewGoalHandler(db)
-	checkInHandler := handlers.NewCheckInHandler(db)
+	lifecycleService := services.NewGoalLifecycleService(db)
+	relapseService := services.NewRelapseService(db)
+	webhookService := services.NewWebhookService(db)
+	webhookHandler := handlers.NewWebhookHandler(db, webhookService)
+	goalHandler := handlers.NewGoalHandler(db, lifecycleService, relapseService, webhookService, events)
+	pushService, err := services.NewPushService(db)
+	if err != nil {
+		log.Fatalf("failed to load Web Push keys: %v", err)
+	}
+	pushHandler := handlers.NewPushHandler(pushService)
+	notificationService := services.NewNotificationService(db, events)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	achievementService := services.NewAchievementService(db, pushService, notificationService)
+	achievementHandler := handlers.NewAchievementHandler(achievementService)
+	pointsService := services.NewPointsService(db)
+	pointsHandler := handlers.NewPointsHandler(db, pointsService)
+	checkInService := services.NewCheckInService(db, pointsService, webhookService, events)
+	checkInHandler := handlers.NewCheckInHandler(db, checkInService, achievementService)
+	missedDayService := services.NewMissedDayService(db, checkInService)
+	inboundHookService := services.NewInboundHookService(db, checkInService)
+	inboundHookHandler := handlers.NewInboundHookHandler(db, inboundHookService, achievementService)
+	intentionHandler := handlers.NewIntentionHandler(db)
+	templateService := services.NewTemplateService(db)
+	templateHandler := handlers.NewTemplateHandler(db, templateService)
+	tagHandler := handlers.NewTagHandler(db)
+	vacationHandler := handlers.NewVacationHandler(db)
+	temptationService := services.NewTemptationService(db, checkInService)
+	temptationHandler := handlers.NewTemptationHandler(db, temptationService, achievementService)
+	relapseHandler := handlers.NewRelapseHandler(db, relapseService)
+	journalService := services.NewJournalService(db)
+	journalHandler := handlers.NewJournalHandler(db, journalService)
+	routineService := services.NewRoutineService(db, checkInService)
+	routineHandler := handlers.NewRoutineHandler(routineService, achievementService)
+	milestoneHandler := handlers.NewMilestoneHandler(db)
+	strengthService := services.NewHabitStrengthService(db)
+	strengthHandler := handlers.NewStrengthHandler(db, strengthService)
+	reportService := services.NewReportService(db)
+	reportHandler := handlers.NewReportHandler(db, reportService)
+	reminderService := services.NewReminderService(db, notify.NewDispatcherFromEnv(), pushService, notificationService)
+	reminderHandler := handlers.NewReminderHandler(db, reminderService)
+	digestService := services.NewDigestService(db, notify.NewMailerFromEnv(), reportService)
+	digestHandler := handlers.NewDigestHandler(digestService)
 
-	// Start scheduled cleanup service
+	// Register scheduled jobs
 	cleanupService := services.NewCleanupService(db)
-	cleanupService.StartScheduledCleanup()
+	jobs := scheduler.New(db)
+	for _, job := range []scheduler.Job{
+		{
+			Name:        "recycle-bin-purge",
+			Spec:        "0 3 * * *",
+			Description: "Permanently delete goals that have been in the recycle bin for more than 30 days",
+			RunOnStart:  true,
+			Run:         cleanupService.CleanupOldDeletedGoals,
+		},
+		{
+			Name:        "goal-lifecycle",
+			Spec:        "0 * * * *",
+			Description: "Activate goals on their start date and complete goals past their end date",
+			RunOnStart:  true,
+			Run:         lifecycleService.RunLifecycle,
+		},
+		{
+			Name:        "missed-days",
+			Spec:        "5 * * * *",
+			Description: "Record missed check-ins for due days that ended without one",
+			RunOnStart:  true,
+			Run:         missedDayService.FinalizeMissedDays,
+		},
+		{
+			Name:        "review-reports",
+			Spec:        "10 * * * *",
+			Description: "Store every user's review of the previous week and month",
+			RunOnStart:  true,
+			Run:         reportService.RunScheduledReports,
+		},
+		{
+			Name:        "goal-reminders",
+			Spec:        "* * * * *",
+			Description: "Send goal reminders that are due",
+			Run:         func() error { return reminderService.RunDueReminders(time.Now()) },
+		},
+		{
+			Name:        "digests",
+			Spec:        "*/5 * * * *",
+			Description: "Send daily and weekly digest emails that are due",
+			RunOnStart:  true,
+			Run:         func() error { return digestService.RunDueDigests(time.Now()) },
+		},
+		{
+			Name:        "notification-prune",
+			Spec:        "30 3 * * *",
+			Description: "Delete read notifications older than 30 days and any older than 90 days",
+			RunOnStart:  true,
+			Run:         notificationService.PruneNotifications,
+		},
+		{
+			Name:        "webhook-delivery-prune",
+			Spec:        "40 3 * * *",
+			Description: "Delete finished webhook deliveries older than 30 days",
+			RunOnStart:  true,
+			Run:         webhookService.PruneDeliveries,
+		},
+		{
+			Name:        "inbound-hook-call-prune",
+			Spec:        "45 3 * * *",
+			Description: "Delete inbound hook calls older than 30 days",
+			RunOnStart:  true,
+			Run:         inboundHookService.PruneCalls,
+		},
+	} {
+		if err := jobs.Register(job); err != nil {
+			log.Fatalf("failed to register job: %v", err)
+		}
+	}
+	adminHandler := handlers.NewAdminHandler(db, jobs, notificationService)
 
-	router := gin.Default()
+	// Start scheduled jobs
+	jobs.Start()
+
+	// Start outbound webhook deliveries
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	webhookWorker := webhookService.StartWebhookWorker(workerCtx)
+
+	router := gin.New()
+	router.Use(middleware.Logger(), gin.Recovery())
+	// Client addresses, used for rate limits and logs, only come from
+	// X-Forwarded-For when the request passed through a trusted proxy.
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
 	router.Use(cors.Default())
 
-	routes.SetupRoutes(router, authHandler, goalHandler, checkInHandler)
+	routes.SetupRoutes(router, authHandler, goalHandler, checkInHandler, intentionHandler, templateHandler, tagHandler, vacationHandler, temptationHandler, relapseHandler, journalHandler, routineHandler, milestoneHandler, strengthHandler, reportHandler, achievementHandler, pointsHandler, reminderHandler, pushHandler, webhookHandler, inboundHookHandler, adminHandler, digestHandler, notificationHandler, eventHandler)
 
 	// Serve embedded static files
 	staticFS, err := fs.Sub(webFS, "web/dist")
@@ -66,9 +200,47 @@ func main() {
 	}
 
 	addr := "0.0.0.0:" + port
-	log.Printf("Starting server on %s", addr)
+	server := &http.Server{Addr: addr, Handler: router}
+	server.RegisterOnShutdown(events.Close)
+
+	go func() {
+		log.Printf("Starting server on %s", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("failed to start server: %v", err)
+		}
+	}()
+
+	// Stop gracefully on SIGINT or SIGTERM, letting running requests and
+	// jobs finish
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+	log.Println("Shutting down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("failed to shut down server: %v", err)
+	}
+	if err := jobs.Stop(ctx); err != nil {
+		log.Printf("failed to wait for running jobs: %v", err)
+	}
+	stopWorker()
+	select {
+	case <-webhookWorker:
+	case <-ctx.Done():
+		log.Printf("failed to wait for the webhook delivery worker: %v", ctx.Err())
+	}
+}
 
-	if err := router.Run(addr); err != nil {
-		log.Fatalf("failed to start server: %v", err)
+// trustedProxies returns the addresses or CIDR ranges listed in
+// TRUSTED_PROXIES, comma separated. None are trusted by default.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
 	}
+	return proxies
 }
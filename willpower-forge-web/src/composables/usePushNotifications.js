@@ -0,0 +1,67 @@
+import { ref } from 'vue';
+import {
+  getVapidPublicKey,
+  getPushSubscriptions,
+  savePushSubscription,
+  deletePushSubscription
+} from '../services/api';
+
+const urlBase64ToUint8Array = (base64) => {
+  const padded = (base64 + '='.repeat((4 - (base64.length % 4)) % 4)).replace(/-/g, '+').replace(/_/g, '/');
+  const raw = atob(padded);
+  return Uint8Array.from(raw, (char) => char.charCodeAt(0));
+};
+
+// usePushNotifications subscribes this browser to Web Push reminders and
+// achievements through the /sw.js service worker.
+export function usePushNotifications() {
+  const supported = 'serviceWorker' in navigator && 'PushManager' in window && 'Notification' in window;
+  const enabled = ref(false);
+  const busy = ref(false);
+
+  const registration = () => navigator.serviceWorker.register('/sw.js');
+
+  const refresh = async () => {
+    if (!supported) return;
+    const reg = await registration();
+    enabled.value = Boolean(await reg.pushManager.getSubscription());
+  };
+
+  const enable = async () => {
+    if (!supported || busy.value) return;
+    busy.value = true;
+    try {
+      if ((await Notification.requestPermission()) !== 'granted') return;
+      const reg = await registration();
+      const { data } = await getVapidPublicKey();
+      const subscription = await reg.pushManager.subscribe({
+        userVisibleOnly: true,
+        applicationServerKey: urlBase64ToUint8Array(data.data.public_key)
+      });
+      await savePushSubscription(subscription.toJSON());
+      enabled.value = true;
+    } finally {
+      busy.value = false;
+    }
+  };
+
+  const disable = async () => {
+    if (!supported || busy.value) return;
+    busy.value = true;
+    try {
+      const reg = await registration();
+      const subscription = await reg.pushManager.getSubscription();
+      if (subscription) {
+        const { data } = await getPushSubscriptions();
+        const stored = (data.data || []).find((item) => item.endpoint === subscription.endpoint);
+        if (stored) await deletePushSubscription(stored.id);
+        await subscription.unsubscribe();
+      }
+      enabled.value = false;
+    } finally {
+      busy.value = false;
+    }
+  };
+
+  return { supported, enabled, busy, refresh, enable, disable };
+}
@@ -28,7 +28,10 @@ export default {
     yesterday: 'Yesterday',
     noCheckIns: 'No check-ins yet for this period.',
     goalCreated: 'Goal created successfully',
-    goalMovedToRecycleBin: 'Goal moved to recycle bin'
+    goalMovedToRecycleBin: 'Goal moved to recycle bin',
+    pushOn: 'Notifications on',
+    pushOff: 'Notifications off',
+    pushFailed: 'Could not change notification settings'
   },
   goalTypes: {
     I_WILL: 'I WILL',
@@ -75,6 +78,7 @@ export default {
     status: 'Status',
     reviewNotes: 'Review Notes',
     checkInRecorded: 'Check-in recorded as',
+    achievementUnlocked: 'Achievement unlocked',
     deleteConfirm: 'Are you sure you want to delete this goal? It will be moved to the recycle bin.',
     total: 'Total',
     records: 'records',
@@ -28,7 +28,10 @@ export default {
     yesterday: '昨天',
     noCheckIns: '这段时间还没有打卡记录。',
     goalCreated: '目标创建成功',
-    goalMovedToRecycleBin: '目标已移至回收站'
+    goalMovedToRecycleBin: '目标已移至回收站',
+    pushOn: '通知已开启',
+    pushOff: '通知已关闭',
+    pushFailed: '无法更改通知设置'
   },
   goalTypes: {
     I_WILL: '我要做',
@@ -75,6 +78,7 @@ export default {
     status: '状态',
     reviewNotes: '回顾备注',
     checkInRecorded: '打卡已记录为',
+    achievementUnlocked: '解锁成就',
     deleteConfirm: '确定要删除这个目标吗？它将被移到回收站。',
     total: '共',
     records: '条记录',
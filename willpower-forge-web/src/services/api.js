@@ -10,6 +10,7 @@ api.interceptors.request.use((config) => {
   if (token) {
     config.headers.Authorization = `Bearer ${token}`;
   }
+  config.headers['Accept-Language'] = localStorage.getItem('locale') || 'en';
   return config;
 });
 
@@ -19,4 +20,13 @@ export const getDeletedGoals = () => api.get('/goals/recycle-bin');
 export const restoreGoal = (goalId) => api.post(`/goals/${goalId}/restore`);
 export const permanentDeleteGoal = (goalId) => api.delete(`/goals/${goalId}/permanent`);
 
+// Achievement APIs
+export const getAchievements = () => api.get('/achievements');
+
+// Web Push APIs
+export const getVapidPublicKey = () => api.get('/push/vapid-public-key');
+export const getPushSubscriptions = () => api.get('/push/subscriptions');
+export const savePushSubscription = (subscription) => api.post('/push/subscriptions', subscription);
+export const deletePushSubscription = (id) => api.delete(`/push/subscriptions/${id}`);
+
 export default api;